@@ -0,0 +1,155 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+	"io"
+	"log"
+	"os"
+	"sort"
+)
+
+// cmdCombat runs the combat command
+var cmdCombat = &cobra.Command{
+	Use:   "combat",
+	Short: "combat tools",
+	Run: func(cmd *cobra.Command, args []string) {
+	},
+}
+
+// cmdCombatSimulate runs the combat simulate command
+var cmdCombatSimulate = &cobra.Command{
+	Use:   "simulate scenario.json",
+	Short: "run a battle many times and report the outcomes",
+	Long: `Reads a scenario describing two or more stacks (nobles with their
+attack, defense, behind settings, skills, and men, beasts, or siege engines),
+the terrain, the weather, and any fortification the defenders hold. The
+battle is fought --battles times in a throwaway province using the items
+and skills from the library, and the win rates, rounds, average losses,
+and the fate of each noble are reported as text or JSON.
+
+Nothing is written to lib-dir.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		} else if argsCombatSimulate.format != "text" && argsCombatSimulate.format != "json" {
+			return fmt.Errorf("format must be text or json")
+		}
+
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		sc, err := olympia.ParseCombatScenario(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", args[0], err)
+		}
+		if argsCombatSimulate.seed != 0 {
+			sc.Seed = argsCombatSimulate.seed
+		}
+
+		options := []olympia.Option{
+			olympia.WithLibPath(argsRoot.libdir),
+			olympia.WithTimeSelf(argsRoot.time_self),
+			olympia.WithLockWait(argsRoot.wait),
+		}
+		cs, err := olympia.SimulateCombat(sc, argsCombatSimulate.battles, options...)
+		if err != nil {
+			return err
+		}
+
+		w := io.Writer(os.Stdout)
+		if argsCombatSimulate.output != "" {
+			fp, err := os.Create(argsCombatSimulate.output)
+			if err != nil {
+				return err
+			}
+			defer fp.Close()
+			w = fp
+		}
+		if argsCombatSimulate.format == "json" {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(cs)
+		} else {
+			printCombatStats(w, cs)
+		}
+		if err == nil && argsCombatSimulate.output != "" {
+			log.Printf("combat: created %s\n", argsCombatSimulate.output)
+		}
+		return err
+	},
+}
+
+// printCombatStats writes the results of the simulation as text.
+func printCombatStats(w io.Writer, cs *olympia.CombatStats) {
+	if cs.Title != "" {
+		fmt.Fprintf(w, "%s\n\n", cs.Title)
+	}
+	fmt.Fprintf(w, "%-16s  %6d\n", "battles", cs.Battles)
+	fmt.Fprintf(w, "%-16s  %6d  %5.1f%%\n", "attacker wins", cs.AttackerWins, 100*cs.AttackerWinRate)
+	fmt.Fprintf(w, "%-16s  %6d  %5.1f%%\n", "defender wins", cs.DefenderWins, 100*cs.DefenderWinRate)
+	fmt.Fprintf(w, "%-16s  %6d  %5.1f%%\n", "ties", cs.Ties, 100*cs.TieRate)
+	if cs.NoCombat != 0 {
+		fmt.Fprintf(w, "%-16s  %6d\n", "no combat", cs.NoCombat)
+	}
+	fmt.Fprintf(w, "%-16s  %6.1f  (%d to %d)\n", "rounds", cs.AverageRounds, cs.MinRounds, cs.MaxRounds)
+	if cs.FortDamage != 0 {
+		fmt.Fprintf(w, "%-16s  %6.1f\n", "fort damage", cs.FortDamage)
+	}
+
+	for _, st := range cs.Stacks {
+		fmt.Fprintf(w, "\n%s (%s)\n", st.Name, st.Side)
+		var items []string
+		for item := range st.Losses {
+			items = append(items, item)
+		}
+		sort.Strings(items)
+		for _, item := range items {
+			fmt.Fprintf(w, "  lost %-20s  %8.2f per battle\n", item, st.Losses[item])
+		}
+		fmt.Fprintf(w, "  %-24s  %7s  %7s  %8s  %6s\n", "noble", "unhurt", "wounded", "captured", "killed")
+		for _, n := range st.Nobles {
+			fmt.Fprintf(w, "  %-24s  %7d  %7d  %8d  %6d\n", n.Name, n.Unhurt, n.Wounded, n.Captured, n.Killed)
+		}
+	}
+}
+
+var argsCombatSimulate struct {
+	battles int
+	seed    uint32
+	format  string
+	output  string
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdCombat)
+
+	cmdCombat.AddCommand(cmdCombatSimulate)
+	cmdCombatSimulate.Flags().IntVar(&argsCombatSimulate.battles, "battles", 0, "number of battles to fight (default is the scenario's count)")
+	cmdCombatSimulate.Flags().Uint32Var(&argsCombatSimulate.seed, "seed", 0, "random seed (default is the scenario's seed, or the library's)")
+	cmdCombatSimulate.Flags().StringVar(&argsCombatSimulate.format, "format", "text", "output format (text or json)")
+	cmdCombatSimulate.Flags().StringVar(&argsCombatSimulate.output, "output", "", "file to write the results to (default is stdout)")
+}
@@ -0,0 +1,117 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+)
+
+// cmdDB runs the db command
+var cmdDB = &cobra.Command{
+	Use:   "db",
+	Short: "manage the box database",
+	Run: func(cmd *cobra.Command, args []string) {
+	},
+}
+
+// cmdDBConvert runs the db convert command
+var cmdDBConvert = &cobra.Command{
+	Use:   "convert",
+	Short: "convert the box database between the text and json formats",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		} else if argsDBConvert.to == "" {
+			return fmt.Errorf("missing to parameter")
+		}
+
+		options := []olympia.Option{
+			olympia.WithLibPath(argsRoot.libdir),
+			olympia.WithTimeSelf(argsRoot.time_self),
+			olympia.WithLockWait(argsRoot.wait),
+		}
+		if argsDBConvert.from != "" {
+			options = append(options, olympia.WithDBFormat(argsDBConvert.from))
+		}
+
+		return olympia.ConvertDB(argsDBConvert.to, options...)
+	},
+}
+
+// cmdDBVerify runs the db verify command
+var cmdDBVerify = &cobra.Command{
+	Use:   "verify",
+	Short: "check that the box database survives a save and reload",
+	Long: `Loads the library, writes it to a temporary directory, reloads it,
+and reports every attribute that was lost or altered. The boxes are compared
+in memory, field by field, skipping only the fields the engine never saves.
+The library is not changed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		}
+
+		options := []olympia.Option{
+			olympia.WithLibPath(argsRoot.libdir),
+			olympia.WithTimeSelf(argsRoot.time_self),
+			olympia.WithLockWait(argsRoot.wait),
+		}
+		if argsDBVerify.from != "" {
+			options = append(options, olympia.WithDBFormat(argsDBVerify.from))
+		}
+
+		diffs, err := olympia.VerifyDB(argsDBVerify.format, options...)
+		if err != nil {
+			return err
+		}
+		for _, diff := range diffs {
+			fmt.Println(diff)
+		}
+		if len(diffs) != 0 {
+			return fmt.Errorf("%d attributes lost or altered", len(diffs))
+		}
+		fmt.Printf("db verify: %s round trip ok\n", argsDBVerify.format)
+		return nil
+	},
+}
+
+var argsDBConvert struct {
+	from string
+	to   string
+}
+
+var argsDBVerify struct {
+	from   string
+	format string
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdDB)
+
+	cmdDB.AddCommand(cmdDBConvert)
+	cmdDBConvert.Flags().StringVar(&argsDBConvert.from, "from", "", "format to load (text or json), default is the format recorded in sysdata")
+	cmdDBConvert.Flags().StringVar(&argsDBConvert.to, "to", "", "format to save (text or json)")
+
+	cmdDB.AddCommand(cmdDBVerify)
+	cmdDBVerify.Flags().StringVar(&argsDBVerify.from, "from", "", "format to load (text or json), default is the format recorded in sysdata")
+	cmdDBVerify.Flags().StringVar(&argsDBVerify.format, "format", olympia.DB_TEXT, "format to round trip through (text or json)")
+}
@@ -21,16 +21,23 @@ package cli
 
 import (
 	"fmt"
-	"github.com/mdhender/golympia/pkg/maps"
 	"github.com/mdhender/golympia/pkg/olympia"
 	"github.com/spf13/cobra"
-	"log"
 )
 
 // cmdGenerateMap runs the map generator command
 var cmdGenerateMap = &cobra.Command{
 	Use:   "map",
 	Short: "generate a new map",
+	Long: `Builds a world from the ASCII map and the land and region files, then
+checks it for land that can't be reached from the start cities, regions
+missing from the region file, hidden provinces whose terrain is ambiguous,
+and continents without cities or connections.
+
+With --preview the map is drawn (as PNG if the name ends in .png, otherwise
+as SVG) before anything is saved. With --dry-run nothing is written to
+lib-dir. A map with fatal problems is only saved with --force. Saving takes
+the lib-dir lock, so a turn or the portal can't read a half-written world.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if argsRoot.libdir == "" {
 			return fmt.Errorf("missing lib-dir parameter")
@@ -59,22 +66,44 @@ var cmdGenerateMap = &cobra.Command{
 		options = append(options, olympia.WithRegionData(argsGenerateMap.regionFileName))
 		options = append(options, olympia.WithRoadData(argsGenerateMap.roadFileName))
 		options = append(options, olympia.WithSeedData(argsGenerateMap.seedFileName))
+		options = append(options, olympia.WithLockWait(argsRoot.wait))
 
-		mc, err := maps.Read(argsGenerateMap.mapFileName)
-		if err != nil {
-			log.Fatal(err)
-		} else if mc != nil {
-			return nil
+		if argsGenerateMap.preview != "" {
+			options = append(options, olympia.WithMapPreview(argsGenerateMap.preview))
 		}
 
-		if err := olympia.GenerateMap(options...); err != nil {
-			log.Fatal(err)
+		v, err := olympia.GenerateMap(argsGenerateMap.dryRun, argsGenerateMap.force, options...)
+		if v != nil {
+			printMapValidation(v)
 		}
-
-		return nil
+		return err
 	},
 }
 
+// printMapValidation lists the continents and the problems found in the map.
+func printMapValidation(v *olympia.MapValidation) {
+	fmt.Printf("%-30s  %8s  %6s  %6s  %9s  %5s  %s\n", "continent", "coord", "provs", "cities", "gates o/i", "roads", "coast")
+	for _, c := range v.Continents {
+		coast := "no"
+		if c.Coastal {
+			coast = "yes"
+		}
+		fmt.Printf("%-30s  %8s  %6d  %6d  %9s  %5d  %s\n", c.Name, fmt.Sprintf("(%d,%d)", c.Row, c.Col), c.Provinces, c.Cities, fmt.Sprintf("%d/%d", c.GatesOut, c.GatesIn), c.Roads, coast)
+	}
+	if len(v.Issues) == 0 {
+		fmt.Printf("\nno problems found\n")
+		return
+	}
+	fmt.Printf("\n")
+	for _, i := range v.Issues {
+		level := "warn "
+		if i.Fatal {
+			level = "error"
+		}
+		fmt.Printf("%s  %8s  %-16s  %s\n", level, fmt.Sprintf("(%d,%d)", i.Row, i.Col), i.Kind, i.Message)
+	}
+}
+
 var argsGenerateMap struct {
 	mapFileName       string
 	cityFileName      string
@@ -85,6 +114,9 @@ var argsGenerateMap struct {
 	regionFileName    string
 	roadFileName      string
 	seedFileName      string
+	preview           string
+	dryRun            bool
+	force             bool
 }
 
 func init() {
@@ -102,6 +134,11 @@ func init() {
 	cmdGenerateMap.Flags().StringVar(&argsGenerateMap.roadFileName, "road-data", "roads.json", "road data to export")
 	cmdGenerateMap.Flags().StringVar(&argsGenerateMap.seedFileName, "seed-data", "randseed.json", "random seed data to export")
 
+	// validation
+	cmdGenerateMap.Flags().StringVar(&argsGenerateMap.preview, "preview", "", "draw the generated map to this SVG or PNG file before saving")
+	cmdGenerateMap.Flags().BoolVar(&argsGenerateMap.dryRun, "dry-run", false, "validate and preview the map without saving it")
+	cmdGenerateMap.Flags().BoolVar(&argsGenerateMap.force, "force", false, "save the map even if validation finds fatal problems")
+
 	//if err := cmdGenerateMap.MarkFlagRequired("map-data"); err != nil {
 	//	panic(err)
 	//}
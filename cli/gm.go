@@ -0,0 +1,169 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"strings"
+)
+
+// cmdGM runs the gm command
+var cmdGM = &cobra.Command{
+	Use:   "gm",
+	Short: "game master tools",
+	Run: func(cmd *cobra.Command, args []string) {
+	},
+}
+
+// cmdGMConsole runs the gm console command
+var cmdGMConsole = &cobra.Command{
+	Use:   "console",
+	Short: "run immediate commands against the database",
+	Long: `Loads the database and runs immediate commands as the GM.
+Commands are read from the terminal, with line editing, history, and
+tab completion of command names and entity codes, or from a script file.
+Type "save" to save the database and "quit" to leave the console.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		}
+
+		options := []olympia.Option{
+			olympia.WithLibPath(argsRoot.libdir),
+			olympia.WithTimeSelf(argsRoot.time_self),
+			olympia.WithLockWait(argsRoot.wait),
+			olympia.WithDryRun(argsGMConsole.dry_run),
+		}
+
+		var input olympia.ConsoleInput
+		if argsGMConsole.script != "" {
+			fp, err := os.Open(argsGMConsole.script)
+			if err != nil {
+				return err
+			}
+			defer fp.Close()
+			input = &scriptInput{r: bufio.NewReader(fp)}
+		} else if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+			input = newTerminalInput(fd)
+		} else {
+			input = &scriptInput{r: bufio.NewReader(os.Stdin)}
+		}
+
+		return olympia.GMConsole(input, options...)
+	},
+}
+
+var argsGMConsole struct {
+	dry_run bool
+	script  string
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdGM)
+
+	cmdGM.AddCommand(cmdGMConsole)
+	cmdGMConsole.Flags().BoolVar(&argsGMConsole.dry_run, "dry-run", false, "discard changes unless the save command is issued")
+	cmdGMConsole.Flags().StringVar(&argsGMConsole.script, "script", "", "run the immediate commands in this file instead of reading the terminal")
+}
+
+// scriptInput reads console commands from a file or pipe, echoing each one.
+type scriptInput struct {
+	r *bufio.Reader
+}
+
+func (si *scriptInput) ReadLine(prompt string) (string, error) {
+	line, err := si.r.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	} else if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	fmt.Printf("%s%s\n", prompt, line)
+	return line, nil
+}
+
+// terminalInput reads console commands from the terminal with line editing.
+// The terminal is only in raw mode while a line is being read, so the engine's
+// output is written to a normal terminal.
+type terminalInput struct {
+	fd int
+	t  *term.Terminal
+}
+
+func newTerminalInput(fd int) *terminalInput {
+	ti := &terminalInput{fd: fd}
+	ti.t = term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{os.Stdin, os.Stdout}, "")
+	ti.t.AutoCompleteCallback = ti.complete
+	return ti
+}
+
+func (ti *terminalInput) ReadLine(prompt string) (string, error) {
+	state, err := term.MakeRaw(ti.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(ti.fd, state)
+	if w, _, err := term.GetSize(ti.fd); err == nil {
+		_ = ti.t.SetSize(w, 0)
+	}
+	ti.t.SetPrompt(prompt)
+	return ti.t.ReadLine()
+}
+
+// complete expands the word before the cursor to the longest prefix
+// shared by the matching command names and entity codes.
+// When the word is ambiguous, the matches are listed.
+func (ti *terminalInput) complete(line string, pos int, key rune) (string, int, bool) {
+	if key != '\t' {
+		return "", 0, false
+	}
+	start := strings.LastIndexAny(line[:pos], " \t") + 1
+	matches := olympia.ConsoleComplete(line[start:pos])
+	if len(matches) == 0 {
+		return "", 0, false
+	}
+
+	word := matches[0]
+	for _, m := range matches[1:] {
+		for !strings.HasPrefix(strings.ToLower(m), strings.ToLower(word)) {
+			word = word[:len(word)-1]
+		}
+	}
+	if len(matches) == 1 {
+		word += " "
+	} else if len(word) <= pos-start {
+		if len(matches) > 40 {
+			matches = append(matches[:40], "...")
+		}
+		_, _ = fmt.Fprintf(ti.t, "%s\n", strings.Join(matches, " "))
+		return "", 0, false
+	}
+	return line[:start] + word + line[pos:], start + len(word), true
+}
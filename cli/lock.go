@@ -0,0 +1,92 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+)
+
+// cmdLock runs the lock command
+var cmdLock = &cobra.Command{
+	Use:   "lock",
+	Short: "manage the lib-dir lock",
+	Run: func(cmd *cobra.Command, args []string) {
+	},
+}
+
+// cmdLockStatus runs the lock status command
+var cmdLockStatus = &cobra.Command{
+	Use:   "status",
+	Short: "show who holds the lib-dir lock",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		}
+		li, err := olympia.LockStatus(olympia.WithLibPath(argsRoot.libdir))
+		if err != nil {
+			return err
+		} else if li == nil {
+			fmt.Printf("%s: not locked\n", argsRoot.libdir)
+			return nil
+		}
+		fmt.Printf("%s: locked by %s\n", argsRoot.libdir, li)
+		return nil
+	},
+}
+
+// cmdLockBreak runs the lock break command
+var cmdLockBreak = &cobra.Command{
+	Use:   "break",
+	Short: "remove the lib-dir lock",
+	Long: `Removes the lib-dir lock. A lock is only removed when its owner is
+known to be dead, which we can tell only for an owner on this host.
+Use --force to remove the lock of a process that may still be running.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		}
+		li, err := olympia.BreakLock(argsLockBreak.force, olympia.WithLibPath(argsRoot.libdir))
+		if errors.Is(err, olympia.ErrLocked) {
+			return fmt.Errorf("%w (use --force to remove it anyway)", err)
+		} else if err != nil {
+			return err
+		} else if li == nil {
+			fmt.Printf("%s: not locked\n", argsRoot.libdir)
+			return nil
+		}
+		fmt.Printf("%s: removed lock held by %s\n", argsRoot.libdir, li)
+		return nil
+	},
+}
+
+var argsLockBreak struct {
+	force bool
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdLock)
+	cmdLock.AddCommand(cmdLockStatus)
+	cmdLock.AddCommand(cmdLockBreak)
+
+	cmdLockBreak.Flags().BoolVar(&argsLockBreak.force, "force", false, "remove the lock even if its owner may still be running")
+}
@@ -0,0 +1,155 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/mdhender/golympia/pkg/worldmap"
+	"github.com/spf13/cobra"
+	"log"
+	"os"
+)
+
+// cmdMap runs the map command
+var cmdMap = &cobra.Command{
+	Use:   "map",
+	Short: "draw and export the world map",
+	Run: func(cmd *cobra.Command, args []string) {
+	},
+}
+
+// cmdMapRender runs the map render command
+var cmdMapRender = &cobra.Command{
+	Use:   "render",
+	Short: "draw the world map as SVG and PNG images",
+	Long: `Draws the surface provinces from the database with terrain colors,
+region borders, cities, roads, gates, and sea lanes. By default the map shows
+everything; with --faction it only shows what that faction knows about.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		} else if argsMapRender.svg == "" && argsMapRender.png == "" {
+			return fmt.Errorf("missing svg or png parameter")
+		}
+
+		options := []olympia.Option{
+			olympia.WithLibPath(argsRoot.libdir),
+			olympia.WithTimeSelf(argsRoot.time_self),
+			olympia.WithLockWait(argsRoot.wait),
+		}
+
+		m, err := olympia.WorldMap(argsMapRender.faction, options...)
+		if err != nil {
+			return err
+		}
+		opts := worldmap.Options{Cell: argsMapRender.cell}
+
+		if argsMapRender.svg != "" {
+			b := &bytes.Buffer{}
+			if err := m.SVG(b, opts); err != nil {
+				return err
+			} else if err := os.WriteFile(argsMapRender.svg, b.Bytes(), 0666); err != nil {
+				return err
+			}
+			log.Printf("map: created %s\n", argsMapRender.svg)
+		}
+		if argsMapRender.png != "" {
+			b := &bytes.Buffer{}
+			if err := m.PNG(b, opts); err != nil {
+				return err
+			} else if err := os.WriteFile(argsMapRender.png, b.Bytes(), 0666); err != nil {
+				return err
+			}
+			log.Printf("map: created %s\n", argsMapRender.png)
+		}
+
+		return nil
+	},
+}
+
+// cmdMapAtlas runs the map atlas command
+var cmdMapAtlas = &cobra.Command{
+	Use:   "atlas",
+	Short: "export what a faction knows about the world as JSON",
+	Long: `Writes the provinces, sublocations, roads, and gates known to a faction,
+with the turn each location was last visited. The same atlas is written
+to the log directory alongside each turn report.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		} else if argsMapAtlas.faction == "" {
+			return fmt.Errorf("missing faction parameter")
+		}
+
+		options := []olympia.Option{
+			olympia.WithLibPath(argsRoot.libdir),
+			olympia.WithTimeSelf(argsRoot.time_self),
+			olympia.WithLockWait(argsRoot.wait),
+		}
+
+		a, err := olympia.FactionAtlas(argsMapAtlas.faction, options...)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(a, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if argsMapAtlas.output == "" {
+			fmt.Println(string(data))
+			return nil
+		} else if err := os.WriteFile(argsMapAtlas.output, data, 0666); err != nil {
+			return err
+		}
+		log.Printf("map: created %s\n", argsMapAtlas.output)
+
+		return nil
+	},
+}
+
+var argsMapAtlas struct {
+	faction string
+	output  string
+}
+
+var argsMapRender struct {
+	cell    int
+	faction string
+	png     string
+	svg     string
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdMap)
+
+	cmdMap.AddCommand(cmdMapAtlas)
+	cmdMapAtlas.Flags().StringVar(&argsMapAtlas.faction, "faction", "", "faction to export")
+	cmdMapAtlas.Flags().StringVar(&argsMapAtlas.output, "output", "", "name of the JSON file to create (default is stdout)")
+
+	cmdMap.AddCommand(cmdMapRender)
+	cmdMapRender.Flags().IntVar(&argsMapRender.cell, "cell", worldmap.DefaultCell, "size of a province in pixels")
+	cmdMapRender.Flags().StringVar(&argsMapRender.faction, "faction", "", "only show what this faction knows (default is the GM view)")
+	cmdMapRender.Flags().StringVar(&argsMapRender.png, "png", "", "name of the PNG file to create")
+	cmdMapRender.Flags().StringVar(&argsMapRender.svg, "svg", "", "name of the SVG file to create")
+}
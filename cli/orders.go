@@ -0,0 +1,119 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+// cmdOrders runs the orders command
+var cmdOrders = &cobra.Command{
+	Use:   "orders",
+	Short: "work with player orders",
+	Run: func(cmd *cobra.Command, args []string) {
+	},
+}
+
+// cmdOrdersCheck runs the orders check command
+var cmdOrdersCheck = &cobra.Command{
+	Use:   "check file",
+	Short: "check a set of orders without queueing them",
+	Long: `Runs the order scanner against a file of orders (or a mail message
+containing them) using the loaded database. Nothing is queued or mailed,
+and nothing in lib-dir is changed. The lib-dir lock is only taken while a
+text-format database is loaded, since a turn may be in the middle of saving it.
+By default the results are written as JSON; use --text for the acknowledgement.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		}
+		oc, ack, err := olympia.CheckOrders(args[0], olympia.WithLibPath(argsRoot.libdir), olympia.WithLockWait(argsRoot.wait))
+		if err != nil {
+			return err
+		}
+		if argsOrdersCheck.text {
+			_, err = os.Stdout.Write(ack)
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(oc)
+	},
+}
+
+var argsOrdersCheck struct {
+	text bool
+}
+
+// cmdOrdersCommands runs the orders commands command
+var cmdOrdersCommands = &cobra.Command{
+	Use:   "commands",
+	Short: "list the orders the game accepts",
+	Long: `Lists the orders from the game's command definitions: the lib-dir's
+commands.json if it has one, otherwise the definitions built into the engine.
+The definitions are checked the same way they are when the game loads, so this
+is also how to check an edited commands.json. Use --json to print the
+definitions themselves, e.g. as a starting point for a variant game.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var options []olympia.Option
+		if argsRoot.libdir != "" {
+			options = append(options, olympia.WithLibPath(argsRoot.libdir))
+		}
+		defs, err := olympia.CommandDefinitions(options...)
+		if err != nil {
+			return err
+		}
+		if argsOrdersCommands.json {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			enc.SetEscapeHTML(false)
+			return enc.Encode(defs)
+		}
+		for _, d := range defs.Commands {
+			if d.Disabled {
+				continue
+			}
+			name := d.Name
+			if len(d.Aliases) != 0 {
+				name = fmt.Sprintf("%s (%s)", name, strings.Join(d.Aliases, ", "))
+			}
+			fmt.Printf("%-20s %s\n", name, d.Help)
+		}
+		return nil
+	},
+}
+
+var argsOrdersCommands struct {
+	json bool
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdOrders)
+	cmdOrders.AddCommand(cmdOrdersCheck)
+	cmdOrders.AddCommand(cmdOrdersCommands)
+	cmdOrdersCheck.Flags().BoolVar(&argsOrdersCheck.text, "text", false, "print the acknowledgement instead of JSON")
+	cmdOrdersCommands.Flags().BoolVar(&argsOrdersCommands.json, "json", false, "print the definitions as JSON")
+}
@@ -0,0 +1,150 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// cmdPlayers runs the players command
+var cmdPlayers = &cobra.Command{
+	Use:   "players",
+	Short: "manage new player signups",
+	Run: func(cmd *cobra.Command, args []string) {
+	},
+}
+
+// cmdPlayersAdd runs the players add command
+var cmdPlayersAdd = &cobra.Command{
+	Use:   "add [file.json...]",
+	Short: "check and queue join requests for the next turn",
+	Long: `Checks join requests against the database and the joins already queued,
+and queues the ones that pass. Each file holds a join request or a list of
+them. With no files, the request is built from the flags.
+
+The new players are added when the next turn runs (or by "turn add-players").`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		}
+
+		var joins []*olympia.JoinRequest
+		for _, name := range args {
+			data, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			list, err := olympia.ParseJoinRequests(data)
+			if err != nil {
+				return fmt.Errorf("%s: %w", name, err)
+			}
+			joins = append(joins, list...)
+		}
+		if len(args) == 0 {
+			j := argsPlayersAdd.join
+			joins = append(joins, &j)
+		}
+
+		options := []olympia.Option{
+			olympia.WithLibPath(argsRoot.libdir),
+			olympia.WithTimeSelf(argsRoot.time_self),
+			olympia.WithLockWait(argsRoot.wait),
+		}
+		results, err := olympia.QueueJoins(joins, argsPlayersAdd.dry_run, options...)
+		if err != nil {
+			return err
+		}
+		return printJoinResults(results, argsPlayersAdd.dry_run)
+	},
+}
+
+// cmdPlayersCheck runs the players check command
+var cmdPlayersCheck = &cobra.Command{
+	Use:   "check",
+	Short: "report which queued join requests would succeed",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		}
+
+		options := []olympia.Option{
+			olympia.WithLibPath(argsRoot.libdir),
+			olympia.WithTimeSelf(argsRoot.time_self),
+			olympia.WithLockWait(argsRoot.wait),
+		}
+		results, err := olympia.CheckJoins(options...)
+		if err != nil {
+			return err
+		}
+		return printJoinResults(results, true)
+	},
+}
+
+// printJoinResults lists the requests and returns an error if any failed.
+func printJoinResults(results []olympia.JoinResult, dryRun bool) error {
+	failed := 0
+	for _, r := range results {
+		who := "?"
+		if r.Request != nil {
+			who = fmt.Sprintf("%q (%s <%s>)", r.Request.Faction, r.Request.FullName, r.Request.EMail)
+		}
+		if r.Name != "" {
+			who = r.Name + ": " + who
+		}
+		switch {
+		case r.Error != "":
+			failed++
+			fmt.Printf("fail  %s: %s\n", who, r.Error)
+		case dryRun:
+			fmt.Printf("ok    %s\n", who)
+		default:
+			fmt.Printf("ok    %s: queued\n", who)
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d of %d join requests failed", failed, len(results))
+	}
+	return nil
+}
+
+var argsPlayersAdd struct {
+	dry_run bool
+	join    olympia.JoinRequest
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdPlayers)
+
+	cmdPlayers.AddCommand(cmdPlayersAdd)
+	cmdPlayersAdd.Flags().BoolVar(&argsPlayersAdd.dry_run, "dry-run", false, "check the requests without queueing them")
+	cmdPlayersAdd.Flags().StringVar(&argsPlayersAdd.join.Account, "account", "", "faction code to use (default is to assign one)")
+	cmdPlayersAdd.Flags().StringVar(&argsPlayersAdd.join.Faction, "faction", "", "name of the faction")
+	cmdPlayersAdd.Flags().StringVar(&argsPlayersAdd.join.Character, "character", "", "name of the starting noble")
+	cmdPlayersAdd.Flags().StringVar(&argsPlayersAdd.join.FullName, "full-name", "", "player's full name")
+	cmdPlayersAdd.Flags().StringVar(&argsPlayersAdd.join.EMail, "email", "", "player's e-mail address")
+	cmdPlayersAdd.Flags().StringVar(&argsPlayersAdd.join.Password, "password", "", "faction password")
+	cmdPlayersAdd.Flags().StringVar(&argsPlayersAdd.join.Nation, "nation", "", "nation to join")
+	cmdPlayersAdd.Flags().StringVar(&argsPlayersAdd.join.StartCity, "start-city", "", `city code or "random" (default is the nation's capital)`)
+
+	cmdPlayers.AddCommand(cmdPlayersCheck)
+}
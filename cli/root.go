@@ -38,36 +38,20 @@ var cmdRoot = &cobra.Command{
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		started := time.Now()
-		if argsRoot.add_flag {
-			argsRoot.immediate = false
-		}
-		if argsRoot.eat_flag {
-			argsRoot.immediate = false
-		}
-		if argsRoot.run_flag {
-			argsRoot.immediate = false
-		}
 
 		log.Printf("%-20s == %q\n", "lib-dir", argsRoot.libdir)
 
-		if argsRoot.combat_test_flag {
+		if argsRoot.testJsonLoad {
 			if argsRoot.libdir == "" {
 				return fmt.Errorf("missing lib-dir argument")
 			}
-			if err := olympia.TestCombat(argsRoot.libdir); err != nil {
-				log.Fatal(err)
+			count, err := olympia.LoadDB(olympia.WithLibPath(argsRoot.libdir), olympia.WithDBFormat(olympia.DB_JSON))
+			if err != nil {
+				return err
 			}
+			log.Printf("test-json-load: loaded %d boxes\n", count)
 		}
 
-		//if argsRoot.testJsonLoad {
-		//	if argsRoot.libdir == "" {
-		//		return fmt.Errorf("missing lib-dir argument")
-		//	}
-		//	if _, err := store.Load(argsRoot.libdir, true); err != nil {
-		//		log.Fatal(err)
-		//	}
-		//}
-
 		if argsRoot.time_self {
 			elapsed := time.Now().Sub(started)
 			log.Printf("elapsed time: %v\n", elapsed)
@@ -84,31 +68,24 @@ func Execute() error {
 }
 
 var argsRoot struct {
-	acct_flag          bool
-	add_flag           bool
-	art_flag           bool
-	combat_test_flag   bool
-	eat_flag           bool
-	flush_always       bool
-	immed_after        bool
-	immediate          bool
-	inhibit_add_flag   bool
-	libdir             string
-	lore_flag          bool
-	mail_now           bool
-	map_flag           bool
-	map_test_flag      bool
-	pretty_data_files  bool
-	run_flag           bool
-	save_flag          bool
-	test_prng_flag     bool
-	test_lists_flag    bool
-	testJsonLoad       bool
-	time_self          bool
-	unspool_first_flag bool
+	acct_flag         bool
+	art_flag          bool
+	flush_always      bool
+	libdir            string
+	lore_flag         bool
+	map_flag          bool
+	map_test_flag     bool
+	pretty_data_files bool
+	save_flag         bool
+	test_prng_flag    bool
+	test_lists_flag   bool
+	testJsonLoad      bool
+	time_self         bool
+	wait              time.Duration
 }
 
 func init() {
+	// -a, -e, -E, -i, -M, -r, and -x are now the "turn" sub-commands.
 	//fprintf(stderr, "usage: oly [options]\n");
 	//fprintf(stderr, "  -a        Add new players mode\n");
 	//fprintf(stderr, "  -e        Eat orders from libdir/spool\n");
@@ -130,22 +107,15 @@ func init() {
 	cmdRoot.PersistentFlags().StringVar(&argsRoot.libdir, "lib-dir", "", "set lib path")
 	cmdRoot.PersistentFlags().BoolVar(&argsRoot.save_flag, "save-db", false, "set save-db-flag")
 	cmdRoot.PersistentFlags().BoolVar(&argsRoot.time_self, "time", false, "time commands")
+	cmdRoot.PersistentFlags().DurationVar(&argsRoot.wait, "wait", 0, "how long to wait for another process to release the lib-dir lock")
 
-	cmdRoot.Flags().BoolVar(&argsRoot.add_flag, "a", false, "set add-flag")
-	cmdRoot.Flags().BoolVar(&argsRoot.eat_flag, "e", false, "set eat-flag")
 	cmdRoot.Flags().BoolVar(&argsRoot.flush_always, "f", false, "set flush-always-flag")
-	cmdRoot.Flags().BoolVar(&argsRoot.immed_after, "i", false, "set immed-after-flag")
 	cmdRoot.Flags().BoolVar(&argsRoot.map_flag, "m", false, "set map-flag")
 	cmdRoot.Flags().BoolVar(&argsRoot.pretty_data_files, "p", false, "set pretty-data-files-flag")
 	cmdRoot.Flags().BoolVar(&argsRoot.art_flag, "q", false, "set test-artifacts-flag")
-	cmdRoot.Flags().BoolVar(&argsRoot.run_flag, "r", false, "set run-turn-flag")
-	cmdRoot.Flags().BoolVar(&argsRoot.inhibit_add_flag, "x", false, "set inhibit-add-flag")
 	cmdRoot.Flags().BoolVar(&argsRoot.acct_flag, "A", false, "set acct-flag")
-	cmdRoot.Flags().BoolVar(&argsRoot.unspool_first_flag, "E", false, "set unspool-first-flag")
 	cmdRoot.Flags().BoolVar(&argsRoot.lore_flag, "L", false, "set lore-flag")
-	cmdRoot.Flags().BoolVar(&argsRoot.mail_now, "M", false, "set mail-now-flag")
 
-	cmdRoot.Flags().BoolVar(&argsRoot.combat_test_flag, "test-combat", false, "set combat-test-flag")
 	cmdRoot.Flags().BoolVar(&argsRoot.testJsonLoad, "test-json-load", false, "test load from json store")
 	cmdRoot.Flags().BoolVar(&argsRoot.test_lists_flag, "t", false, "set test-lists-flag")
 	cmdRoot.Flags().BoolVar(&argsRoot.test_prng_flag, "R", false, "set test-prng-flag")
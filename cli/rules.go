@@ -0,0 +1,67 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"encoding/json"
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// cmdRules runs the rules command
+var cmdRules = &cobra.Command{
+	Use:   "rules",
+	Short: "game rules profiles",
+	Run: func(cmd *cobra.Command, args []string) {
+	},
+}
+
+// cmdRulesShow runs the rules show command
+var cmdRulesShow = &cobra.Command{
+	Use:   "show",
+	Short: "print the game's rules profile",
+	Long: `Prints the rules profile the game plays by: the balance settings from
+sysdata.json and the engine defaults, with the values in the lib-dir's
+rules.json laid over them. The profile is checked the same way it is when
+the game loads, so this is also how to check an edited rules.json. The
+output is itself a complete rules.json, e.g. as a starting point for a
+variant game.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var options []olympia.Option
+		if argsRoot.libdir != "" {
+			options = append(options, olympia.WithLibPath(argsRoot.libdir))
+		}
+		rules, err := olympia.RulesProfile(options...)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(rules)
+	},
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdRules)
+	cmdRules.AddCommand(cmdRulesShow)
+}
@@ -0,0 +1,86 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+)
+
+// cmdServe runs the web portal
+var cmdServe = &cobra.Command{
+	Use:   "serve",
+	Short: "run the web portal for reports, orders, and the Times",
+	Long: `Runs a web server for the players. Players sign in with their faction
+code and password to read their turn reports and upload orders. Uploaded
+orders are left in lib-dir/spool for the next "turn eat". The Times and
+the rules are public. New players post JSON join requests to /join, which
+are checked and queued for the next turn. Each address may post five join
+requests an hour, and no more than 100 may wait for the next turn; behind
+a proxy, every player shares the proxy's address.
+
+Players send their passwords with every request, so the portal serves
+HTTPS with --tls-cert and --tls-key. Use --plain-http only when a reverse
+proxy in front of the portal terminates TLS. Orders posted by a browser
+from another site are refused, so a proxy must pass the Host header
+through unchanged.
+
+The portal never takes the lib-dir lock. The player list is reloaded in
+the background after a turn saves the database; while another command
+holds the lock, the portal keeps serving the list it has.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		}
+
+		options := []olympia.Option{
+			olympia.WithLibPath(argsRoot.libdir),
+			olympia.WithTimeSelf(argsRoot.time_self),
+			olympia.WithLockWait(argsRoot.wait),
+		}
+		if argsServe.rules != "" {
+			options = append(options, olympia.WithPortalRules(argsServe.rules))
+		}
+		if argsServe.tls_cert != "" || argsServe.tls_key != "" {
+			options = append(options, olympia.WithTLS(argsServe.tls_cert, argsServe.tls_key))
+		}
+		options = append(options, olympia.WithPlainHTTP(argsServe.plain_http))
+
+		return olympia.Serve(argsServe.addr, options...)
+	},
+}
+
+var argsServe struct {
+	addr       string
+	plain_http bool
+	rules      string
+	tls_cert   string
+	tls_key    string
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdServe)
+	cmdServe.Flags().StringVar(&argsServe.addr, "addr", "localhost:8080", "address to listen on")
+	cmdServe.Flags().BoolVar(&argsServe.plain_http, "plain-http", false, "serve without TLS (only behind a proxy that terminates TLS)")
+	cmdServe.Flags().StringVar(&argsServe.rules, "rules", "", "directory of rules pages to serve")
+	cmdServe.Flags().StringVar(&argsServe.tls_cert, "tls-cert", "", "certificate file")
+	cmdServe.Flags().StringVar(&argsServe.tls_key, "tls-key", "", "key file")
+}
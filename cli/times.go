@@ -0,0 +1,161 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// cmdTimes runs the times command
+var cmdTimes = &cobra.Command{
+	Use:   "times",
+	Short: "manage the Times newspaper",
+	Run: func(cmd *cobra.Command, args []string) {
+	},
+}
+
+// cmdTimesEditorial runs the times editorial command
+var cmdTimesEditorial = &cobra.Command{
+	Use:   "editorial",
+	Short: "add an editorial section to the next issue",
+	Long: `Queues an editorial for the next issue of the Times. The text is read
+from --file, or from standard input when no file is given. Editorials run
+ahead of the game information and are printed in the order they were added.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		}
+
+		var body []byte
+		var err error
+		if argsTimesEditorial.file == "" || argsTimesEditorial.file == "-" {
+			body, err = io.ReadAll(os.Stdin)
+		} else {
+			body, err = os.ReadFile(argsTimesEditorial.file)
+		}
+		if err != nil {
+			return err
+		}
+
+		options := []olympia.Option{
+			olympia.WithLibPath(argsRoot.libdir),
+			olympia.WithTimeSelf(argsRoot.time_self),
+			olympia.WithLockWait(argsRoot.wait),
+		}
+		return olympia.AddEditorial(argsTimesEditorial.title, argsTimesEditorial.author, string(body), options...)
+	},
+}
+
+// cmdTimesShow runs the times show command
+var cmdTimesShow = &cobra.Command{
+	Use:   "show [turn]",
+	Short: "print an issue from the archive",
+	Long:  `Prints the issue for the turn, or the latest issue, as text, markdown, or html.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		}
+		turn := 0
+		if len(args) != 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid turn %q", args[0])
+			}
+			turn = n
+		}
+
+		is, err := olympia.TimesIssue(turn, olympia.WithLibPath(argsRoot.libdir))
+		if err != nil {
+			return err
+		}
+		switch argsTimesShow.format {
+		case "text":
+			return is.Text(os.Stdout)
+		case "markdown":
+			return is.Markdown(os.Stdout)
+		case "html":
+			return is.HTML(os.Stdout)
+		}
+		return fmt.Errorf("unknown format %q", argsTimesShow.format)
+	},
+}
+
+// cmdTimesSearch runs the times search command
+var cmdTimesSearch = &cobra.Command{
+	Use:   "search <words...>",
+	Short: "search the back issues",
+	Long:  `Lists the articles in the archive that contain every word, ignoring case, newest first.`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		}
+
+		matches, err := olympia.SearchTimes(strings.Join(args, " "), olympia.WithLibPath(argsRoot.libdir))
+		if err != nil {
+			return err
+		}
+		for _, m := range matches {
+			head := fmt.Sprintf("turn %d, %s", m.Turn, m.Article.Kind)
+			if m.Article.Title != "" {
+				head += ": " + m.Article.Title
+			}
+			if m.Article.Author != "" {
+				head += " -- " + m.Article.Author
+			}
+			fmt.Printf("%s\n%s\n", head, m.Article.Body)
+		}
+		if len(matches) == 0 {
+			fmt.Printf("nothing found\n")
+		}
+		return nil
+	},
+}
+
+var argsTimesEditorial struct {
+	title  string
+	author string
+	file   string
+}
+
+var argsTimesShow struct {
+	format string
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdTimes)
+
+	cmdTimes.AddCommand(cmdTimesEditorial)
+	cmdTimesEditorial.Flags().StringVar(&argsTimesEditorial.title, "title", "", "headline for the editorial")
+	cmdTimesEditorial.Flags().StringVar(&argsTimesEditorial.author, "author", "The Editors", "byline for the editorial")
+	cmdTimesEditorial.Flags().StringVar(&argsTimesEditorial.file, "file", "", "file holding the text (default is standard input)")
+
+	cmdTimes.AddCommand(cmdTimesShow)
+	cmdTimesShow.Flags().StringVar(&argsTimesShow.format, "format", "text", "output format (text, markdown, or html)")
+
+	cmdTimes.AddCommand(cmdTimesSearch)
+}
@@ -0,0 +1,81 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+)
+
+// cmdTurn runs the turn command
+var cmdTurn = &cobra.Command{
+	Use:   "turn",
+	Short: "run the steps of turn processing",
+	Run: func(cmd *cobra.Command, args []string) {
+	},
+}
+
+// turnOptions returns the options shared by all the turn commands.
+func turnOptions() ([]olympia.Option, error) {
+	if argsRoot.libdir == "" {
+		return nil, fmt.Errorf("missing lib-dir parameter")
+	}
+
+	var options []olympia.Option
+	options = append(options, olympia.WithLibPath(argsRoot.libdir))
+	options = append(options, olympia.WithSaveDB(argsRoot.save_flag))
+	options = append(options, olympia.WithTimeSelf(argsRoot.time_self))
+	options = append(options, olympia.WithLockWait(argsRoot.wait))
+	if argsTurn.db_format != "" {
+		options = append(options, olympia.WithDBFormat(argsTurn.db_format))
+	}
+	if argsTurn.save_format != "" {
+		options = append(options, olympia.WithSaveFormat(argsTurn.save_format))
+	}
+	if argsTurn.mail_dir != "" {
+		options = append(options, olympia.WithMailDir(argsTurn.mail_dir))
+	}
+	for _, path := range argsTurn.orders_maildir {
+		options = append(options, olympia.WithMaildir(path))
+	}
+	for _, path := range argsTurn.orders_mbox {
+		options = append(options, olympia.WithMbox(path))
+	}
+
+	return options, nil
+}
+
+var argsTurn struct {
+	db_format      string
+	save_format    string
+	mail_dir       string
+	orders_maildir []string
+	orders_mbox    []string
+}
+
+func init() {
+	cmdRoot.AddCommand(cmdTurn)
+	cmdTurn.PersistentFlags().StringVar(&argsTurn.db_format, "db-format", "", "format of the box database (text or json), default is the format recorded in sysdata")
+	cmdTurn.PersistentFlags().StringVar(&argsTurn.save_format, "save-format", "", "format to save the box database in (text or json), default is the format loaded")
+	cmdTurn.PersistentFlags().StringVar(&argsTurn.mail_dir, "mail-dir", "", "write mail to .eml files in this directory instead of sending it")
+	cmdTurn.PersistentFlags().StringSliceVar(&argsTurn.orders_maildir, "orders-maildir", nil, "also read orders from this Maildir")
+	cmdTurn.PersistentFlags().StringSliceVar(&argsTurn.orders_mbox, "orders-mbox", nil, "also read orders from this mbox file")
+}
@@ -0,0 +1,49 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+)
+
+// cmdTurnAddPlayers runs the add players command
+var cmdTurnAddPlayers = &cobra.Command{
+	Use:   "add-players",
+	Short: "add new players",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options, err := turnOptions()
+		if err != nil {
+			return err
+		}
+		options = append(options, olympia.WithMailNow(argsTurnAddPlayers.mail_now))
+
+		return olympia.AddPlayers(options...)
+	},
+}
+
+var argsTurnAddPlayers struct {
+	mail_now bool
+}
+
+func init() {
+	cmdTurn.AddCommand(cmdTurnAddPlayers)
+	cmdTurnAddPlayers.Flags().BoolVar(&argsTurnAddPlayers.mail_now, "mail", false, "mail initial reports")
+}
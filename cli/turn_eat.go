@@ -0,0 +1,67 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+)
+
+// cmdTurnEat runs the eat orders command
+var cmdTurnEat = &cobra.Command{
+	Use:   "eat",
+	Short: "eat orders from lib-dir/spool and the other order sources",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options, err := turnOptions()
+		if err != nil {
+			return err
+		}
+		options = append(options, olympia.WithEatOnce(argsTurnEat.once))
+		options = append(options, olympia.WithMailNow(argsTurnEat.mail_now))
+		if argsTurnEat.http != "" {
+			options = append(options, olympia.WithOrdersHTTP(argsTurnEat.http))
+			if argsTurnEat.tls_cert != "" || argsTurnEat.tls_key != "" {
+				options = append(options, olympia.WithTLS(argsTurnEat.tls_cert, argsTurnEat.tls_key))
+			}
+			options = append(options, olympia.WithPlainHTTP(argsTurnEat.plain_http))
+		}
+
+		return olympia.EatOrders(options...)
+	},
+}
+
+var argsTurnEat struct {
+	http       string
+	mail_now   bool
+	once       bool
+	plain_http bool
+	tls_cert   string
+	tls_key    string
+}
+
+func init() {
+	cmdTurn.AddCommand(cmdTurnEat)
+	cmdTurnEat.Flags().StringVar(&argsTurnEat.http, "http", "", "accept orders posted to ADDR/orders while polling (needs --tls-cert and --tls-key, or --plain-http)")
+	cmdTurnEat.Flags().BoolVar(&argsTurnEat.mail_now, "mail", false, "mail acknowledgements")
+	cmdTurnEat.Flags().BoolVar(&argsTurnEat.once, "once", false, "process the spool once instead of polling")
+	cmdTurnEat.Flags().BoolVar(&argsTurnEat.plain_http, "plain-http", false, "serve --http without TLS (only behind a proxy that terminates TLS)")
+	cmdTurnEat.Flags().StringVar(&argsTurnEat.tls_cert, "tls-cert", "", "certificate file for --http")
+	cmdTurnEat.Flags().StringVar(&argsTurnEat.tls_key, "tls-key", "", "key file for --http")
+}
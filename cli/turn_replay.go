@@ -0,0 +1,70 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+	"strconv"
+)
+
+// cmdTurnReplay runs the replay command
+var cmdTurnReplay = &cobra.Command{
+	Use:   "replay turn",
+	Short: "re-run a turn from its snapshot and check that it reproduces exactly",
+	Long: `Restores the snapshot taken before the turn into a temporary directory,
+runs the turn again, and compares the database and reports against the
+checksums recorded when the turn was first run. Any difference is an error.
+The library is not changed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		}
+		turn, err := strconv.Atoi(args[0])
+		if err != nil || turn < 1 {
+			return fmt.Errorf("replay: invalid turn %q", args[0])
+		}
+
+		options := []olympia.Option{
+			olympia.WithLibPath(argsRoot.libdir),
+			olympia.WithTimeSelf(argsRoot.time_self),
+			olympia.WithLockWait(argsRoot.wait),
+		}
+
+		diffs, err := olympia.ReplayTurn(turn, options...)
+		if err != nil {
+			return err
+		}
+		for _, diff := range diffs {
+			fmt.Println(diff)
+		}
+		if len(diffs) != 0 {
+			return fmt.Errorf("replay: turn %d diverged in %d files", turn, len(diffs))
+		}
+		fmt.Printf("replay: turn %d reproduced exactly\n", turn)
+		return nil
+	},
+}
+
+func init() {
+	cmdTurn.AddCommand(cmdTurnReplay)
+}
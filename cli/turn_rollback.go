@@ -0,0 +1,78 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"fmt"
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+	"strconv"
+)
+
+// cmdTurnRollback runs the rollback command
+var cmdTurnRollback = &cobra.Command{
+	Use:   "rollback [turn]",
+	Short: "restore the database to the state before a turn was run",
+	Long: `Restores the system data, PRNG seed, box database, and orders
+from the snapshot taken before the given turn was run, so that the turn
+can be run again. With no turn, lists the snapshots that are available.
+
+Snapshots are only taken by "turn run --save-db"; a turn run without it
+saves nothing, so there is nothing to roll back.
+
+The saved reports, Times issues, and logs from the turn and the ones
+after it, and the snapshots of the later turns, are moved to
+lib-dir/rolled-back.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if argsRoot.libdir == "" {
+			return fmt.Errorf("missing lib-dir parameter")
+		}
+
+		if len(args) == 0 {
+			turns, err := olympia.Snapshots(olympia.WithLibPath(argsRoot.libdir))
+			if err != nil {
+				return err
+			} else if len(turns) == 0 {
+				fmt.Println("rollback: no snapshots")
+			}
+			for _, turn := range turns {
+				fmt.Printf("rollback: turn %d\n", turn)
+			}
+			return nil
+		}
+
+		turn, err := strconv.Atoi(args[0])
+		if err != nil || turn < 1 {
+			return fmt.Errorf("rollback: invalid turn %q", args[0])
+		}
+
+		options := []olympia.Option{
+			olympia.WithLibPath(argsRoot.libdir),
+			olympia.WithLockWait(argsRoot.wait),
+		}
+
+		return olympia.RollbackTurn(turn, options...)
+	},
+}
+
+func init() {
+	cmdTurn.AddCommand(cmdTurnRollback)
+}
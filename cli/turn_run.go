@@ -0,0 +1,58 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package cli
+
+import (
+	"github.com/mdhender/golympia/pkg/olympia"
+	"github.com/spf13/cobra"
+)
+
+// cmdTurnRun runs the run turn command
+var cmdTurnRun = &cobra.Command{
+	Use:   "run",
+	Short: "run a turn",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options, err := turnOptions()
+		if err != nil {
+			return err
+		}
+		options = append(options, olympia.WithImmediateAfter(argsTurnRun.immed_after))
+		options = append(options, olympia.WithInhibitAdd(argsTurnRun.inhibit_add_flag))
+		options = append(options, olympia.WithMailNow(argsTurnRun.mail_now))
+		options = append(options, olympia.WithUnspoolFirst(argsTurnRun.unspool_first_flag))
+
+		return olympia.RunTurn(options...)
+	},
+}
+
+var argsTurnRun struct {
+	immed_after        bool
+	inhibit_add_flag   bool
+	mail_now           bool
+	unspool_first_flag bool
+}
+
+func init() {
+	cmdTurn.AddCommand(cmdTurnRun)
+	cmdTurnRun.Flags().BoolVar(&argsTurnRun.unspool_first_flag, "eat-first", false, "eat orders from the spool before running the turn")
+	cmdTurnRun.Flags().BoolVar(&argsTurnRun.immed_after, "immediate", false, "run immediate mode after the turn")
+	cmdTurnRun.Flags().BoolVar(&argsTurnRun.inhibit_add_flag, "inhibit-add", false, "don't add new players during the turn")
+	cmdTurnRun.Flags().BoolVar(&argsTurnRun.mail_now, "mail", false, "mail reports")
+}
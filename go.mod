@@ -2,9 +2,13 @@ module github.com/mdhender/golympia
 
 go 1.19
 
-require github.com/spf13/cobra v1.6.1
+require (
+	github.com/spf13/cobra v1.6.1
+	golang.org/x/term v0.6.0
+)
 
 require (
 	github.com/inconshreveable/mousetrap v1.0.1 // indirect
 	github.com/spf13/pflag v1.0.5 // indirect
+	golang.org/x/sys v0.6.0 // indirect
 )
@@ -22,14 +22,12 @@ package main
 import (
 	"github.com/mdhender/golympia/cli"
 	"log"
-	"math/rand"
 	"os"
 	"time"
 )
 
 func main() {
 	started := time.Now()
-	rand.Seed(started.UnixNano())
 
 	rv := 0
 	if err := cli.Execute(); err != nil {
@@ -0,0 +1,251 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package battle is the structured record of the battles fought in a turn.
+// The engine writes it alongside the prose combat report so that replay
+// viewers and balance tools don't have to scrape the report text.
+package battle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Sides of a battle, and its results.
+const (
+	Attacker = "attacker"
+	Defender = "defender"
+	Tie      = "tie"
+)
+
+// Phases of a combat round. The defender's initial missile volley is
+// the missile phase of round 0.
+const (
+	Special = "special"
+	Missile = "missile"
+	Melee   = "melee"
+)
+
+// Event types.
+const (
+	Attack     = "attack"     // By made Count attacks on Target, scoring Hits
+	Spell      = "spell"      // By cast Skill, striking Count
+	Breath     = "breath"     // By's beasts (Item) breathed fire, striking Count
+	Potion     = "potion"     // By threw a potion (Item), striking Count
+	Dash       = "dash"       // By, a hero, dashed out and attacked
+	Raise      = "raise"      // By raised Count of Target's dead
+	Join       = "join"       // Target joined the battle as an ally
+	Casualties = "casualties" // Target lost Count men
+	Wound      = "wound"      // Target, a noble, lost Count health
+	Damage     = "damage"     // Target, a structure, took Count damage
+	Flee       = "flee"       // Count of Target's men broke and left
+)
+
+// Battle is one battle.
+type Battle struct {
+	Number    int         `json:"number"` // order the battle was fought in the turn, from 1
+	Turn      int         `json:"turn"`
+	Day       int         `json:"day"`
+	Where     string      `json:"where"`
+	WhereName string      `json:"where-name,omitempty"`
+	Terrain   string      `json:"terrain,omitempty"`
+	Rain      bool        `json:"rain,omitempty"`
+	Wind      bool        `json:"wind,omitempty"`
+	Sea       bool        `json:"sea,omitempty"`
+	Attacker  *Side       `json:"attacker"`
+	Defender  *Side       `json:"defender"`
+	Rounds    []*Round    `json:"rounds"`
+	Result    string      `json:"result"` // attacker, defender, or tie
+	Prisoners []*Prisoner `json:"prisoners,omitempty"`
+	Booty     []*Booty    `json:"booty,omitempty"`
+}
+
+// Side is the fighters on one side of the battle.
+// The first noble is the leader; a structure, if any, is first.
+type Side struct {
+	Leader   string     `json:"leader"`
+	Fighters []*Fighter `json:"fighters"`
+}
+
+// Fighter is a noble, a group of men or beasts of one kind in a noble's
+// stack, or the structure the side fights from.
+type Fighter struct {
+	Unit     string `json:"unit"`
+	Name     string `json:"name,omitempty"`
+	Faction  string `json:"faction,omitempty"`
+	Kind     string `json:"kind"` // noble, structure, or item
+	Item     string `json:"item,omitempty"`
+	ItemName string `json:"item-name,omitempty"`
+	Count    int    `json:"count"` // men, 1 for a noble, or the structure's rating
+	Attack   int    `json:"attack,omitempty"`
+	Defense  int    `json:"defense,omitempty"`
+	Missile  int    `json:"missile,omitempty"`
+	Behind   int    `json:"behind,omitempty"`
+	Inside   bool   `json:"inside,omitempty"`
+	Ally     bool   `json:"ally,omitempty"`
+	Joined   int    `json:"joined,omitempty"` // round an ally joined the battle
+	Lost     int    `json:"lost"`             // men killed or structure damage
+	Health   int    `json:"health,omitempty"` // nobles only, after the battle
+	Fate     string `json:"fate,omitempty"`   // nobles only: unhurt, wounded, captured, or killed
+	Hidden   bool   `json:"hidden,omitempty"` // faction, strength and health withheld from the reader
+}
+
+// Ref is a fighter, by side ("attacker" or "defender") and position
+// in the side's list of fighters.
+type Ref struct {
+	Side    string `json:"side"`
+	Fighter int    `json:"fighter"`
+}
+
+// Round is the events of one combat round.
+type Round struct {
+	Round  int      `json:"round"`
+	Events []*Event `json:"events"`
+}
+
+// Event is something that happened during a round.
+// The attacks one fighter makes on another in a phase are collected
+// into a single event.
+type Event struct {
+	Phase  string `json:"phase,omitempty"`
+	Type   string `json:"type"`
+	By     *Ref   `json:"by,omitempty"`
+	Target *Ref   `json:"target,omitempty"`
+	Skill  string `json:"skill,omitempty"`
+	Item   string `json:"item,omitempty"`
+	Count  int    `json:"count,omitempty"`
+	Hits   int    `json:"hits,omitempty"`
+}
+
+// Prisoner is a noble taken prisoner by the winner.
+type Prisoner struct {
+	Unit   string `json:"unit"`
+	Captor string `json:"captor"`
+}
+
+// Booty is items taken from the losing side.
+type Booty struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Item     string `json:"item"`
+	ItemName string `json:"item-name,omitempty"`
+	Qty      int    `json:"qty"`
+}
+
+// Involves returns true if any of the faction's units fought in the battle.
+func (b *Battle) Involves(faction string) bool {
+	for _, s := range []*Side{b.Attacker, b.Defender} {
+		for _, f := range s.Fighters {
+			if f.Faction == faction {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Fighter returns the fighter a reference points at, or nil.
+func (b *Battle) Fighter(r *Ref) *Fighter {
+	var s *Side
+	if r == nil {
+		return nil
+	} else if r.Side == Attacker {
+		s = b.Attacker
+	} else {
+		s = b.Defender
+	}
+	if r.Fighter < 0 || r.Fighter >= len(s.Fighters) {
+		return nil
+	}
+	return s.Fighters[r.Fighter]
+}
+
+// ForFaction returns the battles the faction's units fought in, as the
+// faction saw them. The full record is for the GM; the copies returned
+// here don't say which faction the other fighters belong to, don't give
+// their strength or health, and leave out the individual attacks.
+func ForFaction(battles []*Battle, faction string) []*Battle {
+	var l []*Battle
+	for _, b := range battles {
+		if b.Involves(faction) {
+			l = append(l, b.seenBy(faction))
+		}
+	}
+	return l
+}
+
+// seenBy returns a copy of the battle with the details that the faction
+// doesn't know removed.
+func (b *Battle) seenBy(faction string) *Battle {
+	c := *b
+	c.Attacker, c.Defender = b.Attacker.seenBy(faction), b.Defender.seenBy(faction)
+	c.Rounds = nil
+	for _, r := range b.Rounds {
+		cr := &Round{Round: r.Round, Events: []*Event{}}
+		for _, e := range r.Events {
+			if e.Type != Attack {
+				cr.Events = append(cr.Events, e)
+			}
+		}
+		c.Rounds = append(c.Rounds, cr)
+	}
+	return &c
+}
+
+func (s *Side) seenBy(faction string) *Side {
+	c := &Side{Leader: s.Leader}
+	for _, f := range s.Fighters {
+		cf := *f
+		if f.Faction != faction {
+			cf.Faction, cf.Hidden = "", true
+			cf.Attack, cf.Defense, cf.Missile, cf.Behind, cf.Health = 0, 0, 0, 0, 0
+		}
+		c.Fighters = append(c.Fighters, &cf)
+	}
+	return c
+}
+
+// Read loads battles from a file. A missing file holds no battles.
+func Read(name string) ([]*Battle, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var battles []*Battle
+	if err := json.Unmarshal(data, &battles); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return battles, nil
+}
+
+// Write saves battles to a file.
+func Write(name string, battles []*Battle) error {
+	if battles == nil {
+		battles = []*Battle{}
+	}
+	data, err := json.MarshalIndent(battles, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0666)
+}
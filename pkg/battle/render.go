@@ -0,0 +1,103 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package battle
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+)
+
+// HTML writes the battles as a fragment for inclusion in a report page:
+// a summary of each battle followed by the full record as JSON in a
+// script element with the id "battles".
+func HTML(w io.Writer, battles []*Battle) error {
+	if len(battles) == 0 {
+		return nil
+	}
+	bw := bufio.NewWriter(w)
+	esc := html.EscapeString
+
+	fmt.Fprintf(bw, "<section class=\"battles\">\n<h2>Battles</h2>\n")
+	for _, b := range battles {
+		fmt.Fprintf(bw, "<article class=\"battle\" id=\"battle-%d\">\n", b.Number)
+		fmt.Fprintf(bw, "<h3>Battle in %s [%s], day %d</h3>\n", esc(b.WhereName), esc(b.Where), b.Day)
+		fmt.Fprintf(bw, "<p>%d rounds, %s.</p>\n", len(b.Rounds), esc(b.outcome()))
+		for _, side := range []string{Attacker, Defender} {
+			s := b.Attacker
+			if side == Defender {
+				s = b.Defender
+			}
+			fmt.Fprintf(bw, "<table class=%q>\n<caption>%s</caption>\n", side, esc(side))
+			fmt.Fprintf(bw, "<tr><th>Unit</th><th>Kind</th><th>Count</th><th>Attack</th><th>Defense</th><th>Missile</th><th>Lost</th><th>Fate</th></tr>\n")
+			for _, f := range s.Fighters {
+				kind := f.Kind
+				if f.ItemName != "" {
+					kind = f.ItemName
+				}
+				attack, defense, missile := fmt.Sprint(f.Attack), fmt.Sprint(f.Defense), fmt.Sprint(f.Missile)
+				if f.Hidden {
+					attack, defense, missile = "?", "?", "?"
+				}
+				fmt.Fprintf(bw, "<tr><td>%s [%s]</td><td>%s</td><td>%d</td><td>%s</td><td>%s</td><td>%s</td><td>%d</td><td>%s</td></tr>\n",
+					esc(f.Name), esc(f.Unit), esc(kind), f.Count, attack, defense, missile, f.Lost, esc(f.Fate))
+			}
+			fmt.Fprintf(bw, "</table>\n")
+		}
+		if len(b.Prisoners) != 0 {
+			fmt.Fprintf(bw, "<p>Prisoners:")
+			for _, p := range b.Prisoners {
+				fmt.Fprintf(bw, " [%s] taken by [%s];", esc(p.Unit), esc(p.Captor))
+			}
+			fmt.Fprintf(bw, "</p>\n")
+		}
+		if len(b.Booty) != 0 {
+			fmt.Fprintf(bw, "<p>Booty:")
+			for _, t := range b.Booty {
+				fmt.Fprintf(bw, " [%s] took %d %s from [%s];", esc(t.To), t.Qty, esc(t.ItemName), esc(t.From))
+			}
+			fmt.Fprintf(bw, "</p>\n")
+		}
+		fmt.Fprintf(bw, "</article>\n")
+	}
+
+	// json.Marshal escapes <, > and &, so the record can't close the script element.
+	data, err := json.Marshal(battles)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(bw, "<script type=\"application/json\" id=\"battles\">%s</script>\n", data)
+	fmt.Fprintf(bw, "</section>\n")
+
+	return bw.Flush()
+}
+
+// outcome describes the result of the battle.
+func (b *Battle) outcome() string {
+	switch b.Result {
+	case Attacker:
+		return "the attacker won"
+	case Defender:
+		return "the defender won"
+	}
+	return "no one won"
+}
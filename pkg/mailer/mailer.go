@@ -0,0 +1,252 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package mailer builds MIME messages and delivers them over SMTP
+// or into a directory of .eml files.
+package mailer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Message is a text message with optional attachments.
+type Message struct {
+	From        string // "name <address>" or "address (name)"
+	ReplyTo     string
+	To          []string
+	Cc          []string
+	Bcc         []string // envelope only, never written to the headers
+	Subject     string
+	Headers     map[string]string // extra headers, e.g. X-Loop
+	Body        []byte
+	ContentType string // of the body; defaults to text/plain
+	Attachments []Attachment
+	Date        time.Time // defaults to now
+}
+
+// Attachment is a file attached to a message.
+type Attachment struct {
+	Name        string
+	ContentType string // defaults to text/plain
+	Data        []byte
+}
+
+// Sender returns the envelope sender.
+func (m *Message) Sender() (string, error) {
+	a, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return "", fmt.Errorf("mailer: from: %w", err)
+	}
+	return a.Address, nil
+}
+
+// Recipients returns the envelope recipients (To, Cc, and Bcc).
+func (m *Message) Recipients() ([]string, error) {
+	var rcpts []string
+	for _, list := range [][]string{m.To, m.Cc, m.Bcc} {
+		for _, to := range list {
+			a, err := mail.ParseAddress(to)
+			if err != nil {
+				return nil, fmt.Errorf("mailer: recipient %q: %w", to, err)
+			}
+			rcpts = append(rcpts, a.Address)
+		}
+	}
+	if len(rcpts) == 0 {
+		return nil, fmt.Errorf("mailer: no recipients")
+	}
+	return rcpts, nil
+}
+
+// Bytes returns the message in RFC 5322 format.
+// Messages without attachments are sent as a single part.
+func (m *Message) Bytes() ([]byte, error) {
+	from, err := mail.ParseAddress(m.From)
+	if err != nil {
+		return nil, fmt.Errorf("mailer: from: %w", err)
+	}
+
+	date := m.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	b := &bytes.Buffer{}
+	header := func(key, value string) {
+		_, _ = fmt.Fprintf(b, "%s: %s\r\n", key, value)
+	}
+	header("From", from.String())
+	if m.ReplyTo != "" {
+		a, err := mail.ParseAddress(m.ReplyTo)
+		if err != nil {
+			return nil, fmt.Errorf("mailer: reply-to: %w", err)
+		}
+		header("Reply-To", a.String())
+	}
+	for _, h := range []struct {
+		key  string
+		list []string
+	}{{"To", m.To}, {"Cc", m.Cc}} {
+		if len(h.list) == 0 {
+			continue
+		}
+		var addrs []string
+		for _, to := range h.list {
+			a, err := mail.ParseAddress(to)
+			if err != nil {
+				return nil, fmt.Errorf("mailer: %s: %w", strings.ToLower(h.key), err)
+			}
+			addrs = append(addrs, a.String())
+		}
+		header(h.key, strings.Join(addrs, ", "))
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	for k, v := range m.Headers {
+		header(textproto.CanonicalMIMEHeaderKey(k), v)
+	}
+	header("MIME-Version", "1.0")
+
+	contentType := m.ContentType
+	if contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+	}
+	if len(m.Attachments) == 0 {
+		header("Content-Type", contentType)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		b.WriteString("\r\n")
+		if err := writeQuotedPrintable(b, m.Body); err != nil {
+			return nil, fmt.Errorf("mailer: body: %w", err)
+		}
+		return b.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(b)
+	header("Content-Type", fmt.Sprintf("multipart/mixed; boundary=%q", mw.Boundary()))
+	b.WriteString("\r\n")
+
+	pw, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mailer: body: %w", err)
+	} else if err = writeQuotedPrintable(pw, m.Body); err != nil {
+		return nil, fmt.Errorf("mailer: body: %w", err)
+	}
+
+	for _, a := range m.Attachments {
+		contentType := a.ContentType
+		if contentType == "" {
+			contentType = "text/plain; charset=utf-8"
+		}
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("mailer: attachment %q: %w", a.Name, err)
+		} else if err = writeBase64(pw, a.Data); err != nil {
+			return nil, fmt.Errorf("mailer: attachment %q: %w", a.Name, err)
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("mailer: %w", err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// Split breaks a message into numbered parts so that no part's body
+// has more than maxLines lines or maxBytes bytes. A limit of zero means
+// no limit. Bodies are only split on line boundaries, so a single line
+// longer than maxBytes gets a part to itself. Attachments are sent with
+// the last part.
+func Split(m *Message, maxLines, maxBytes int) []*Message {
+	if maxLines <= 0 && maxBytes <= 0 {
+		return []*Message{m}
+	}
+
+	var bodies [][]byte
+	var cur []byte
+	lines := 0
+	for _, line := range bytes.SplitAfter(m.Body, []byte{'\n'}) {
+		if len(line) == 0 {
+			continue
+		}
+		full := (maxLines > 0 && lines >= maxLines) || (maxBytes > 0 && len(cur)+len(line) > maxBytes)
+		if full && len(cur) != 0 {
+			bodies = append(bodies, cur)
+			cur, lines = nil, 0
+		}
+		cur = append(cur, line...)
+		lines++
+	}
+	if len(cur) != 0 || len(bodies) == 0 {
+		bodies = append(bodies, cur)
+	}
+
+	if len(bodies) == 1 {
+		return []*Message{m}
+	}
+
+	var parts []*Message
+	for i, body := range bodies {
+		part := *m
+		part.Subject = fmt.Sprintf("%s (part %d of %d)", m.Subject, i+1, len(bodies))
+		part.Body = body
+		if i != len(bodies)-1 {
+			part.Attachments = nil
+		}
+		parts = append(parts, &part)
+	}
+	return parts
+}
+
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, body []byte) error {
+	qw := quotedprintable.NewWriter(w)
+	if _, err := qw.Write(body); err != nil {
+		return err
+	}
+	return qw.Close()
+}
+
+// writeBase64 writes data as base64 in lines of 76 characters.
+func writeBase64(w interface{ Write([]byte) (int, error) }, data []byte) error {
+	enc := base64.StdEncoding.EncodeToString(data)
+	for len(enc) > 76 {
+		if _, err := fmt.Fprintf(w, "%s\r\n", enc[:76]); err != nil {
+			return err
+		}
+		enc = enc[76:]
+	}
+	_, err := fmt.Fprintf(w, "%s\r\n", enc)
+	return err
+}
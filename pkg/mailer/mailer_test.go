@@ -0,0 +1,131 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package mailer
+
+import (
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	m := &Message{Subject: "report", Body: []byte("aaaa\nbbbb\ncccccccccccc\ndd\n")}
+
+	if got := Split(m, 0, 0); len(got) != 1 || got[0] != m {
+		t.Errorf("no limits: got %d parts, want the message itself", len(got))
+	}
+	if got := Split(m, 10, 1000); len(got) != 1 || got[0] != m {
+		t.Errorf("under the limits: got %d parts, want the message itself", len(got))
+	}
+
+	// the long line is bigger than the limit, so it gets a part to itself
+	got := Split(m, 0, 10)
+	want := []string{"aaaa\nbbbb\n", "cccccccccccc\n", "dd\n"}
+	if len(got) != len(want) {
+		t.Fatalf("byte limit: got %d parts, want %d", len(got), len(want))
+	}
+	for i, part := range got {
+		if string(part.Body) != want[i] {
+			t.Errorf("byte limit: part %d: got %q, want %q", i+1, part.Body, want[i])
+		}
+	}
+	if got[1].Subject != "report (part 2 of 3)" {
+		t.Errorf("subject: got %q", got[1].Subject)
+	}
+}
+
+func TestBytesAddresses(t *testing.T) {
+	to := []string{
+		(&mail.Address{Name: "Smith, Jane", Address: "jane@example.com"}).String(),
+		(&mail.Address{Name: "Zoë", Address: "zoe@example.com"}).String(),
+		"bob@example.com",
+	}
+	data, err := (&Message{From: "gm@example.com (Olympia Moderator)", To: to, Subject: "hi", Body: []byte("hello\n")}).Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := m.Header.AddressList("To")
+	if err != nil {
+		t.Fatalf("to: %v", err)
+	}
+	want := []mail.Address{
+		{Name: "Smith, Jane", Address: "jane@example.com"},
+		{Name: "Zoë", Address: "zoe@example.com"},
+		{Address: "bob@example.com"},
+	}
+	if len(list) != len(want) {
+		t.Fatalf("to: got %v, want %v", list, want)
+	}
+	for i := range want {
+		if *list[i] != want[i] {
+			t.Errorf("to %d: got %v, want %v", i, *list[i], want[i])
+		}
+	}
+	if from, err := m.Header.AddressList("From"); err != nil || from[0].Address != "gm@example.com" {
+		t.Errorf("from: got %v, %v", from, err)
+	}
+}
+
+func TestBytesContentType(t *testing.T) {
+	for _, tc := range []struct {
+		contentType string
+		attach      bool
+		want        string
+	}{
+		{"", false, "text/plain"},
+		{"text/html; charset=utf-8", false, "text/html"},
+		{"text/html; charset=utf-8", true, "text/html"},
+	} {
+		m := &Message{From: "gm@example.com", To: []string{"bob@example.com"}, Subject: "report", Body: []byte("<p>hi</p>\n"), ContentType: tc.contentType}
+		if tc.attach {
+			m.Attachments = []Attachment{{Name: "times.txt", Data: []byte("news\n")}}
+		}
+		data, err := m.Bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.attach {
+			part, err := multipart.NewReader(msg.Body, params["boundary"]).NextPart()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mediaType, _, err = mime.ParseMediaType(part.Header.Get("Content-Type")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if mediaType != tc.want {
+			t.Errorf("%q, attachments %v: got %q, want %q", tc.contentType, tc.attach, mediaType, tc.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package mailer
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Transport delivers messages.
+type Transport interface {
+	Send(m *Message) error
+}
+
+// SMTP delivers messages to a mail server.
+type SMTP struct {
+	Host     string
+	Port     int // defaults to 25
+	Username string
+	Password string
+	// StartTLS requires the server to support STARTTLS.
+	// Credentials are never sent over an unencrypted connection
+	// unless the server is on the loopback interface.
+	StartTLS  bool
+	TLSConfig *tls.Config // optional, for servers with private certificates
+	Timeout   time.Duration
+}
+
+// Send implements the Transport interface.
+func (t *SMTP) Send(m *Message) error {
+	from, err := m.Sender()
+	if err != nil {
+		return err
+	}
+	rcpts, err := m.Recipients()
+	if err != nil {
+		return err
+	}
+	msg, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+
+	port := t.Port
+	if port == 0 {
+		port = 25
+	}
+	timeout := t.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	addr := net.JoinHostPort(t.Host, strconv.Itoa(port))
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return fmt.Errorf("mailer: smtp: %w", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(timeout))
+
+	c, err := smtp.NewClient(conn, t.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("mailer: smtp: %w", err)
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		cfg := t.TLSConfig
+		if cfg == nil {
+			cfg = &tls.Config{ServerName: t.Host}
+		}
+		if err := c.StartTLS(cfg); err != nil {
+			return fmt.Errorf("mailer: smtp: starttls: %w", err)
+		}
+	} else if t.StartTLS {
+		return fmt.Errorf("mailer: smtp: %s does not support STARTTLS", addr)
+	}
+
+	if t.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("mailer: smtp: %s does not support AUTH", addr)
+		}
+		if err := c.Auth(smtp.PlainAuth("", t.Username, t.Password, t.Host)); err != nil {
+			return fmt.Errorf("mailer: smtp: auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("mailer: smtp: mail from %s: %w", from, err)
+	}
+	for _, rcpt := range rcpts {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("mailer: smtp: rcpt to %s: %w", rcpt, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("mailer: smtp: data: %w", err)
+	} else if _, err = w.Write(msg); err != nil {
+		return fmt.Errorf("mailer: smtp: data: %w", err)
+	} else if err = w.Close(); err != nil {
+		return fmt.Errorf("mailer: smtp: data: %w", err)
+	}
+
+	return c.Quit()
+}
+
+// Dir is a dry-run transport that writes each message to a .eml file.
+type Dir struct {
+	Path string
+
+	sync.Mutex
+	seq int
+}
+
+// Send implements the Transport interface.
+func (t *Dir) Send(m *Message) error {
+	rcpts, err := m.Recipients()
+	if err != nil {
+		return err
+	}
+	msg, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(t.Path, 0755); err != nil {
+		return fmt.Errorf("mailer: dir: %w", err)
+	}
+
+	t.Lock()
+	t.seq++
+	name := fmt.Sprintf("%s-%04d-%s.eml", time.Now().UTC().Format("20060102T150405"), t.seq, safeName(rcpts[0]))
+	t.Unlock()
+
+	if err := os.WriteFile(filepath.Join(t.Path, name), msg, 0644); err != nil {
+		return fmt.Errorf("mailer: dir: %w", err)
+	}
+	return nil
+}
+
+// safeName replaces anything that might cause trouble in a file name.
+func safeName(s string) string {
+	b := []byte(s)
+	for i, ch := range b {
+		if !(('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9') || ch == '.' || ch == '-' || ch == '@') {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,453 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpServer is an in-process stand-in for a mail server.
+// It speaks just enough SMTP for the SMTP transport: EHLO, STARTTLS,
+// AUTH PLAIN, MAIL, RCPT, DATA, and QUIT.
+type smtpServer struct {
+	ln       net.Listener
+	tls      *tls.Config // nil means STARTTLS isn't offered
+	username string
+	password string
+
+	sync.Mutex
+	received []*received
+}
+
+// received is one message accepted by the stand-in.
+type received struct {
+	tls  bool // STARTTLS was done before the message was sent
+	auth string
+	from string
+	rcpt []string
+	data []byte
+}
+
+func newSMTPServer(t *testing.T, cfg *tls.Config, username, password string) *smtpServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpServer{ln: ln, tls: cfg, username: username, password: password}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() {
+		_ = ln.Close()
+	})
+	return s
+}
+
+func (s *smtpServer) port() int {
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpServer) messages() []*received {
+	s.Lock()
+	defer s.Unlock()
+	return append([]*received(nil), s.received...)
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+	reply := func(lines ...string) {
+		for _, line := range lines {
+			_, _ = w.WriteString(line + "\r\n")
+		}
+		_ = w.Flush()
+	}
+
+	var isTLS bool
+	var auth string
+	msg := &received{}
+	reply("220 localhost ESMTP stand-in")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			ext := []string{"250-localhost"}
+			if s.tls != nil && !isTLS {
+				ext = append(ext, "250-STARTTLS")
+			}
+			if s.username != "" && (isTLS || s.tls == nil) {
+				ext = append(ext, "250-AUTH PLAIN")
+			}
+			ext = append(ext, "250 8BITMIME")
+			reply(ext...)
+		case "STARTTLS":
+			if s.tls == nil || isTLS {
+				reply("502 not supported")
+				continue
+			}
+			reply("220 go ahead")
+			tc := tls.Server(conn, s.tls)
+			if err := tc.Handshake(); err != nil {
+				return
+			}
+			conn, isTLS = tc, true
+			r, w = bufio.NewReader(conn), bufio.NewWriter(conn)
+		case "AUTH":
+			mech, resp, _ := strings.Cut(arg, " ")
+			data, err := base64.StdEncoding.DecodeString(resp)
+			if !strings.EqualFold(mech, "PLAIN") || err != nil {
+				reply("504 unrecognized authentication type")
+				continue
+			}
+			// PLAIN is authzid NUL authcid NUL passwd
+			fields := strings.Split(string(data), "\x00")
+			if len(fields) != 3 || fields[1] != s.username || fields[2] != s.password {
+				reply("535 authentication failed")
+				continue
+			}
+			auth = fields[1]
+			reply("235 ok")
+		case "MAIL":
+			msg = &received{tls: isTLS, auth: auth, from: angleAddr(arg)}
+			reply("250 ok")
+		case "RCPT":
+			msg.rcpt = append(msg.rcpt, angleAddr(arg))
+			reply("250 ok")
+		case "DATA":
+			reply("354 end with .")
+			var data bytes.Buffer
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				} else if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			msg.data = data.Bytes()
+			s.Lock()
+			s.received = append(s.received, msg)
+			s.Unlock()
+			reply("250 queued")
+		case "RSET", "NOOP":
+			reply("250 ok")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("500 unrecognized command")
+		}
+	}
+}
+
+// angleAddr returns the address from "FROM:<address>" or "TO:<address>".
+func angleAddr(arg string) string {
+	if i := strings.IndexByte(arg, '<'); i != -1 {
+		if j := strings.IndexByte(arg[i:], '>'); j != -1 {
+			return arg[i+1 : i+j]
+		}
+	}
+	return arg
+}
+
+// testTLS returns a server config with a self-signed certificate for
+// 127.0.0.1 and a client config that trusts it.
+func testTLS(t *testing.T) (server, client *tls.Config) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	server = &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+	client = &tls.Config{RootCAs: pool, ServerName: "127.0.0.1"}
+	return server, client
+}
+
+// crlf undoes the line endings the quoted-printable writer puts on the wire.
+var crlf = strings.NewReplacer("\r\n", "\n")
+
+// parts returns the text body and the attachments of a message read back from the wire.
+func parts(t *testing.T, data []byte) (hdr mail.Header, body string, attachments map[string][]byte) {
+	t.Helper()
+	m, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	attachments = make(map[string][]byte)
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		b, err := io.ReadAll(m.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m.Header, crlf.Replace(decodeQP(t, b)), attachments
+	}
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		// NextPart decodes quoted-printable parts itself
+		b, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.FileName() == "" {
+			body = crlf.Replace(string(b))
+			continue
+		}
+		dec, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(string(b), "\r\n", ""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		attachments[p.FileName()] = dec
+	}
+	return m.Header, body, attachments
+}
+
+func decodeQP(t *testing.T, b []byte) string {
+	t.Helper()
+	out, err := io.ReadAll(quotedprintable.NewReader(bytes.NewReader(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestSMTPStartTLSAuth(t *testing.T) {
+	serverTLS, clientTLS := testTLS(t)
+	srv := newSMTPServer(t, serverTLS, "gm", "secret")
+
+	times := []byte("The Olympia Times\n\nNothing happened this month.\n")
+	msg := &Message{
+		From:    "Olympia Moderator <gm@example.com>",
+		To:      []string{(&mail.Address{Name: "Smith, Jane", Address: "jane@example.com"}).String()},
+		Bcc:     []string{"moderator@example.com"},
+		Subject: "Olympia:TAG game 1 turn 2 report [text]",
+		Body:    []byte("Turn 2 report.\n"),
+		Attachments: []Attachment{
+			{Name: "times-1-2.txt", Data: times},
+		},
+	}
+	tr := &SMTP{
+		Host:      "127.0.0.1",
+		Port:      srv.port(),
+		Username:  "gm",
+		Password:  "secret",
+		StartTLS:  true,
+		TLSConfig: clientTLS,
+		Timeout:   5 * time.Second,
+	}
+	if err := tr.Send(msg); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	got := srv.messages()
+	if len(got) != 1 {
+		t.Fatalf("server received %d messages, want 1", len(got))
+	}
+	m := got[0]
+	if !m.tls {
+		t.Errorf("message was sent without STARTTLS")
+	}
+	if m.auth != "gm" {
+		t.Errorf("auth: got %q, want %q", m.auth, "gm")
+	}
+	if m.from != "gm@example.com" {
+		t.Errorf("mail from: got %q, want %q", m.from, "gm@example.com")
+	}
+	if want := []string{"jane@example.com", "moderator@example.com"}; strings.Join(m.rcpt, ",") != strings.Join(want, ",") {
+		t.Errorf("rcpt to: got %v, want %v", m.rcpt, want)
+	}
+
+	hdr, body, attachments := parts(t, m.data)
+	to, err := hdr.AddressList("To")
+	if err != nil {
+		t.Fatalf("to: %v", err)
+	} else if len(to) != 1 || to[0].Name != "Smith, Jane" || to[0].Address != "jane@example.com" {
+		t.Errorf("to: got %v", to)
+	}
+	if hdr.Get("Bcc") != "" {
+		t.Errorf("bcc header was written: %q", hdr.Get("Bcc"))
+	}
+	if body != "Turn 2 report.\n" {
+		t.Errorf("body: got %q", body)
+	}
+	if !bytes.Equal(attachments["times-1-2.txt"], times) {
+		t.Errorf("times attachment: got %q, want %q", attachments["times-1-2.txt"], times)
+	}
+}
+
+func TestSMTPRequiresStartTLS(t *testing.T) {
+	srv := newSMTPServer(t, nil, "gm", "secret")
+	tr := &SMTP{Host: "127.0.0.1", Port: srv.port(), Username: "gm", Password: "secret", StartTLS: true, Timeout: 5 * time.Second}
+	err := tr.Send(&Message{From: "gm@example.com", To: []string{"jane@example.com"}, Body: []byte("hi\n")})
+	if err == nil || !strings.Contains(err.Error(), "does not support STARTTLS") {
+		t.Fatalf("send: got %v, want a STARTTLS error", err)
+	}
+	if n := len(srv.messages()); n != 0 {
+		t.Errorf("server received %d messages, want 0", n)
+	}
+}
+
+func TestSMTPBadPassword(t *testing.T) {
+	serverTLS, clientTLS := testTLS(t)
+	srv := newSMTPServer(t, serverTLS, "gm", "secret")
+	tr := &SMTP{Host: "127.0.0.1", Port: srv.port(), Username: "gm", Password: "wrong", StartTLS: true, TLSConfig: clientTLS, Timeout: 5 * time.Second}
+	err := tr.Send(&Message{From: "gm@example.com", To: []string{"jane@example.com"}, Body: []byte("hi\n")})
+	if err == nil || !strings.Contains(err.Error(), "auth") {
+		t.Fatalf("send: got %v, want an auth error", err)
+	}
+	if n := len(srv.messages()); n != 0 {
+		t.Errorf("server received %d messages, want 0", n)
+	}
+}
+
+func TestSMTPSplitReport(t *testing.T) {
+	serverTLS, clientTLS := testTLS(t)
+	srv := newSMTPServer(t, serverTLS, "", "")
+
+	var report strings.Builder
+	for i := 1; i <= 25; i++ {
+		report.WriteString("line " + strconv.Itoa(i) + " of the report\n")
+	}
+	times := []byte("The Olympia Times\n")
+	msg := &Message{
+		From:        "gm@example.com",
+		To:          []string{"jane@example.com"},
+		Subject:     "turn 2",
+		Body:        []byte(report.String()),
+		Attachments: []Attachment{{Name: "times-1-2.txt", Data: times}},
+	}
+	tr := &SMTP{Host: "127.0.0.1", Port: srv.port(), StartTLS: true, TLSConfig: clientTLS, Timeout: 5 * time.Second}
+	for _, part := range Split(msg, 10, 0) {
+		if err := tr.Send(part); err != nil {
+			t.Fatalf("send %q: %v", part.Subject, err)
+		}
+	}
+
+	got := srv.messages()
+	if len(got) != 3 {
+		t.Fatalf("server received %d messages, want 3", len(got))
+	}
+	var joined string
+	for i, m := range got {
+		hdr, body, attachments := parts(t, m.data)
+		want := "turn 2 (part " + strconv.Itoa(i+1) + " of 3)"
+		if subject := hdr.Get("Subject"); subject != want {
+			t.Errorf("part %d: subject: got %q, want %q", i+1, subject, want)
+		}
+		if i < 2 && len(attachments) != 0 {
+			t.Errorf("part %d: got %d attachments, want the Times only on the last part", i+1, len(attachments))
+		} else if i == 2 && !bytes.Equal(attachments["times-1-2.txt"], times) {
+			t.Errorf("part %d: times attachment: got %q", i+1, attachments["times-1-2.txt"])
+		}
+		joined += body
+	}
+	if joined != report.String() {
+		t.Errorf("the parts don't add up to the report:\n%s", joined)
+	}
+}
+
+func TestDirTransport(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	tr := &Dir{Path: dir}
+	if err := tr.Send(&Message{From: "gm@example.com", To: []string{"Jane <jane@example.com>"}, Subject: "hi", Body: []byte("hello\n")}); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 1 || !strings.HasSuffix(entries[0].Name(), "-jane@example.com.eml") {
+		t.Fatalf("got %v, want one .eml file named for the recipient", entries)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, body, _ := parts(t, data); body != "hello\n" {
+		t.Errorf("body: got %q", body)
+	}
+}
@@ -170,12 +170,14 @@ func starting_noble_points(nation int) int {
 	total_np, total_nations := 0.0, 0.0
 	ratio := 0.0
 
-	if rp_nation(nation).player_limit != 0 {
-		return 12
+	base := game_rules.Start.NoblePoints
+
+	if !game_rules.Start.Balance || rp_nation(nation).player_limit != 0 {
+		return base
 	}
 
 	if len(loop_nation()) == 1 {
-		return 12
+		return base
 	}
 
 	total_nations = 0
@@ -192,23 +194,28 @@ func starting_noble_points(nation int) int {
 		ratio = 1.0
 	}
 
+	var bonus int
 	if ratio >= 2.0 {
-		return 20
+		bonus = 8
 	} else if ratio >= 1.75 {
-		return 18
+		bonus = 6
 	} else if ratio >= 1.50 {
-		return 16
+		bonus = 4
 	} else if ratio >= 1.25 {
-		return 14
+		bonus = 2
 	} else if ratio >= 0.75 {
-		return 12
+		bonus = 0
 	} else if ratio >= 0.50 {
-		return 10
+		bonus = -2
 	} else if ratio >= 0.25 {
-		return 8
+		bonus = -4
 	} else {
-		return 6
+		bonus = -6
+	}
+	if base+bonus < 0 {
+		return 0
 	}
+	return base + bonus
 }
 
 /*
@@ -220,12 +227,14 @@ func starting_noble_points(nation int) int {
 func starting_gold(nation int) int {
 	ratio, total_gold, total_nations := 0.0, 0.0, 0.0
 
-	if rp_nation(nation).player_limit != 0 {
-		return 5000
+	base := game_rules.Start.Gold
+
+	if !game_rules.Start.Balance || rp_nation(nation).player_limit != 0 {
+		return base
 	}
 
 	if len(loop_nation()) == 1 {
-		return 5000
+		return base
 	}
 
 	total_nations = 0
@@ -247,7 +256,7 @@ func starting_gold(nation int) int {
 	} else if ratio < 0.25 {
 		ratio = 0.25
 	}
-	return int(ratio * 5000)
+	return int(ratio * float64(base))
 }
 
 func add_new_player(pl int, faction, character, full_name, email string, nation, start_city int) int {
@@ -318,183 +327,6 @@ func add_new_player(pl int, faction, character, full_name, email string, nation,
 	return pl
 }
 
-func failed_join(email, reason string) {
-	if !save_flag {
-		return
-	}
-
-	//tmpfile := fmt.Sprintf("failedjoin%d", get_process_id());
-	//tmp, err := os.Create(tmpfile);
-	//if err != nil {
-	//	panic(err)
-	//}
-	//fprintf(tmp, "From: %s\n", from_host);
-	//fprintf(tmp, "Subject: Failed attempt to join Olympia: The Age of Gods, Game %d\n", game_number);
-	//fprintf(tmp, "To: %s, %s\n\n", email, rp_player(gm_player).email);
-	//fprintf(tmp, "%s", reason);
-	//fprintf(tmp, "\n\n -- The Game Master\n\n");
-	//fclose(tmp);
-	///*VLN cmd = sout("sendmail -t -odq < %s", tmpfile);*/
-	//cmd := sout("msmtp -t < %s", tmpfile);
-	//if (system(cmd)) {
-	//    log.Printf( "Failed to send 'failed join' mail?\n");
-	//} else {
-	//    unlink(tmpfile);
-	//}
-
-	panic("!implemented")
-}
-
-func make_new_players_sup(acct string, fp *os.File) bool {
-	n, sc := 0, 0
-	var faction, character, full_name, email, nat string
-
-	if faction = fetch_inp(fp); faction == "" {
-		log.Printf("%s: Unable to add %s <%s>n", acct, full_name, email) // todo: uninitialized values
-		log.Printf("    partial read of faction.")
-		return false
-	}
-
-	if character = fetch_inp(fp); character == "" {
-		log.Printf("%s: Unable to add %s <%s>n", acct, full_name, email) // todo: uninitialized values
-		log.Printf("    partial read of character.")
-		return false
-	}
-
-	if full_name = fetch_inp(fp); full_name == "" {
-		log.Printf("%s: Unable to add %s <%s>n", acct, full_name, email) // todo: uninitialized values
-		log.Printf("    partial read of full_name.")
-		return false
-	}
-
-	if email = fetch_inp(fp); email == "" {
-		log.Printf("%s: Unable to add %s <%s>n", acct, full_name, email)
-		log.Printf("    partial read of email.")
-		return false
-	}
-
-	if nat = fetch_inp(fp); nat == "" {
-		log.Printf("%s: Unable to add %s <%s>n", acct, full_name, email)
-		log.Printf("    partial read of nation.")
-		return false
-	}
-
-	/*
-	 *  Start_City can be null (blank)
-	 *
-	 */
-	start_city := fetch_inp(fp)
-
-	pl := scode([]byte(acct))
-	assert(pl > 0 && pl < MAX_BOXES)
-
-	/*
-	 * Maybe he's already in the game.
-	 *
-	 */
-	if bx[pl] != nil {
-		fail_buf := fmt.Sprintf("Olympia was unable to add you to this game because\nthere is already a faction assigned to your account.\n")
-		failed_join(email, fail_buf)
-		return true
-	}
-
-	/*
-	 *  Figure out the nation
-	 *
-	 */
-	n = find_nation(nat)
-	if n != 0 {
-		wout(gm_player, "Couldn't add player %s: bad nation.", acct)
-		fail_buf := fmt.Sprintf("Olympia was unable to add you to this game because\nwe could not decipher the nation name (%s) that\nyou provided.  Please try to join again using a valid nation.\n", nat)
-		failed_join(email, fail_buf)
-		return true
-	}
-	/*
-	 *  Wed Jul  2 11:32:01 1997 -- Scott Turner
-	 *
-	 *  Check player limits on the nation they've chosen.
-	 *
-	 */
-	if rp_nation(n).player_limit != 0 {
-		total := 0
-		for _, i := range loop_player() {
-			if nation(i) == n {
-				total++
-			}
-		}
-		if total >= rp_nation(n).player_limit {
-			fail_buf := fmt.Sprintf("Olympia was unable to add you to this game because\nthe %s nation has already reached its limit of %d players.\n  Please try again using a different nation.\n", rp_nation(n).name, rp_nation(n).player_limit)
-			failed_join(email, fail_buf)
-			return true
-		}
-	}
-	/*
-	 *  Check to make sure the city someone is trying to
-	 *  start in is controlled by the appropriate nation.
-	 *  If not, give him the default starting city.
-	 *
-	 */
-	if strings.HasPrefix(strings.ToLower(start_city), "rand") {
-		sc = RANDOM_START
-	} else {
-		sc = code_to_int([]byte(start_city))
-	}
-
-	alloc_box(pl, T_player, sub_pl_regular)
-
-	add_new_player(pl, faction, character, full_name, email, n, sc)
-	if start_city != "" {
-		my_free(start_city)
-	}
-	log.Printf("\tadded player %s\n", box_name(pl))
-
-	return true
-}
-
-func make_new_players() {
-	files, err := os.ReadDir(libdir)
-	if err != nil {
-		log.Printf("make_new_players: can't open %s: \n", options.accounting_dir)
-		return
-	}
-
-	for _, f := range files {
-		if strings.HasPrefix(f.Name(), ".") {
-			continue
-		}
-		acct := f.Name()
-
-		log.Printf("VLN account = %s\n", acct)
-		fnam := filepath.Join(options.accounting_dir, acct, fmt.Sprintf("Join-tag-%d", game_number))
-
-		fp, err := os.Open(fnam)
-		if err != nil {
-			continue
-		}
-
-		if !make_new_players_sup(acct, fp) {
-			// this should generate some notice of a failed add and alert the GM/user.
-			log.Printf("Failed to add new player %q\n", acct)
-		}
-		_ = fp.Close()
-	}
-}
-
-func rename_act_join_files() error {
-	for i := 0; i < len(new_players); i++ {
-		pl := new_players[i]
-		acct := fmt.Sprintf("%s", box_code_less(pl))
-
-		old_name := filepath.Join(options.accounting_dir, acct, fmt.Sprintf("Join-tag-%d", game_number))
-		new_name := filepath.Join(options.accounting_dir, acct, fmt.Sprintf("Join-tag-%d-", game_number))
-
-		if err := rename(old_name, new_name); err != nil {
-			return fmt.Errorf("rename(%s, %s): %w", old_name, new_name)
-		}
-	}
-	return nil
-}
-
 func new_player_banners() {
 	out_path = MASTER
 	out_alt_who = OUT_BANNER
@@ -597,7 +429,7 @@ func mail_initial_reports() {
 	for i = 0; i < len(new_players); i++ {
 		pl = new_players[i]
 
-		s = filepath.Join(libdir, "log", libdir, fmt.Sprintf("%d", pl))
+		s = filepath.Join(libdir, "log", fmt.Sprintf("%d", pl))
 		t = filepath.Join(libdir, "save", fmt.Sprintf("%d", sysclock.turn), fmt.Sprintf("%d", pl))
 
 		if err := rename(s, t); err != nil {
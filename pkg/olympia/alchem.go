@@ -113,8 +113,7 @@ func new_potion(who int) int {
 		panic("!reached")
 	}
 
-	p := p_item_magic(potion)
-	p.Creator = who
+	set_item_creator(potion, who)
 	p_item(potion).weight = 1
 
 	wout(who, "Produced one %q", box_name(potion))
@@ -554,6 +554,258 @@ func d_unobscure_art(c *command) int {
 	return TRUE
 }
 
+/*
+ *  The creator of an item and the province it was made in.
+ *
+ *  Show artifact creator and show artifact region reveal them; the
+ *  creator may cloak either one, and remove artifact cloaks lifts
+ *  both.  The creator may also curse an artifact so that any other
+ *  noble holding it loses loyalty each month.
+ *
+ */
+func set_item_creator(item, who int) {
+	p := p_item_magic(item)
+	p.Creator = who
+	p.RegionCreat = province(who)
+}
+
+/*
+ *  Common checks for the artifact cloak and curse spells.
+ *
+ */
+func check_art_target(c *command, creator_only bool) bool {
+	target := c.a
+
+	if kind(target) != T_item || item_unique(target) == FALSE ||
+		has_item(c.who, target) < 1 {
+		wout(c.who, "You must possess an artifact to cast this spell upon it.")
+		return false
+	}
+
+	if creator_only && item_creator(target) != c.who {
+		wout(c.who, "Only the creator of %s may cast this spell upon it.",
+			box_name(target))
+		return false
+	}
+
+	return true
+}
+
+func v_show_art_creat(c *command) int {
+	if !check_art_target(c, false) {
+		return FALSE
+	}
+
+	if !check_aura(c.who, skill_aura(c.use_skill)) {
+		return FALSE
+	}
+
+	return TRUE
+}
+
+func d_show_art_creat(c *command) int {
+	target := c.a
+
+	if !check_art_target(c, false) {
+		return FALSE
+	}
+
+	if !charge_aura(c.who, skill_aura(c.use_skill)) {
+		return FALSE
+	}
+	wout(c.who, "Used %s aura casting this spell.", nice_num(skill_aura(c.use_skill)))
+
+	creator := item_creator(target)
+	if item_creat_cloak(target) != FALSE || !valid_box(creator) {
+		wout(c.who, "You are unable to learn who created %s.",
+			box_name(target))
+		return TRUE
+	}
+
+	wout(c.who, "%s was created by %s.", box_name(target), box_name(creator))
+
+	return TRUE
+}
+
+func v_show_art_reg(c *command) int {
+	if !check_art_target(c, false) {
+		return FALSE
+	}
+
+	if !check_aura(c.who, skill_aura(c.use_skill)) {
+		return FALSE
+	}
+
+	return TRUE
+}
+
+func d_show_art_reg(c *command) int {
+	target := c.a
+
+	if !check_art_target(c, false) {
+		return FALSE
+	}
+
+	if !charge_aura(c.who, skill_aura(c.use_skill)) {
+		return FALSE
+	}
+	wout(c.who, "Used %s aura casting this spell.", nice_num(skill_aura(c.use_skill)))
+
+	where := item_creat_loc(target)
+	if item_reg_cloak(target) != FALSE || !valid_box(where) {
+		wout(c.who, "You are unable to learn where %s was created.",
+			box_name(target))
+		return TRUE
+	}
+
+	wout(c.who, "%s was created in %s.", box_name(target), box_name(where))
+
+	return TRUE
+}
+
+func v_cloak_creat(c *command) int {
+	if !check_art_target(c, true) {
+		return FALSE
+	}
+
+	if !check_aura(c.who, skill_aura(c.use_skill)) {
+		return FALSE
+	}
+
+	return TRUE
+}
+
+func d_cloak_creat(c *command) int {
+	target := c.a
+
+	if !check_art_target(c, true) {
+		return FALSE
+	}
+
+	if !charge_aura(c.who, skill_aura(c.use_skill)) {
+		return FALSE
+	}
+	wout(c.who, "Used %s aura casting this spell.", nice_num(skill_aura(c.use_skill)))
+
+	p_item_magic(target).CloakCreator = TRUE
+	wout(c.who, "The creator of %s is now cloaked.", box_name(target))
+
+	return TRUE
+}
+
+func v_cloak_reg(c *command) int {
+	if !check_art_target(c, true) {
+		return FALSE
+	}
+
+	if !check_aura(c.who, skill_aura(c.use_skill)) {
+		return FALSE
+	}
+
+	return TRUE
+}
+
+func d_cloak_reg(c *command) int {
+	target := c.a
+
+	if !check_art_target(c, true) {
+		return FALSE
+	}
+
+	if !charge_aura(c.who, skill_aura(c.use_skill)) {
+		return FALSE
+	}
+	wout(c.who, "Used %s aura casting this spell.", nice_num(skill_aura(c.use_skill)))
+
+	p_item_magic(target).CloakRegion = TRUE
+	wout(c.who, "The origin of %s is now cloaked.", box_name(target))
+
+	return TRUE
+}
+
+func v_rem_art_cloak(c *command) int {
+	if !check_art_target(c, false) {
+		return FALSE
+	}
+
+	if !check_aura(c.who, skill_aura(c.use_skill)) {
+		return FALSE
+	}
+
+	return TRUE
+}
+
+func d_rem_art_cloak(c *command) int {
+	target := c.a
+
+	if !check_art_target(c, false) {
+		return FALSE
+	}
+
+	if !charge_aura(c.who, skill_aura(c.use_skill)) {
+		return FALSE
+	}
+	wout(c.who, "Used %s aura casting this spell.", nice_num(skill_aura(c.use_skill)))
+
+	if item_creat_cloak(target) == FALSE && item_reg_cloak(target) == FALSE {
+		wout(c.who, "%s is not cloaked.", box_name(target))
+		return TRUE
+	}
+
+	p := p_item_magic(target)
+	p.CloakCreator = FALSE
+	p.CloakRegion = FALSE
+	wout(c.who, "Removed the cloaks from %s.", box_name(target))
+
+	return TRUE
+}
+
+/*
+ *  use 3143 <artifact> [loyalty]
+ *
+ *  Each point of loyalty lost per month costs the spell's aura.
+ *
+ */
+func v_curse_noncreat(c *command) int {
+	amount := c.b
+
+	if amount < 1 {
+		amount = 1
+		c.b = amount
+	}
+
+	if !check_art_target(c, true) {
+		return FALSE
+	}
+
+	if !check_aura(c.who, amount*skill_aura(c.use_skill)) {
+		return FALSE
+	}
+
+	return TRUE
+}
+
+func d_curse_noncreat(c *command) int {
+	target := c.a
+	amount := c.b
+	aura := amount * skill_aura(c.use_skill)
+
+	if !check_art_target(c, true) {
+		return FALSE
+	}
+
+	if !charge_aura(c.who, aura) {
+		return FALSE
+	}
+	wout(c.who, "Used %s aura casting this spell.", nice_num(aura))
+
+	p_item_magic(target).CurseLoyalty = amount
+	wout(c.who, "Any other noble holding %s will lose %d loyalty each month.",
+		box_name(target), amount)
+
+	return TRUE
+}
+
 /*
  *  Tue Oct 27 11:34:53 1998 -- Scott Turner
  *
@@ -738,6 +990,7 @@ func d_forge_aura(c *command) int {
 	p_item_artifact(newItem).Type = ART_AURACULUM
 	p_item_artifact(newItem).Param1 = c.who    /* creator */
 	p_item_artifact(newItem).Param2 = aura * 2 /* aura */
+	set_item_creator(newItem, c.who)
 
 	cm = p_magic(c.who)
 	cm.auraculum = newItem
@@ -823,6 +1076,7 @@ func d_forge_art_x(c *command) int {
 	newItem := create_unique_item(c.who, sub_magic_artifact)
 	p_item(newItem).weight = 10
 	p_item_artifact(newItem).Type = ART_COMBAT
+	set_item_creator(newItem, c.who)
 
 	switch c.use_skill {
 	case sk_forge_weapon:
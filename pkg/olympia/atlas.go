@@ -0,0 +1,180 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/worldmap"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// FactionAtlas loads the database and returns what the faction knows
+// about the world.
+func FactionAtlas(faction string, options ...Option) (a *worldmap.Atlas, err error) {
+	defer recover_turn("FactionAtlas", &err)
+	defer unlock_tag()
+
+	if err := start_turn(options...); err != nil {
+		return nil, fmt.Errorf("FactionAtlas: %w", err)
+	}
+
+	pl := code_to_int([]byte(faction))
+	if kind(pl) != T_player {
+		return nil, fmt.Errorf("FactionAtlas: %q is not a faction", faction)
+	}
+
+	return faction_atlas(pl), nil
+}
+
+// record_visits stamps the current turn on every location that the
+// faction's units touched this turn.
+func record_visits() {
+	for _, pl := range loop_player() {
+		p := p_player(pl)
+		for _, i := range known_sparse_loop(p.locs) {
+			if kind(i) != T_loc {
+				continue
+			}
+			if p.Visited == nil {
+				p.Visited = make(map[int]int)
+			}
+			p.Visited[i] = sysclock.turn
+		}
+	}
+}
+
+// known_to returns true if the faction has seen or visited the location.
+func known_to(pl, n int) bool {
+	if pl == 0 || !valid_box(n) {
+		return false
+	} else if test_known(pl, n) {
+		return true
+	}
+	_, ok := p_player(pl).Visited[n]
+	return ok
+}
+
+// faction_atlas collects the provinces, sublocations, and routes known
+// to the faction.
+func faction_atlas(pl int) *worldmap.Atlas {
+	p := p_player(pl)
+	a := &worldmap.Atlas{
+		Faction: box_code_less(pl),
+		Name:    just_name(pl),
+		Game:    game_number,
+		Turn:    sysclock.turn,
+	}
+
+	for _, i := range loop_loc() {
+		if !known_to(pl, i) {
+			continue
+		}
+		l := &worldmap.Location{
+			Id:          box_code_less(i),
+			Name:        just_name(i),
+			Kind:        subkind_s[subkind(i)],
+			LastVisited: p.Visited[i],
+		}
+		switch depth := loc_depth(i); {
+		case depth == LOC_province:
+			l.Region = just_name(region(i))
+			if is_surface_province(i) {
+				l.Surface = true
+				l.Row, l.Col = region_row_col(i)
+			}
+			a.Provinces = append(a.Provinces, l)
+		case depth > LOC_province:
+			l.Inside = box_code_less(loc(i))
+			l.Hidden = loc_hidden(i)
+			a.Sublocations = append(a.Sublocations, l)
+		}
+	}
+
+	// as on the map, a route is shown if the faction knows both ends,
+	// and, for gates and hidden roads, the route itself.
+	route := func(n int, what string, from, to int, secret bool) {
+		if !known_to(pl, from) || !known_to(pl, to) || (secret && !known_to(pl, n)) {
+			return
+		}
+		a.Routes = append(a.Routes, &worldmap.Route{
+			Id:     box_code_less(n),
+			Kind:   what,
+			Name:   just_name(n),
+			From:   box_code_less(from),
+			To:     box_code_less(to),
+			Hidden: secret,
+		})
+	}
+	for _, i := range loop_kind(T_road) {
+		route(i, "road", loc(i), road_dest(i), road_hidden(i) != FALSE)
+	}
+	for _, i := range loop_gate() {
+		route(i, "gate", loc(i), gate_dest(i), true)
+	}
+
+	return a
+}
+
+// write_atlases writes each faction's atlas as JSON, SVG, and PNG into
+// the log directory, next to the turn report, so that they are saved
+// with it.
+func write_atlases() {
+	stage("write_atlases()")
+
+	for _, pl := range loop_player() {
+		if subkind(pl) != sub_pl_regular {
+			continue
+		}
+		if err := write_atlas(pl, filepath.Join(libdir, "log")); err != nil {
+			log.Printf("write_atlases: %s: %v\n", box_code_less(pl), err)
+		}
+	}
+}
+
+func write_atlas(pl int, dir string) error {
+	data, err := json.MarshalIndent(faction_atlas(pl), "", "  ")
+	if err != nil {
+		return err
+	} else if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.atlas.json", pl)), data, 0666); err != nil {
+		return err
+	}
+
+	m := world_map(pl)
+
+	var b bytes.Buffer
+	if err := m.SVG(&b, worldmap.Options{}); err != nil {
+		return err
+	} else if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.atlas.svg", pl)), b.Bytes(), 0666); err != nil {
+		return err
+	}
+
+	b.Reset()
+	if err := m.PNG(&b, worldmap.Options{}); err != nil {
+		return err
+	} else if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.atlas.png", pl)), b.Bytes(), 0666); err != nil {
+		return err
+	}
+
+	return nil
+}
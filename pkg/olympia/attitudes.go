@@ -32,7 +32,7 @@ type Attitudes struct {
 }
 
 func (a *att_ent) ToAttitudes() *Attitudes {
-	if a == nil || (len(a.neutral) == 0 && len(a.defend) == 0 || len(a.hostile) == 0) {
+	if a == nil || (len(a.neutral) == 0 && len(a.defend) == 0 && len(a.hostile) == 0) {
 		return nil
 	}
 	return &Attitudes{
@@ -41,3 +41,14 @@ func (a *att_ent) ToAttitudes() *Attitudes {
 		Hostile: a.hostile.ToBoxList(),
 	}
 }
+
+func (a *Attitudes) toBox() *att_ent {
+	if a == nil {
+		return nil
+	}
+	return &att_ent{
+		neutral: append(ints_l(nil), a.Neutral...),
+		hostile: append(ints_l(nil), a.Hostile...),
+		defend:  append(ints_l(nil), a.Defend...),
+	}
+}
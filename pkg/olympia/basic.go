@@ -687,8 +687,8 @@ func new_scroll(who int) int {
 	}
 	set_name(newScroll, "Scroll")
 
+	set_item_creator(newScroll, who)
 	p := p_item_magic(newScroll)
-	p.Creator = who
 	p.OrbUseCount = 1 /* Let the scroll get one use. */
 	p_item(newScroll).weight = 1
 
@@ -0,0 +1,295 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"fmt"
+	"github.com/mdhender/golympia/pkg/battle"
+	"log"
+	"path/filepath"
+)
+
+/*
+ *  The structured battle record.  The combat code calls the battle_
+ *  hooks as it fights; they mirror what run_combat writes to the
+ *  combat log and the location reports, but as data.
+ */
+
+var (
+	battles_this_turn []*battle.Battle /* every battle fought this turn */
+	cur_battle        *battle.Battle   /* the battle being fought, if any */
+	cur_battle_round  *battle.Round
+	cur_battle_phase  string
+	battle_refs       map[*fight]*battle.Ref
+	battle_sides      map[int]string /* unit -> side, for booty */
+	battle_attacks    map[battle_attack_key]*battle.Event
+)
+
+type battle_attack_key struct {
+	phase  string
+	by     *fight
+	target *fight
+}
+
+// battle_fighter describes a fighter as it enters the battle.
+func battle_fighter(f *fight, ally bool) *battle.Fighter {
+	bf := &battle.Fighter{
+		Unit:    box_code_less(f.unit),
+		Name:    just_name(f.unit),
+		Count:   f.sav_num,
+		Attack:  f.attack,
+		Defense: f.defense,
+		Missile: f.missile,
+		Behind:  f.behind,
+		Inside:  f.inside,
+		Ally:    ally || f.ally != FALSE,
+	}
+	switch f.kind {
+	case FK_noble:
+		bf.Kind, bf.Count = "noble", 1
+		bf.Health = char_health(f.unit)
+	case FK_fort:
+		bf.Kind = "structure"
+	default:
+		bf.Kind = "item"
+		bf.Item = box_code_less(f.kind)
+		bf.ItemName = plural_item_name(f.kind, f.sav_num)
+	}
+	if f.kind != FK_fort {
+		if pl := player(f.unit); pl != 0 {
+			bf.Faction = box_code_less(pl)
+		}
+	}
+	return bf
+}
+
+func battle_add_fighters(s *battle.Side, side string, l []*fight, joined int) {
+	for _, f := range l {
+		if battle_refs[f] != nil {
+			continue
+		}
+		bf := battle_fighter(f, joined != 0)
+		bf.Joined = joined
+		battle_refs[f] = &battle.Ref{Side: side, Fighter: len(s.Fighters)}
+		battle_sides[f.unit] = side
+		s.Fighters = append(s.Fighters, bf)
+		if joined != 0 && f.kind == FK_noble {
+			battle_event(battle.Join, nil, f, 0)
+		}
+	}
+}
+
+// battle_begin starts the record of a battle between the fight lists.
+func battle_begin(l_a, l_b []*fight) {
+	where := combat_def_loc
+	if where == 0 {
+		where = subloc(l_b[0].unit)
+	}
+	cur_battle = &battle.Battle{
+		Number:    len(battles_this_turn) + 1,
+		Turn:      sysclock.turn,
+		Day:       sysclock.day,
+		Where:     box_code_less(where),
+		WhereName: just_name(where),
+		Terrain:   subkind_s[subkind(province(where))],
+		Rain:      combat_rain,
+		Wind:      combat_wind,
+		Sea:       combat_sea,
+		Attacker:  &battle.Side{Leader: box_code_less(lead_char(l_a))},
+		Defender:  &battle.Side{Leader: box_code_less(lead_char(l_b))},
+	}
+	cur_battle_round, cur_battle_phase = nil, ""
+	battle_refs = make(map[*fight]*battle.Ref)
+	battle_sides = make(map[int]string)
+	battle_add_fighters(cur_battle.Attacker, battle.Attacker, l_a, 0)
+	battle_add_fighters(cur_battle.Defender, battle.Defender, l_b, 0)
+	battles_this_turn = append(battles_this_turn, cur_battle)
+}
+
+// battle_join records the defender's allies joining the battle.
+func battle_join(l_b []*fight) {
+	if cur_battle != nil {
+		battle_add_fighters(cur_battle.Defender, battle.Defender, l_b, round)
+	}
+}
+
+func battle_round(n int) {
+	if cur_battle == nil {
+		return
+	}
+	cur_battle_round = &battle.Round{Round: n}
+	cur_battle.Rounds = append(cur_battle.Rounds, cur_battle_round)
+	cur_battle_phase = ""
+	battle_attacks = make(map[battle_attack_key]*battle.Event)
+}
+
+func battle_phase(phase string) {
+	cur_battle_phase = phase
+}
+
+// battle_event adds an event to the current round.
+// Returns nil if no battle is being recorded.
+func battle_event(typ string, f, g *fight, count int) *battle.Event {
+	if cur_battle == nil || cur_battle_round == nil {
+		return nil
+	}
+	e := &battle.Event{Phase: cur_battle_phase, Type: typ, Count: count}
+	if f != nil {
+		e.By = battle_refs[f]
+	}
+	if g != nil {
+		e.Target = battle_refs[g]
+	}
+	cur_battle_round.Events = append(cur_battle_round.Events, e)
+	return e
+}
+
+// battle_attack records an attack by f on g.
+func battle_attack(f, g *fight, hit bool) {
+	if cur_battle == nil || cur_battle_round == nil {
+		return
+	}
+	k := battle_attack_key{phase: cur_battle_phase, by: f, target: g}
+	e := battle_attacks[k]
+	if e == nil {
+		e = battle_event(battle.Attack, f, g, 0)
+		battle_attacks[k] = e
+	}
+	e.Count++
+	if hit {
+		e.Hits++
+	}
+}
+
+func battle_spell(f *fight, skill int, count int) {
+	if e := battle_event(battle.Spell, f, nil, count); e != nil {
+		e.Skill = box_code_less(skill)
+	}
+}
+
+func battle_special(typ string, f *fight, item int, count int) {
+	if e := battle_event(typ, f, nil, count); e != nil && item != 0 {
+		e.Item = box_code_less(item)
+	}
+}
+
+// battle_losses records the hits resolved against g at the end of a phase.
+func battle_losses(g *fight, count int) {
+	switch g.kind {
+	case FK_fort:
+		battle_event(battle.Damage, nil, g, count)
+	case FK_noble:
+		battle_event(battle.Wound, nil, g, count)
+	default:
+		battle_event(battle.Casualties, nil, g, count)
+	}
+}
+
+func battle_prisoner(who, captor int) {
+	if cur_battle != nil {
+		cur_battle.Prisoners = append(cur_battle.Prisoners, &battle.Prisoner{
+			Unit:   box_code_less(who),
+			Captor: box_code_less(captor),
+		})
+	}
+}
+
+// battle_booty records items taken by one side from the other.
+// Items passed between stackmates aren't booty.
+func battle_booty(from, to, item, qty int) {
+	if cur_battle == nil || qty <= 0 {
+		return
+	}
+	if a, b := battle_sides[from], battle_sides[to]; a == "" || b == "" || a == b {
+		return
+	}
+	cur_battle.Booty = append(cur_battle.Booty, &battle.Booty{
+		From:     box_code_less(from),
+		To:       box_code_less(to),
+		Item:     box_code_less(item),
+		ItemName: plural_item_name(item, qty),
+		Qty:      qty,
+	})
+}
+
+// battle_end records the result and what became of each fighter.
+// Called after reconcile, so the dead are dead and the prisoners taken.
+func battle_end(result int, l_a, l_b []*fight) {
+	if cur_battle == nil {
+		return
+	}
+	switch result {
+	case A_WON:
+		cur_battle.Result = battle.Attacker
+	case B_WON:
+		cur_battle.Result = battle.Defender
+	default:
+		cur_battle.Result = battle.Tie
+	}
+	for _, l := range [][]*fight{l_a, l_b} {
+		for _, f := range l {
+			r := battle_refs[f]
+			if r == nil {
+				continue
+			}
+			bf := cur_battle.Fighter(r)
+			if f.kind != FK_noble {
+				bf.Lost = f.sav_num - f.num
+				continue
+			}
+			health := bf.Health
+			bf.Health = f.new_health
+			if kind(f.unit) != T_char {
+				bf.Fate, bf.Health = "killed", 0
+			} else if is_prisoner(f.unit) {
+				bf.Fate = "captured"
+			} else if f.new_health < health {
+				bf.Fate = "wounded"
+			} else {
+				bf.Fate = "unhurt"
+			}
+		}
+	}
+	cur_battle, cur_battle_round = nil, nil
+	battle_refs, battle_sides, battle_attacks = nil, nil, nil
+}
+
+// write_battles writes the turn's battles to log/battles.json for the GM,
+// and each faction's view of its battles to log/<pl>.battles.json.
+func write_battles() {
+	stage("write_battles()")
+
+	dir := filepath.Join(libdir, "log")
+	if err := battle.Write(filepath.Join(dir, "battles.json"), battles_this_turn); err != nil {
+		log.Printf("write_battles: %v\n", err)
+	}
+	for _, pl := range loop_player() {
+		if subkind(pl) != sub_pl_regular {
+			continue
+		}
+		l := battle.ForFaction(battles_this_turn, box_code_less(pl))
+		if len(l) == 0 {
+			continue
+		}
+		if err := battle.Write(filepath.Join(dir, fmt.Sprintf("%d.battles.json", pl)), l); err != nil {
+			log.Printf("write_battles: %s: %v\n", box_code_less(pl), err)
+		}
+	}
+}
@@ -38,6 +38,8 @@ func BoxAlloc(id, kind, skind int) {
 	add_sub_chain(id)
 }
 
+// Box is the json version of a box.
+// Every section that the legacy text format saves has a field here.
 type Box struct {
 	Id             int             `json:"id"`             // identity of the thing
 	Name           string          `json:"name,omitempty"` // name of the thing
@@ -45,12 +47,17 @@ type Box struct {
 	SubKind        int             `json:"sub-kind,omitempty"`
 	Attitudes      *Attitudes      `json:"attitudes,omitempty"`
 	CharMagic      *CharMagic      `json:"char-magic,omitempty"`
+	Command        *Command        `json:"command,omitempty"`
 	Effects        EffectList      `json:"effects,omitempty"`
 	EntityArtifact *EntityArtifact `json:"entity-artifact,omitempty"`
 	EntityChar     *EntityChar     `json:"entity-char,omitempty"`
+	EntityGate     *EntityGate     `json:"entity-gate,omitempty"`
 	EntityItem     *EntityItem     `json:"entity-item,omitempty"`
 	EntityLoc      *EntityLoc      `json:"entity-loc,omitempty"`
+	EntityMisc     *EntityMisc     `json:"entity-misc,omitempty"`
+	EntityNation   *EntityNation   `json:"entity-nation,omitempty"`
 	EntityPlayer   *EntityPlayer   `json:"entity-player,omitempty"`
+	EntitySkill    *EntitySkill    `json:"entity-skill,omitempty"`
 	EntitySubLoc   *EntitySubLoc   `json:"entity-subloc,omitempty"`
 	ItemMagic      *ItemMagic      `json:"item-magic,omitempty"`
 	Items          InventoryList   `json:"items,omitempty"`
@@ -58,6 +65,86 @@ type Box struct {
 	Trades         TradeList       `json:"trades,omitempty"`
 }
 
+// ToBox converts the in-memory box to the json model.
+func (b *box) ToBox(id int) *Box {
+	if b == nil {
+		return nil
+	}
+	return &Box{
+		Id:             id,
+		Name:           b.name,
+		Kind:           b.kind,
+		SubKind:        b.skind,
+		Attitudes:      b.ToAttitudes(),
+		CharMagic:      b.ToCharMagic(),
+		Command:        b.cmd.ToCommand(),
+		Effects:        b.ToEffectList(),
+		EntityArtifact: b.ToEntityArtifact(id),
+		EntityChar:     b.ToEntityChar(),
+		EntityGate:     b.x_gate.ToEntityGate(),
+		EntityItem:     b.ToEntityItem(id),
+		EntityLoc:      b.ToEntityLoc(),
+		EntityMisc:     b.x_misc.ToEntityMisc(),
+		EntityNation:   b.x_nation.ToEntityNation(),
+		EntityPlayer:   b.ToEntityPlayer(),
+		EntitySkill:    b.x_skill.ToEntitySkill(),
+		EntitySubLoc:   b.ToEntitySubLoc(),
+		ItemMagic:      b.ToItemMagic(id),
+		Items:          b.ToInventoryList(),
+		LocationInfo:   b.ToLocationInfo(),
+		Trades:         b.ToTradeList(),
+	}
+}
+
+// toBox fills in the in-memory box that was allocated for the json box.
+// The box must already be allocated with the right kind and subkind.
+func (b *Box) toBox(p *box) (err error) {
+	p.name = b.Name
+	p.x_loc_info = b.LocationInfo.toBox()
+	p.x_player = b.EntityPlayer
+	p.x_char = b.EntityChar.toBox()
+	if b.CharMagic != nil {
+		if p.x_char == nil {
+			p.x_char = &entity_char{}
+		}
+		p.x_char.x_char_magic = b.CharMagic.toBox()
+	}
+	p.x_loc = b.EntityLoc.toBox()
+	p.x_subloc = b.EntitySubLoc.toBox()
+	if b.EntityItem != nil {
+		p.x_item = b.EntityItem.toBox()
+		p.x_item.id = b.Id
+	}
+	if b.ItemMagic != nil {
+		if p.x_item == nil {
+			p.x_item = &entity_item{id: b.Id}
+		}
+		im := *b.ItemMagic
+		im.Id = b.Id
+		p.x_item.x_item_magic = &im
+	}
+	if b.EntityArtifact != nil {
+		if p.x_item == nil {
+			p.x_item = &entity_item{id: b.Id}
+		}
+		ea := *b.EntityArtifact
+		ea.Id = b.Id
+		p.x_item.x_item_artifact = &ea
+	}
+	p.x_skill = b.EntitySkill.toBox()
+	p.x_nation = b.EntityNation.toBox()
+	p.x_gate = b.EntityGate.toBox()
+	p.x_misc = b.EntityMisc.toBox()
+	p.x_disp = b.Attitudes.toBox()
+	if p.cmd, err = b.Command.toBox(b.Id); err != nil {
+		return err
+	}
+	p.items = b.Items.toBox()
+	p.trades = b.Trades.toBox(b.Id)
+	p.effects = b.Effects.toBox()
+	return nil
+}
+
 // ToBoxList replaces boxlist_print
 func (l ints_l) ToBoxList() (il ints_l) {
 	for _, e := range l {
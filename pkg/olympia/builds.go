@@ -23,10 +23,10 @@ type EntityBuildList []*EntityBuild
 
 // EntityBuild describes what kind of build is going on in a location.
 type EntityBuild struct {
-	Type           int // What work is going on?
-	BuildMaterials int // fifths of materials we've used
-	EffortGiven    int //
-	EffortRequired int // not finished if nonzero
+	Type           int `json:"type,omitempty"`            // What work is going on?
+	BuildMaterials int `json:"build-materials,omitempty"` // fifths of materials we've used
+	EffortGiven    int `json:"effort-given,omitempty"`    //
+	EffortRequired int `json:"effort-required,omitempty"` // not finished if nonzero
 }
 
 type entity_build_l []*entity_build
@@ -55,6 +55,18 @@ func (e *entity_build) ToEntityBuild() *EntityBuild {
 	}
 }
 
+func (l EntityBuildList) toBox() (bl entity_build_l) {
+	for _, e := range l {
+		bl = append(bl, &entity_build{
+			type_:           e.Type,
+			build_materials: e.BuildMaterials,
+			effort_given:    e.EffortGiven,
+			effort_required: e.EffortRequired,
+		})
+	}
+	return bl
+}
+
 func (l entity_build_l) delete(index int) entity_build_l {
 	var cp entity_build_l
 	for i, e := range l {
@@ -1051,16 +1051,18 @@ func do_province_tax(c *command) int {
 		return FALSE
 	}
 
+	max_rate := game_rules.Taxes.MaxRate
+
 	if rate < 0 {
-		wout(c.who, "The province tax should be from 0 to 10.")
+		wout(c.who, "The province tax should be from 0 to %d.", max_rate)
 		wout(c.who, "I'll assume you meant 'tax 0'.")
 		rate = 0
 	}
 
-	if rate > 10 {
-		wout(c.who, "The province tax should be from 0 to 10.")
-		wout(c.who, "I'll assume you meant 'tax 10'.")
-		rate = 10
+	if rate > max_rate {
+		wout(c.who, "The province tax should be from 0 to %d.", max_rate)
+		wout(c.who, "I'll assume you meant 'tax %d'.", max_rate)
+		rate = max_rate
 	}
 
 	if FALSE == garrison_here(province) {
@@ -35,10 +35,10 @@ type CharMagic struct {
 	Auraculum        int    `json:"auraculum,omitempty"`         // char created an auraculum
 	CurAura          int    `json:"cur-aura,omitempty"`          // current aura level for magician
 	HideMage         int    `json:"hide-mage,omitempty"`         // number of points hiding the magician
-	HideSelf         bool   `json:"hide-self,omitempty"`         // character is hidden
+	HideSelf         int    `json:"hide-self,omitempty"`         // character is hidden
 	HinderMeditation int    `json:"hinder-meditation,omitempty"` // number of points to hinder, usually 0...3
-	KnowsWeather     bool   `json:"knows-weather,omitempty"`     // knows weather magic
-	Magician         bool   `json:"magician,omitempty"`          // is a magician
+	KnowsWeather     int    `json:"knows-weather,omitempty"`     // knows weather magic
+	Magician         int    `json:"magician,omitempty"`          // is a magician
 	MaxAura          int    `json:"max-aura,omitempty"`          // maximum aura level for magician
 	ProjectCast      int    `json:"project-cast,omitempty"`      // project next cast
 	QuickCast        int    `json:"quick-cast,omitempty"`        // speed next cast
@@ -55,12 +55,28 @@ type CharMagic struct {
 }
 
 type CharReligion struct {
-	Priest    int    /* Who this noble is dedicated to, if anyone. */
-	Piety     int    /* Our current piety. */
-	Followers ints_l /* Who is dedicated to us, if anyone. */
+	Priest    int    `json:"priest,omitempty"`    // Who this noble is dedicated to, if anyone.
+	Piety     int    `json:"piety,omitempty"`     // Our current piety.
+	Followers ints_l `json:"followers,omitempty"` // Who is dedicated to us, if anyone.
+}
+
+// AcceptEnt is what a character will accept from others.
+type AcceptEnt struct {
+	Item    int `json:"item,omitempty"`     // 0 = any item
+	FromWho int `json:"from-who,omitempty"` // 0 = anyone, else char or player
+	Qty     int `json:"qty,omitempty"`      // 0 = any qty
+}
+
+// SkillEnt is a skill known (or being studied) by a character.
+type SkillEnt struct {
+	Skill       int `json:"skill"`
+	DaysStudied int `json:"days-studied,omitempty"` // days studied * TOUGH_NUM
+	Experience  int `json:"experience,omitempty"`   // experience level with skill
+	Know        int `json:"know,omitempty"`         // SKILL_xxx
 }
 
 type EntityChar struct {
+	Accept             []*AcceptEnt  `json:"accept,omitempty"`               // what we can be given
 	Attack             int           `json:"attack,omitempty"`               // fighter attack rating
 	Behind             int           `json:"behind,omitempty"`               // are we behind in combat?
 	BreakPoint         int           `json:"breakPoint,omitempty"`           // break point when fighting
@@ -81,20 +97,18 @@ type EntityChar struct {
 	Rank               int           `json:"rank,omitempty"`                 // noble peerage status
 	Religion           *CharReligion `json:"religion,omitempty"`             // Our religion info...
 	Sick               int           `json:"sick,omitempty"`                 // 1=character is getting worse
-	Skills             skill_ent_l   `json:"skills,omitempty"`               // skills known by char
+	Skills             []*SkillEnt   `json:"skills,omitempty"`               // skills known by char
 	TimeFlying         int           `json:"time-flying,omitempty"`          // time airborne over ocean
 	UnitItem           int           `json:"unit-item,omitempty"`            // unit is made of this kind of item
 	UnitLord           int           `json:"unit-lord,omitempty"`            // who is our owner?
-	XCharMagic         *CharMagic    `json:"x-char-magic,omitempty"`
 
 	// Effects []*effect // list of effects on char // not used?
 
 	// the following are not saved
-	accept     accept_ent_l // what we can be given
-	fresh_hire int          // don't erode loyalty
-	melt_me    int          // in process of melting away
-	new_lord   int          // got a new lord this turn
-	studied    int          // num days we studied
+	fresh_hire int // don't erode loyalty
+	melt_me    int // in process of melting away
+	new_lord   int // got a new lord this turn
+	studied    int // num days we studied
 }
 
 type CharacterList []*Character
@@ -192,18 +206,20 @@ func (e *entity_char) ToEntityChar() *EntityChar {
 		Rank:               e.rank,
 		Religion:           e.religion.ToCharReligion(),
 		Sick:               e.sick,
-		Skills:             e.skills,
-		TimeFlying:         e.time_flying,
-		UnitItem:           e.unit_item,
-		UnitLord:           e.unit_lord,
-		XCharMagic:         e.x_char_magic.ToCharMagic(),
-		fresh_hire:         e.fresh_hire,
-		melt_me:            e.melt_me,
-		new_lord:           e.new_lord,
-		studied:            e.studied,
+
+		TimeFlying: e.time_flying,
+		UnitItem:   e.unit_item,
+		UnitLord:   e.unit_lord,
+		fresh_hire: e.fresh_hire,
+		melt_me:    e.melt_me,
+		new_lord:   e.new_lord,
+		studied:    e.studied,
+	}
+	for _, a := range e.accept {
+		ec.Accept = append(ec.Accept, &AcceptEnt{Item: a.item, FromWho: a.from_who, Qty: a.qty})
 	}
-	if len(e.accept) != 0 {
-		ec.accept = append(ec.accept, e.accept...)
+	for _, sk := range e.skills {
+		ec.Skills = append(ec.Skills, &SkillEnt{Skill: sk.skill, DaysStudied: sk.days_studied, Experience: sk.experience, Know: sk.know})
 	}
 
 	return ec
@@ -233,10 +249,10 @@ func (x *char_magic) ToCharMagic() *CharMagic {
 		Auraculum:        x.auraculum,
 		CurAura:          x.cur_aura,
 		HideMage:         x.hide_mage,
-		HideSelf:         x.hide_self != FALSE,
+		HideSelf:         x.hide_self,
 		HinderMeditation: x.hinder_meditation,
-		KnowsWeather:     x.knows_weather != FALSE,
-		Magician:         x.magician != FALSE,
+		KnowsWeather:     x.knows_weather,
+		Magician:         x.magician,
 		MaxAura:          x.max_aura,
 		ProjectCast:      x.project_cast,
 		QuickCast:        x.quick_cast,
@@ -253,3 +269,75 @@ func (x *char_magic) ToCharMagic() *CharMagic {
 	}
 	return c
 }
+
+func (e *EntityChar) toBox() *entity_char {
+	if e == nil {
+		return nil
+	}
+	c := &entity_char{
+		unit_item:            e.UnitItem,
+		health:               e.Health,
+		sick:                 e.Sick,
+		guard:                e.Guard,
+		loy_kind:             e.LoyKind,
+		loy_rate:             e.LoyRate,
+		death_time:           e.DeathTime.toBox(),
+		moving:               e.Moving,
+		unit_lord:            e.UnitLord,
+		contact:              append([]int(nil), e.Contact...),
+		prisoner:             e.Prisoner,
+		behind:               e.Behind,
+		time_flying:          e.TimeFlying,
+		break_point:          e.BreakPoint,
+		personal_break_point: e.PersonalBreakPoint,
+		rank:                 e.Rank,
+		npc_prog:             e.NpcProg,
+		guild:                e.Guild,
+		attack:               e.Attack,
+		defense:              e.Defense,
+		missile:              e.Missile,
+		religion:             e.Religion.toBox(),
+		pay:                  e.Pay,
+	}
+	for _, a := range e.Accept {
+		c.accept = append(c.accept, &accept_ent{item: a.Item, from_who: a.FromWho, qty: a.Qty})
+	}
+	for _, sk := range e.Skills {
+		c.skills = append(c.skills, &skill_ent{skill: sk.Skill, days_studied: sk.DaysStudied, experience: sk.Experience, know: sk.Know})
+	}
+	return c
+}
+
+func (r *CharReligion) toBox() char_religion {
+	if r == nil {
+		return char_religion{}
+	}
+	return char_religion{
+		priest:    r.Priest,
+		piety:     r.Piety,
+		followers: append(ints_l(nil), r.Followers...),
+	}
+}
+
+func (x *CharMagic) toBox() *char_magic {
+	if x == nil {
+		return nil
+	}
+	return &char_magic{
+		max_aura:          x.MaxAura,
+		cur_aura:          x.CurAura,
+		auraculum:         x.Auraculum,
+		visions:           append(sparse(nil), x.Visions...),
+		token:             x.Token,
+		project_cast:      x.ProjectCast,
+		quick_cast:        x.QuickCast,
+		ability_shroud:    x.AbilityShroud,
+		hide_mage:         x.HideMage,
+		hinder_meditation: x.HinderMeditation,
+		magician:          x.Magician,
+		aura_reflect:      x.AuraReflect,
+		hide_self:         x.HideSelf,
+		swear_on_release:  x.SwearOnRelease,
+		knows_weather:     x.KnowsWeather,
+	}
+}
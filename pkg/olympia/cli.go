@@ -25,10 +25,24 @@ import (
 	"path/filepath"
 )
 
-func GenerateMap(options ...Option) error {
+// GenerateMap builds a new world from the map data and validates it.
+// The map is drawn to the preview file, if one was given, before anything
+// is written. With dryRun, nothing is saved to libdir; otherwise the world
+// is saved unless validation found fatal problems and force is false.
+// Unless it is a dry run, the lib-dir lock is held from before sysdata
+// is read until everything is saved.
+func GenerateMap(dryRun, force bool, options ...Option) (v *MapValidation, err error) {
+	defer recover_turn("GenerateMap", &err)
+	defer unlock_tag()
+
 	for _, option := range options {
 		if err := option(); err != nil {
-			return err
+			return nil, err
+		}
+	}
+	if !dryRun {
+		if err := lock_tag(); err != nil {
+			return nil, fmt.Errorf("GenerateMap: %w", err)
 		}
 	}
 
@@ -42,12 +56,14 @@ func GenerateMap(options ...Option) error {
 	log.Printf("%-18s == %q\n", "seed data", seedDataFilename)
 
 	if _, err := SysDataLoad(filepath.Join(libdir, "sysdata.json")); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return nil, fmt.Errorf("GenerateMap: %w", err)
 	}
 
 	clear_alloc_flag()
 	dir_assert()
-	load_seed(seedDataFilename)
+	if err := load_seed(seedDataFilename); err != nil {
+		return nil, fmt.Errorf("GenerateMap: %w", err)
+	}
 
 	map_init()
 
@@ -91,58 +107,73 @@ func GenerateMap(options ...Option) error {
 	log.Println("")
 	log.Println("")
 
+	v = validate_map()
+	for _, i := range v.Issues {
+		log.Printf("GenerateMap: (%2d,%2d) %s: %s\n", i.Row, i.Col, i.Kind, i.Message)
+	}
+	if mapPreviewFilename != "" {
+		if err := write_map_preview(); err != nil {
+			return v, fmt.Errorf("GenerateMap: %w", err)
+		}
+	}
+	if dryRun {
+		return v, nil
+	} else if n := v.Fatal(); n != 0 && !force {
+		return v, fmt.Errorf("GenerateMap: %d problems in the map, not saved", n)
+	}
+
 	/* check database integrity */
 	if err := check_db(); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 	// and save
 	if err := save_db(); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 
 	if err := print_map(filepath.Join(libdir, "map-data.json")); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 	if err := print_sublocs(filepath.Join(libdir, "subloc-data.json")); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 	if err := dump_continents(filepath.Join(libdir, continentDataFilename)); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 	if err := RoadDataSave(filepath.Join(libdir, roadDataFilename)); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 	if err := GateDataSave(filepath.Join(libdir, gateDataFilename)); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 	if err := CharacterDataSave(filepath.Join(libdir, "characters")); err != nil {
 		log.Println(fmt.Errorf("GenerateMap: %w", err))
-		//return fmt.Errorf("GenerateMap: %w", err)
+		//return v, fmt.Errorf("GenerateMap: %w", err)
 	}
-	if err := LocationDataSave(filepath.Join(libdir, "locations.json")); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+	if err := LocationDataSave(filepath.Join(libdir, locationDataFilename)); err != nil {
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 	if err := EntityItemDataSave(filepath.Join(libdir, "items.json")); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 	if err := MiscDataSave(filepath.Join(libdir, "misc.json")); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 	if err := NationDataSave(filepath.Join(libdir, "nations.json")); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 	if err := ShipDataSave(filepath.Join(libdir, "ships.json")); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 	if err := SkillDataSave(filepath.Join(libdir, "skills.json")); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 	if err := SysDataSave(filepath.Join(libdir, "sysdata.json")); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 	if err := UnformDataSave(filepath.Join(libdir, "unform.json")); err != nil {
-		return fmt.Errorf("GenerateMap: %w", err)
+		return v, fmt.Errorf("GenerateMap: %w", err)
 	}
 
-	return nil
+	return v, nil
 }
@@ -0,0 +1,353 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+ *  The command table (cmd_tbl) and the skill use table (use_tbl) are
+ *  built from command definitions.  The defaults are compiled in from
+ *  commands.json; a game may replace them with a commands.json in its
+ *  lib directory to change durations or priorities, or to disable
+ *  commands, without rebuilding the engine.  The commands the engine
+ *  looks up by name (required_commands) can't be disabled.
+ *
+ *  Definitions name their handlers.  The names are checked against
+ *  the handler registry in cmdtbl_handlers.go when the table is built.
+ */
+
+//go:embed commands.json
+var default_command_defs []byte
+
+const COMMANDS_FILE = "commands.json"
+
+// CommandDefs is the json version of the command and skill use tables.
+type CommandDefs struct {
+	Commands []*CommandDef `json:"commands"`
+	Uses     []*UseDef     `json:"uses"`
+}
+
+// CommandDef defines an order.
+// Time is the number of days the order takes; -1 means it runs until its
+// handler says it is done. Poll calls the finish handler every day.
+// A disabled command is left out of the table, so orders using it are
+// rejected as unknown.
+type CommandDef struct {
+	Name      string       `json:"name"`
+	Aliases   []string     `json:"aliases,omitempty"`
+	Allow     string       `json:"allow"` // who may issue the order; see glob.go
+	Start     string       `json:"start,omitempty"`
+	Finish    string       `json:"finish,omitempty"`
+	Interrupt string       `json:"interrupt,omitempty"`
+	Time      int          `json:"time"`
+	Poll      bool         `json:"poll,omitempty"`
+	Priority  int          `json:"priority"`
+	Args      *CommandArgs `json:"args,omitempty"`
+	Comment   string       `json:"comment,omitempty"` // handler that annotates the order in the acknowledgement
+	Check     string       `json:"check,omitempty"`   // handler that checks the order when it is eaten
+	Disabled  bool         `json:"disabled,omitempty"`
+	Help      string       `json:"help,omitempty"`
+}
+
+// CommandArgs is the argument schema for an order.
+// Types are unit, item, skill, days, qty, gold, use, practice, or empty
+// for an argument that isn't checked.
+type CommandArgs struct {
+	Required int      `json:"required,omitempty"`
+	Max      int      `json:"max,omitempty"` // 0 for no limit
+	Types    []string `json:"types,omitempty"`
+}
+
+// UseDef defines the use of a skill with the USE order.
+// Name is only there for the reader; skills are matched by number.
+type UseDef struct {
+	Skill     int    `json:"skill"`
+	Name      string `json:"name,omitempty"`
+	Allow     string `json:"allow"`
+	Start     string `json:"start,omitempty"`
+	Finish    string `json:"finish,omitempty"`
+	Interrupt string `json:"interrupt,omitempty"`
+	Time      int    `json:"time"`
+	Poll      bool   `json:"poll,omitempty"`
+	Disabled  bool   `json:"disabled,omitempty"`
+}
+
+// required_commands are looked up by name by the order scanner and the
+// turn, so a game can't disable them or leave them out.
+var required_commands = []string{
+	"begin", "build", "collect", "email", "end", "format", "lore", "message",
+	"notab", "option", "passwd", "password", "players", "post", "press",
+	"resend", "rumor", "split", "stop", "unit", "vis_email", "wait",
+}
+
+var cmd_arg_types = map[string]int{
+	"":         CMD_undef,
+	"unit":     CMD_unit,
+	"item":     CMD_item,
+	"skill":    CMD_skill,
+	"days":     CMD_days,
+	"qty":      CMD_qty,
+	"gold":     CMD_gold,
+	"use":      CMD_use,
+	"practice": CMD_practice,
+}
+
+// parse_command_defs decodes and checks a set of command definitions.
+func parse_command_defs(data []byte) (*CommandDefs, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	defs := &CommandDefs{}
+	if err := dec.Decode(defs); err != nil {
+		return nil, err
+	} else if _, _, err := build_cmd_tbl(defs); err != nil {
+		return nil, err
+	}
+	return defs, nil
+}
+
+// build_cmd_tbl returns the command and use tables for the definitions.
+// Both tables start and end with an empty entry, as the lookups expect.
+func build_cmd_tbl(defs *CommandDefs) ([]cmd_tbl_ent, []use_tbl_ent, error) {
+	handler := func(name, kind, h string) (func(*command) int, error) {
+		if h == "" {
+			return nil, nil
+		} else if fn, ok := cmd_handlers[h]; ok {
+			return fn, nil
+		}
+		return nil, fmt.Errorf("%s: unknown %s handler %q", name, kind, h)
+	}
+	check_allow := func(name, allow string) error {
+		for _, ch := range allow {
+			if !strings.ContainsRune("cgimpr", ch) {
+				return fmt.Errorf("%s: unknown allow flag %q", name, ch)
+			}
+		}
+		return nil
+	}
+
+	ctbl := []cmd_tbl_ent{{pri: 3}}
+	seen, enabled := make(map[string]bool), make(map[string]bool)
+	for _, d := range defs.Commands {
+		if d.Name == "" {
+			return nil, nil, fmt.Errorf("command %d: missing name", len(ctbl))
+		} else if err := check_allow(d.Name, d.Allow); err != nil {
+			return nil, nil, err
+		} else if d.Time < -1 {
+			return nil, nil, fmt.Errorf("%s: time must be -1 or more", d.Name)
+		} else if d.Priority < 0 {
+			return nil, nil, fmt.Errorf("%s: priority must not be negative", d.Name)
+		}
+
+		e := cmd_tbl_ent{
+			allow: d.Allow,
+			time:  d.Time,
+			pri:   d.Priority,
+			help:  d.Help,
+		}
+		if d.Poll {
+			e.poll = TRUE
+		}
+		var err error
+		if e.start, err = handler(d.Name, "start", d.Start); err != nil {
+			return nil, nil, err
+		} else if e.finish, err = handler(d.Name, "finish", d.Finish); err != nil {
+			return nil, nil, err
+		} else if e.interrupt, err = handler(d.Name, "interrupt", d.Interrupt); err != nil {
+			return nil, nil, err
+		}
+		if d.Comment != "" {
+			if e.cmd_comment = cmd_comments[d.Comment]; e.cmd_comment == nil {
+				return nil, nil, fmt.Errorf("%s: unknown comment handler %q", d.Name, d.Comment)
+			}
+		}
+		if d.Check != "" {
+			if e.cmd_check = cmd_checks[d.Check]; e.cmd_check == nil {
+				return nil, nil, fmt.Errorf("%s: unknown check handler %q", d.Name, d.Check)
+			}
+		}
+		if a := d.Args; a != nil {
+			if a.Required < 0 || a.Max < 0 {
+				return nil, nil, fmt.Errorf("%s: argument counts must not be negative", d.Name)
+			} else if a.Max != 0 && a.Max < a.Required {
+				return nil, nil, fmt.Errorf("%s: max arguments is less than required", d.Name)
+			} else if len(a.Types) > len(e.arg_types) {
+				return nil, nil, fmt.Errorf("%s: at most %d argument types", d.Name, len(e.arg_types))
+			}
+			e.num_args_required, e.max_args = a.Required, a.Max
+			for i, t := range a.Types {
+				n, ok := cmd_arg_types[t]
+				if !ok {
+					return nil, nil, fmt.Errorf("%s: unknown argument type %q", d.Name, t)
+				}
+				e.arg_types[i] = n
+			}
+		}
+
+		for _, name := range append([]string{d.Name}, d.Aliases...) {
+			if name == "" {
+				return nil, nil, fmt.Errorf("%s: empty alias", d.Name)
+			} else if seen[strings.ToLower(name)] {
+				return nil, nil, fmt.Errorf("%s: duplicate command name", name)
+			}
+			seen[strings.ToLower(name)] = true
+			if !d.Disabled {
+				e.name = name
+				ctbl = append(ctbl, e)
+				enabled[strings.ToLower(name)] = true
+			}
+		}
+	}
+	ctbl = append(ctbl, cmd_tbl_ent{pri: 1})
+	for _, name := range required_commands {
+		if !enabled[name] {
+			return nil, nil, fmt.Errorf("%s: required by the engine; it can't be disabled or left out", name)
+		}
+	}
+
+	utbl := []use_tbl_ent{{}}
+	used := make(map[int]bool)
+	for _, d := range defs.Uses {
+		name := fmt.Sprintf("use %d", d.Skill)
+		if d.Skill <= 0 {
+			return nil, nil, fmt.Errorf("use %d: missing skill", len(utbl))
+		} else if used[d.Skill] {
+			return nil, nil, fmt.Errorf("%s: duplicate skill", name)
+		} else if err := check_allow(name, d.Allow); err != nil {
+			return nil, nil, err
+		} else if d.Time < -1 {
+			return nil, nil, fmt.Errorf("%s: time must be -1 or more", name)
+		}
+		used[d.Skill] = true
+
+		e := use_tbl_ent{allow: d.Allow, skill: d.Skill, time: d.Time}
+		if d.Poll {
+			e.poll = TRUE
+		}
+		var err error
+		if e.start, err = handler(name, "start", d.Start); err != nil {
+			return nil, nil, err
+		} else if e.finish, err = handler(name, "finish", d.Finish); err != nil {
+			return nil, nil, err
+		} else if e.interrupt, err = handler(name, "interrupt", d.Interrupt); err != nil {
+			return nil, nil, err
+		}
+		if !d.Disabled {
+			utbl = append(utbl, e)
+		}
+	}
+	utbl = append(utbl, use_tbl_ent{})
+
+	return ctbl, utbl, nil
+}
+
+// init_cmd_tbl builds the command and use tables from the compiled-in
+// definitions. They are part of the engine, so a bad one is a bug.
+func init_cmd_tbl() {
+	if len(cmd_tbl) != 0 {
+		return
+	}
+	defs, err := parse_command_defs(default_command_defs)
+	if err != nil {
+		panic(fmt.Sprintf("init_cmd_tbl: %v", err))
+	}
+	cmd_tbl, use_tbl, _ = build_cmd_tbl(defs)
+}
+
+// load_command_defs replaces the command and use tables with the
+// definitions in the lib directory, if there are any.
+func load_command_defs() error {
+	defs, err := read_command_defs(libdir)
+	if err != nil {
+		return err
+	} else if defs == nil {
+		return nil
+	}
+	cmd_tbl, use_tbl, _ = build_cmd_tbl(defs)
+	return nil
+}
+
+// bad_command_error explains why a unit's executing order couldn't be parsed.
+// If the game's definitions disable or leave out a command that a unit is
+// still executing, the command has to be enabled until the order finishes.
+func bad_command_error(who int, line string) error {
+	if w := strings.Fields(line); len(w) != 0 {
+		name := strings.ToLower(strings.TrimLeft(w[0], "&?"))
+		if defs, err := parse_command_defs(default_command_defs); err == nil {
+			for _, d := range defs.Commands {
+				for _, alias := range append([]string{d.Name}, d.Aliases...) {
+					if strings.ToLower(alias) == name {
+						return fmt.Errorf("%d: executing %q, but the game's %s disables or leaves out %q; enable it until the order finishes", who, line, COMMANDS_FILE, d.Name)
+					}
+				}
+			}
+		}
+	}
+	return fmt.Errorf("%d: bad cmd %q", who, line)
+}
+
+// read_command_defs returns the definitions from the lib directory,
+// or nil if it doesn't have any.
+func read_command_defs(dir string) (*CommandDefs, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	name := filepath.Join(dir, COMMANDS_FILE)
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defs, err := parse_command_defs(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return defs, nil
+}
+
+// CommandDefinitions returns the command definitions the game uses:
+// the ones in the lib directory if it has any, otherwise the defaults.
+// The definitions are checked as they would be when the game loads.
+func CommandDefinitions(options ...Option) (*CommandDefs, error) {
+	for _, option := range options {
+		if err := option(); err != nil {
+			return nil, fmt.Errorf("CommandDefinitions: %w", err)
+		}
+	}
+	defs, err := read_command_defs(libdir)
+	if err != nil {
+		return nil, fmt.Errorf("CommandDefinitions: %w", err)
+	} else if defs == nil {
+		if defs, err = parse_command_defs(default_command_defs); err != nil {
+			return nil, fmt.Errorf("CommandDefinitions: %w", err)
+		}
+	}
+	return defs, nil
+}
@@ -0,0 +1,543 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+/*
+ *  The handler registry.  Command definitions refer to their handlers
+ *  by name; a new command needs its handlers added here.
+ */
+
+// cmd_handlers are the start, finish, and interrupt handlers for
+// commands and skill uses.
+var cmd_handlers = map[string]func(*command) int{
+	"d_add_forts":                d_add_forts,
+	"d_add_iron_shoring":         d_add_iron_shoring,
+	"d_add_keels":                d_add_keels,
+	"d_add_ports":                d_add_ports,
+	"d_add_ram":                  d_add_ram,
+	"d_add_sails":                d_add_sails,
+	"d_add_wooden_shoring":       d_add_wooden_shoring,
+	"d_adv_med":                  d_adv_med,
+	"d_archery":                  d_archery,
+	"d_arrange_mugging":          d_arrange_mugging,
+	"d_assassinate":              d_assassinate,
+	"d_aura_blast":               d_aura_blast,
+	"d_banish_corpses":           d_banish_corpses,
+	"d_banish_undead":            d_banish_undead,
+	"d_bar_loc":                  d_bar_loc,
+	"d_bird_spy":                 d_bird_spy,
+	"d_bless_fort":               d_bless_fort,
+	"d_breed":                    d_breed,
+	"d_brew_death":               d_brew_death,
+	"d_brew_fiery":               d_brew_fiery,
+	"d_brew_heal":                d_brew_heal,
+	"d_brew_slave":               d_brew_slave,
+	"d_brew_weightlessness":      d_brew_weightlessness,
+	"d_bribe":                    d_bribe,
+	"d_build":                    d_build,
+	"d_calm_peasants":            d_calm_peasants,
+	"d_capture_beasts":           d_capture_beasts,
+	"d_cloak_creat":              d_cloak_creat,
+	"d_cloak_reg":                d_cloak_reg,
+	"d_collect":                  d_collect,
+	"d_conceal_arts":             d_conceal_arts,
+	"d_conceal_location":         d_conceal_location,
+	"d_conceal_mine":             d_conceal_mine,
+	"d_conceal_nation":           d_conceal_nation,
+	"d_create_dirt_golem":        d_create_dirt_golem,
+	"d_create_flesh_golem":       d_create_flesh_golem,
+	"d_create_holy_symbol":       d_create_holy_symbol,
+	"d_create_iron_golem":        d_create_iron_golem,
+	"d_create_mist":              d_create_mist,
+	"d_create_mithril":           d_create_mithril,
+	"d_create_ninja":             d_create_ninja,
+	"d_curse_noncreat":           d_curse_noncreat,
+	"d_death_fog":                d_death_fog,
+	"d_decrease_demand":          d_decrease_demand,
+	"d_decrease_supply":          d_decrease_supply,
+	"d_dedicate":                 d_dedicate,
+	"d_dedicate_temple":          d_dedicate_temple,
+	"d_dedicate_tower":           d_dedicate_tower,
+	"d_defense":                  d_defense,
+	"d_destroy_art":              d_destroy_art,
+	"d_detect_abil":              d_detect_abil,
+	"d_detect_arts":              d_detect_arts,
+	"d_detect_beasts":            d_detect_beasts,
+	"d_detect_gates":             d_detect_gates,
+	"d_detect_scry":              d_detect_scry,
+	"d_dispel_abil":              d_dispel_abil,
+	"d_dispel_region":            d_dispel_region,
+	"d_dissipate":                d_dissipate,
+	"d_draw_crowds":              d_draw_crowds,
+	"d_eat_dead":                 d_eat_dead,
+	"d_edge_of_kireus":           d_edge_of_kireus,
+	"d_enchant_guard":            d_enchant_guard,
+	"d_exhume":                   d_exhume,
+	"d_explore":                  d_explore,
+	"d_fierce_wind":              d_fierce_wind,
+	"d_find_all_hidden_features": d_find_all_hidden_features,
+	"d_find_food":                d_find_food,
+	"d_find_hidden_features":     d_find_hidden_features,
+	"d_find_rich":                d_find_rich,
+	"d_fly":                      d_fly,
+	"d_forge_art_x":              d_forge_art_x,
+	"d_forge_aura":               d_forge_aura,
+	"d_forge_palantir":           d_forge_palantir,
+	"d_form":                     d_form,
+	"d_fortify_castle":           d_fortify_castle,
+	"d_gather_holy_plant":        d_gather_holy_plant,
+	"d_generic_trap":             d_generic_trap,
+	"d_grow_pop":                 d_grow_pop,
+	"d_guard_loyalty":            d_guard_loyalty,
+	"d_heal":                     d_heal,
+	"d_hide":                     d_hide,
+	"d_hide_item":                d_hide_item,
+	"d_hide_money":               d_hide_money,
+	"d_hinder_med":               d_hinder_med,
+	"d_hinder_med_b":             d_hinder_med_b,
+	"d_improve_charisma":         d_improve_charisma,
+	"d_improve_fort":             d_improve_fort,
+	"d_improve_logging":          d_improve_logging,
+	"d_improve_mining":           d_improve_mining,
+	"d_improve_opium":            d_improve_opium,
+	"d_improve_quarrying":        d_improve_quarrying,
+	"d_improve_smithing":         d_improve_smithing,
+	"d_improve_taxes":            d_improve_taxes,
+	"d_incite":                   d_incite,
+	"d_increase_demand":          d_increase_demand,
+	"d_increase_supply":          d_increase_supply,
+	"d_instill_fanaticism":       d_instill_fanaticism,
+	"d_keep_savage":              d_keep_savage,
+	"d_keep_undead":              d_keep_undead,
+	"d_last_rites":               d_last_rites,
+	"d_lead_to_gold":             d_lead_to_gold,
+	"d_lightning":                d_lightning,
+	"d_locate_char":              d_locate_char,
+	"d_mage_menial":              d_mage_menial,
+	"d_make":                     d_make,
+	"d_meditate":                 d_meditate,
+	"d_mesmerize_crowd":          d_mesmerize_crowd,
+	"d_mine_gate_crystal":        d_mine_gate_crystal,
+	"d_mine_gold":                d_mine_gold,
+	"d_mine_iron":                d_mine_iron,
+	"d_mine_mithril":             d_mine_mithril,
+	"d_moat_castle":              d_moat_castle,
+	"d_move_attack":              d_move_attack,
+	"d_mutate_art":               d_mutate_art,
+	"d_notify_jump":              d_notify_jump,
+	"d_notify_unseal":            d_notify_unseal,
+	"d_obscure_art":              d_obscure_art,
+	"d_obscure_forest_trail":     d_obscure_forest_trail,
+	"d_obscure_mountain_trail":   d_obscure_mountain_trail,
+	"d_persuade_oath":            d_persuade_oath,
+	"d_petty_thief":              d_petty_thief,
+	"d_pillage":                  d_pillage,
+	"d_practice":                 d_practice,
+	"d_prep_ritual":              d_prep_ritual,
+	"d_proj_cast":                d_proj_cast,
+	"d_protect_mine":             d_protect_mine,
+	"d_quick_cast":               d_quick_cast,
+	"d_raise":                    d_raise,
+	"d_rally":                    d_rally,
+	"d_raze":                     d_raze,
+	"d_recruit_elves":            d_recruit_elves,
+	"d_rem_art_cloak":            d_rem_art_cloak,
+	"d_rem_seal":                 d_rem_seal,
+	"d_remove_forts":             d_remove_forts,
+	"d_remove_keels":             d_remove_keels,
+	"d_remove_ports":             d_remove_ports,
+	"d_remove_ram":               d_remove_ram,
+	"d_remove_sails":             d_remove_sails,
+	"d_renew_storm":              d_renew_storm,
+	"d_repair":                   d_repair,
+	"d_resurrect":                d_resurrect,
+	"d_reveal_arts":              d_reveal_arts,
+	"d_reveal_key":               d_reveal_key,
+	"d_reveal_mage":              d_reveal_mage,
+	"d_reveal_vision":            d_reveal_vision,
+	"d_sail":                     d_sail,
+	"d_save_proj":                d_save_proj,
+	"d_save_quick":               d_save_quick,
+	"d_scry_region":              d_scry_region,
+	"d_seal_gate":                d_seal_gate,
+	"d_seek":                     d_seek,
+	"d_seize_storm":              d_seize_storm,
+	"d_show_art_creat":           d_show_art_creat,
+	"d_show_art_reg":             d_show_art_reg,
+	"d_shroud_abil":              d_shroud_abil,
+	"d_shroud_region":            d_shroud_region,
+	"d_smuggle_goods":            d_smuggle_goods,
+	"d_smuggle_men":              d_smuggle_men,
+	"d_sneak":                    d_sneak,
+	"d_spy_inv":                  d_spy_inv,
+	"d_spy_lord":                 d_spy_lord,
+	"d_spy_skills":               d_spy_skills,
+	"d_strengthen_castle":        d_strengthen_castle,
+	"d_study":                    d_study,
+	"d_summon_fog":               d_summon_fog,
+	"d_summon_rain":              d_summon_rain,
+	"d_summon_wind":              d_summon_wind,
+	"d_swordplay":                d_swordplay,
+	"d_tap_health":               d_tap_health,
+	"d_teach":                    d_teach,
+	"d_teleport_item":            d_teleport_item,
+	"d_terrorize":                d_terrorize,
+	"d_torture":                  d_torture,
+	"d_trance":                   d_trance,
+	"d_unbar_loc":                d_unbar_loc,
+	"d_undead_lord":              d_undead_lord,
+	"d_unobscure_art":            d_unobscure_art,
+	"d_unseal_gate":              d_unseal_gate,
+	"d_urchin_spy":               d_urchin_spy,
+	"d_use":                      d_use,
+	"d_view_aura":                d_view_aura,
+	"d_vision_reg":               d_vision_reg,
+	"d_wait":                     d_wait,
+	"d_weaken_fort":              d_weaken_fort,
+	"d_widen_entrance":           d_widen_entrance,
+	"d_write_spell":              d_write_spell,
+	"i_collect":                  i_collect,
+	"i_make":                     i_make,
+	"i_repair":                   i_repair,
+	"i_sail":                     i_sail,
+	"i_use":                      i_use,
+	"i_wait":                     i_wait,
+	"v_accept":                   v_accept,
+	"v_add_forts":                v_add_forts,
+	"v_add_iron_shoring":         v_add_iron_shoring,
+	"v_add_item":                 v_add_item,
+	"v_add_keels":                v_add_keels,
+	"v_add_ports":                v_add_ports,
+	"v_add_ram":                  v_add_ram,
+	"v_add_sails":                v_add_sails,
+	"v_add_wooden_shoring":       v_add_wooden_shoring,
+	"v_admit":                    v_admit,
+	"v_adv_med":                  v_adv_med,
+	"v_appear_common":            v_appear_common,
+	"v_archery":                  v_archery,
+	"v_arrange_mugging":          v_arrange_mugging,
+	"v_assassinate":              v_assassinate,
+	"v_att_clear":                v_att_clear,
+	"v_attack_tactics":           v_attack_tactics,
+	"v_aura_blast":               v_aura_blast,
+	"v_aura_reflect":             v_aura_reflect,
+	"v_banish_corpses":           v_banish_corpses,
+	"v_banish_undead":            v_banish_undead,
+	"v_banner":                   v_banner,
+	"v_bar_loc":                  v_bar_loc,
+	"v_be":                       v_be,
+	"v_behind":                   v_behind,
+	"v_bird_spy":                 v_bird_spy,
+	"v_bless_follower":           v_bless_follower,
+	"v_bless_fort":               v_bless_fort,
+	"v_border":                   v_border,
+	"v_breed":                    v_breed,
+	"v_brew":                     v_brew,
+	"v_bribe":                    v_bribe,
+	"v_build":                    v_build,
+	"v_build_wagons":             v_build_wagons,
+	"v_buy":                      v_buy,
+	"v_capture_beasts":           v_capture_beasts,
+	"v_catch":                    v_catch,
+	"v_claim":                    v_claim,
+	"v_cloak_creat":              v_cloak_creat,
+	"v_cloak_reg":                v_cloak_reg,
+	"v_collect":                  v_collect,
+	"v_conceal_arts":             v_conceal_arts,
+	"v_conceal_location":         v_conceal_location,
+	"v_conceal_mine":             v_conceal_mine,
+	"v_conceal_nation":           v_conceal_nation,
+	"v_contact":                  v_contact,
+	"v_create_dirt_golem":        v_create_dirt_golem,
+	"v_create_flesh_golem":       v_create_flesh_golem,
+	"v_create_holy_symbol":       v_create_holy_symbol,
+	"v_create_iron_golem":        v_create_iron_golem,
+	"v_create_mithril":           v_create_mithril,
+	"v_create_ninja":             v_create_ninja,
+	"v_credit":                   v_credit,
+	"v_ct":                       v_ct,
+	"v_curse_noncreat":           v_curse_noncreat,
+	"v_death_fog":                v_death_fog,
+	"v_decrease_demand":          v_decrease_demand,
+	"v_decrease_supply":          v_decrease_supply,
+	"v_decree":                   v_decree,
+	"v_dedicate":                 v_dedicate,
+	"v_dedicate_temple":          v_dedicate_temple,
+	"v_dedicate_tower":           v_dedicate_tower,
+	"v_deep_identify":            v_deep_identify,
+	"v_defend":                   v_defend,
+	"v_defense":                  v_defense,
+	"v_defense_tactics":          v_defense_tactics,
+	"v_destroy_art":              v_destroy_art,
+	"v_detect_abil":              v_detect_abil,
+	"v_detect_arts":              v_detect_arts,
+	"v_detect_beasts":            v_detect_beasts,
+	"v_detect_gates":             v_detect_gates,
+	"v_detect_scry":              v_detect_scry,
+	"v_die":                      v_die,
+	"v_direct_storm":             v_direct_storm,
+	"v_discard":                  v_discard,
+	"v_dispel_abil":              v_dispel_abil,
+	"v_dispel_region":            v_dispel_region,
+	"v_dissipate":                v_dissipate,
+	"v_draw_crowds":              v_draw_crowds,
+	"v_dump":                     v_dump,
+	"v_east":                     v_east,
+	"v_eat_dead":                 v_eat_dead,
+	"v_edge_of_kireus":           v_edge_of_kireus,
+	"v_emote":                    v_emote,
+	"v_enchant_guard":            v_enchant_guard,
+	"v_enter":                    v_enter,
+	"v_execute":                  v_execute,
+	"v_exhume":                   v_exhume,
+	"v_exit":                     v_exit,
+	"v_explore":                  v_explore,
+	"v_fee":                      v_fee,
+	"v_ferry":                    v_ferry,
+	"v_fierce_wind":              v_fierce_wind,
+	"v_fight_to_death":           v_fight_to_death,
+	"v_find_food":                v_find_food,
+	"v_find_forest_trail":        v_find_forest_trail,
+	"v_find_hidden_features":     v_find_hidden_features,
+	"v_find_mountain_trail":      v_find_mountain_trail,
+	"v_find_rich":                v_find_rich,
+	"v_fish":                     v_fish,
+	"v_fix":                      v_fix,
+	"v_fix2":                     v_fix2,
+	"v_flag":                     v_flag,
+	"v_fly":                      v_fly,
+	"v_forced_march":             v_forced_march,
+	"v_forge_art_x":              v_forge_art_x,
+	"v_forge_aura":               v_forge_aura,
+	"v_forge_palantir":           v_forge_palantir,
+	"v_forget":                   v_forget,
+	"v_form":                     v_form,
+	"v_format":                   v_format,
+	"v_fortify_castle":           v_fortify_castle,
+	"v_fullname":                 v_fullname,
+	"v_garrison":                 v_garrison,
+	"v_gather_holy_plant":        v_gather_holy_plant,
+	"v_generic_trap":             v_generic_trap,
+	"v_get":                      v_get,
+	"v_give":                     v_give,
+	"v_grow_pop":                 v_grow_pop,
+	"v_guard":                    v_guard,
+	"v_heal":                     v_heal,
+	"v_hide":                     v_hide,
+	"v_hide_item":                v_hide_item,
+	"v_hide_money":               v_hide_money,
+	"v_hinder_med":               v_hinder_med,
+	"v_hinder_med_b":             v_hinder_med_b,
+	"v_honor":                    v_honor,
+	"v_hostile":                  v_hostile,
+	"v_identify":                 v_identify,
+	"v_implicit":                 v_implicit,
+	"v_improve_charisma":         v_improve_charisma,
+	"v_improve_fort":             v_improve_fort,
+	"v_improve_logging":          v_improve_logging,
+	"v_improve_mining":           v_improve_mining,
+	"v_improve_opium":            v_improve_opium,
+	"v_improve_quarrying":        v_improve_quarrying,
+	"v_improve_smithing":         v_improve_smithing,
+	"v_incite":                   v_incite,
+	"v_increase_demand":          v_increase_demand,
+	"v_increase_supply":          v_increase_supply,
+	"v_invent":                   v_invent,
+	"v_jump_gate":                v_jump_gate,
+	"v_keep_savage":              v_keep_savage,
+	"v_keep_undead":              v_keep_undead,
+	"v_kill":                     v_kill,
+	"v_know":                     v_know,
+	"v_last_rites":               v_last_rites,
+	"v_lead_to_gold":             v_lead_to_gold,
+	"v_lightning":                v_lightning,
+	"v_listcmds":                 v_listcmds,
+	"v_locate_char":              v_locate_char,
+	"v_look":                     v_look,
+	"v_lore":                     v_lore,
+	"v_los":                      v_los,
+	"v_mage_menial":              v_mage_menial,
+	"v_make":                     v_make,
+	"v_make_artifact":            v_make_artifact,
+	"v_makeloc":                  v_makeloc,
+	"v_mallorn":                  v_mallorn,
+	"v_maxpay":                   v_maxpay,
+	"v_meditate":                 v_meditate,
+	"v_message":                  v_message,
+	"v_mine_gate_crystal":        v_mine_gate_crystal,
+	"v_mine_gold":                v_mine_gold,
+	"v_mine_iron":                v_mine_iron,
+	"v_mine_mithril":             v_mine_mithril,
+	"v_moat_castle":              v_moat_castle,
+	"v_move_attack":              v_move_attack,
+	"v_mutate_art":               v_mutate_art,
+	"v_name":                     v_name,
+	"v_nationlist":               v_nationlist,
+	"v_neutral":                  v_neutral,
+	"v_north":                    v_north,
+	"v_notab":                    v_notab,
+	"v_notify_jump":              v_notify_jump,
+	"v_notify_unseal":            v_notify_unseal,
+	"v_oath":                     v_oath,
+	"v_obscure_art":              v_obscure_art,
+	"v_obscure_forest_trail":     v_obscure_forest_trail,
+	"v_obscure_mountain_trail":   v_obscure_mountain_trail,
+	"v_opium":                    v_opium,
+	"v_pay":                      v_pay,
+	"v_personal_fight_to_death":  v_personal_fight_to_death,
+	"v_persuade_oath":            v_persuade_oath,
+	"v_petty_thief":              v_petty_thief,
+	"v_pillage":                  v_pillage,
+	"v_poof":                     v_poof,
+	"v_post":                     v_post,
+	"v_postproc":                 v_postproc,
+	"v_prac_control":             v_prac_control,
+	"v_prac_protect":             v_prac_protect,
+	"v_practice":                 v_practice,
+	"v_prep_ritual":              v_prep_ritual,
+	"v_press":                    v_press,
+	"v_proj_cast":                v_proj_cast,
+	"v_promote":                  v_promote,
+	"v_proselytise":              v_proselytise,
+	"v_protect_mine":             v_protect_mine,
+	"v_quarry":                   v_quarry,
+	"v_quick_cast":               v_quick_cast,
+	"v_quit":                     v_quit,
+	"v_raise":                    v_raise,
+	"v_raise_corpses":            v_raise_corpses,
+	"v_rally":                    v_rally,
+	"v_raze":                     v_raze,
+	"v_reclaim":                  v_reclaim,
+	"v_recruit":                  v_recruit,
+	"v_recruit_elves":            v_recruit_elves,
+	"v_relore":                   v_relore,
+	"v_rem_art_cloak":            v_rem_art_cloak,
+	"v_rem_seal":                 v_rem_seal,
+	"v_remove_forts":             v_remove_forts,
+	"v_remove_keels":             v_remove_keels,
+	"v_remove_ports":             v_remove_ports,
+	"v_remove_ram":               v_remove_ram,
+	"v_remove_sails":             v_remove_sails,
+	"v_renew_storm":              v_renew_storm,
+	"v_repair":                   v_repair,
+	"v_resurrect":                v_resurrect,
+	"v_reveal_arts":              v_reveal_arts,
+	"v_reveal_key":               v_reveal_key,
+	"v_reveal_mage":              v_reveal_mage,
+	"v_reveal_vision":            v_reveal_vision,
+	"v_reverse_jump":             v_reverse_jump,
+	"v_rumor":                    v_rumor,
+	"v_sail":                     v_sail,
+	"v_save":                     v_save,
+	"v_save_proj":                v_save_proj,
+	"v_save_quick":               v_save_quick,
+	"v_scry_region":              v_scry_region,
+	"v_seal_gate":                v_seal_gate,
+	"v_see_all":                  v_see_all,
+	"v_seed":                     v_seed,
+	"v_seedmarket":               v_seedmarket,
+	"v_seedorc":                  v_seedorc,
+	"v_seek":                     v_seek,
+	"v_seize_storm":              v_seize_storm,
+	"v_sell":                     v_sell,
+	"v_shipbuild":                v_shipbuild,
+	"v_show_art_creat":           v_show_art_creat,
+	"v_show_art_reg":             v_show_art_reg,
+	"v_shroud_abil":              v_shroud_abil,
+	"v_shroud_region":            v_shroud_region,
+	"v_skills":                   v_skills,
+	"v_smuggle_goods":            v_smuggle_goods,
+	"v_smuggle_men":              v_smuggle_men,
+	"v_sneak":                    v_sneak,
+	"v_south":                    v_south,
+	"v_split":                    v_split,
+	"v_spy_inv":                  v_spy_inv,
+	"v_spy_lord":                 v_spy_lord,
+	"v_spy_skills":               v_spy_skills,
+	"v_stack":                    v_stack,
+	"v_stop":                     v_stop,
+	"v_strengthen_castle":        v_strengthen_castle,
+	"v_study":                    v_study,
+	"v_sub_item":                 v_sub_item,
+	"v_summon_fog":               v_summon_fog,
+	"v_summon_rain":              v_summon_rain,
+	"v_summon_savage":            v_summon_savage,
+	"v_summon_wind":              v_summon_wind,
+	"v_swear":                    v_swear,
+	"v_swordplay":                v_swordplay,
+	"v_take_pris":                v_take_pris,
+	"v_tap_health":               v_tap_health,
+	"v_tax":                      v_tax,
+	"v_teach":                    v_teach,
+	"v_teleport":                 v_teleport,
+	"v_teleport_item":            v_teleport_item,
+	"v_tell":                     v_tell,
+	"v_terrorize":                v_terrorize,
+	"v_think":                    v_think,
+	"v_times":                    v_times,
+	"v_torture":                  v_torture,
+	"v_trance":                   v_trance,
+	"v_unbar_loc":                v_unbar_loc,
+	"v_undead_lord":              v_undead_lord,
+	"v_ungarrison":               v_ungarrison,
+	"v_unload":                   v_unload,
+	"v_unobscure_art":            v_unobscure_art,
+	"v_unseal_gate":              v_unseal_gate,
+	"v_unstack":                  v_unstack,
+	"v_urchin_spy":               v_urchin_spy,
+	"v_use":                      v_use,
+	"v_use_beasts":               v_use_beasts,
+	"v_use_cs":                   v_use_cs,
+	"v_use_train_riding":         v_use_train_riding,
+	"v_use_train_war":            v_use_train_war,
+	"v_view_aura":                v_view_aura,
+	"v_vision_reg":               v_vision_reg,
+	"v_wait":                     v_wait,
+	"v_weaken_fort":              v_weaken_fort,
+	"v_west":                     v_west,
+	"v_widen_entrance":           v_widen_entrance,
+	"v_wood":                     v_wood,
+	"v_write_spell":              v_write_spell,
+	"v_xyzzy":                    v_xyzzy,
+	"v_yew":                      v_yew,
+}
+
+// cmd_comments annotate an order in the acknowledgement.
+var cmd_comments = map[string]func(*command) string{
+	"accept_comment":   accept_comment,
+	"admit_comment":    admit_comment,
+	"attack_comment":   attack_comment,
+	"attitude_comment": attitude_comment,
+	"buy_comment":      buy_comment,
+	"catch_comment":    catch_comment,
+	"collect_comment":  collect_comment,
+	"default_comment":  default_comment,
+	"drop_comment":     drop_comment,
+	"get_comment":      get_comment,
+	"give_comment":     give_comment,
+	"move_comment":     move_comment,
+	"study_comment":    study_comment,
+}
+
+// cmd_checks check an order when it is eaten.
+var cmd_checks = map[string]func(*command){
+	"admit_check": admit_check,
+	"quit_check":  quit_check,
+}
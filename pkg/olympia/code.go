@@ -234,7 +234,7 @@ func display_name(n int) string {
 func display_kind(n int) string {
 	switch subkind(n) {
 	case sub_city:
-		if is_port_city_where(n) != FALSE {
+		if is_port_city(n) {
 			return "port city"
 		}
 		return "city"
@@ -409,6 +409,9 @@ func remove_next_chain(n int) {
 		bx[i].x_next_kind = bx[n].x_next_kind
 	}
 	bx[n].x_next_kind = 0
+	if next_chain.cache_last == n {
+		next_chain.cache_last, next_chain.cache_kind = 0, 0
+	}
 }
 
 func add_sub_chain(n int) {
@@ -465,6 +468,9 @@ func remove_sub_chain(n int) {
 	}
 
 	bx[n].x_next_sub = 0
+	if sub_chain.cache_last == n {
+		sub_chain.cache_last, sub_chain.cache_kind = 0, -1
+	}
 }
 
 func delete_box(n int) {
@@ -477,6 +483,19 @@ func delete_box(n int) {
 	}
 }
 
+// free_box deletes the box, if it hasn't been already, and removes it
+// from the table so that its number can be allocated again.  Nothing
+// may refer to the box afterwards.
+func free_box(n int) {
+	if bx[n] == nil {
+		return
+	}
+	if k := bx[n].kind; k != T_deleted && k != T_deadchar {
+		delete_box(n)
+	}
+	delete(bx, n)
+}
+
 func change_box_kind(n int, kind int) {
 	remove_next_chain(n)
 	bx[n].kind = schar(kind)
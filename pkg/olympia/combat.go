@@ -20,6 +20,7 @@
 package olympia
 
 import (
+	"github.com/mdhender/golympia/pkg/battle"
 	"log"
 	"os"
 	"reflect"
@@ -47,6 +48,9 @@ const (
 
 var (
 	combat_def_loc        = 0      /* Where the defenders are. */
+	combat_result         = 0      /* A_WON, B_WON or TIE for the last battle */
+	combat_side_a         []*fight /* The sides of the last battle, */
+	combat_side_b         []*fight /* after the dead are deducted */
 	combat_rain           = false  /* bad for archers */
 	combat_sea            = false  /* naval combat */
 	combat_swampy         = false  /* bad for horses */
@@ -1440,6 +1444,7 @@ func do_magic_combat_attack(l_a []*fight, f *fight, l_b []*fight, skill int) int
 			sum++
 		}
 		out(combat_pl, "%s uses a lightning bolt, killing %d.", box_name(f.unit), sum)
+		battle_spell(f, skill, sum)
 		print_special_banner()
 		if sum != 0 {
 			wout(VECT, "    %s uses a lightning bolt, striking %s.", box_name(f.unit), nice_num(sum))
@@ -1472,6 +1477,7 @@ func do_magic_combat_attack(l_a []*fight, f *fight, l_b []*fight, skill int) int
 			}
 		}
 		out(combat_pl, "%s uses a fireball, killing %d.", box_name(f.unit), sum)
+		battle_spell(f, skill, sum)
 		print_special_banner()
 		wout(VECT, "    %s casts a fireball and engulfs %s victims!", box_name(f.unit), nice_num(sum))
 		return sum
@@ -1493,6 +1499,7 @@ func do_magic_combat_attack(l_a []*fight, f *fight, l_b []*fight, skill int) int
 		}
 		print_special_banner()
 		wout(VECT, "    %s opens a mana-draining gate!", box_name(f.unit))
+		battle_spell(f, skill, sum)
 		if sum != 0 {
 			out(combat_pl, "%s drains %d magicians of aura.", box_name(f.unit), sum)
 			add_aura(f.unit, sum/10)
@@ -1503,6 +1510,7 @@ func do_magic_combat_attack(l_a []*fight, f *fight, l_b []*fight, skill int) int
 		if !charge_aura(f.unit, skill_piety(skill)) {
 			return 0
 		}
+		battle_spell(f, skill, 0)
 		for i := 0; i < len(l_a) && max_raise > 0; i++ {
 			if l_a[i].kind != FK_noble && l_a[i].num < l_a[i].sav_num {
 				num_to_raise := l_a[i].sav_num - l_a[i].num
@@ -1515,6 +1523,7 @@ func do_magic_combat_attack(l_a []*fight, f *fight, l_b []*fight, skill int) int
 				print_special_banner()
 				wout(VECT, "    %s raises %s %s.", box_name(f.unit), nice_num(num_to_raise), plural_item_name(l_a[i].kind, num_to_raise))
 				out(combat_pl, "%s raises %d %s.", box_name(f.unit), num_to_raise, box_name(l_a[i].unit))
+				battle_event(battle.Raise, f, l_a[i], num_to_raise)
 			}
 		}
 		return (10 - max_raise)
@@ -1529,6 +1538,7 @@ func do_magic_combat_attack(l_a []*fight, f *fight, l_b []*fight, skill int) int
 		print_special_banner()
 		wout(VECT, "    %s foresees the next moments of battle.", box_name(f.unit))
 		out(combat_pl, "%s foresees the tide of battle.", box_name(f.unit))
+		battle_spell(f, skill, 0)
 		return 1
 
 	default:
@@ -1568,6 +1578,7 @@ func do_fiery_attack(l_a []*fight, f *fight, l_b []*fight) int {
 			out(VECT, "%s throws a potion of Fiery Death!", box_name(f.unit))
 			if rnd(1, 100) < 6 {
 				out(combat_pl, "The potion lands harmlessly among the defenders.")
+				battle_special(battle.Potion, f, e.item, 0)
 				break
 			}
 			for i := 0; i < 5; i++ {
@@ -1584,6 +1595,7 @@ func do_fiery_attack(l_a []*fight, f *fight, l_b []*fight) int {
 			} else {
 				out(combat_pl, "The explosion does no damage.")
 			}
+			battle_special(battle.Potion, f, e.item, sum)
 			break
 		}
 	}
@@ -1762,7 +1774,7 @@ func real_resolve_hit(l_a []*fight, f *fight, l_b []*fight, g *fight, man int, t
 	 *
 	 *  Only if we have "antipathy" turned on.
 	 */
-	if options.mp_antipathy &&
+	if game_rules.MPAntipathy &&
 		contains_priest(l_a) && contains_mu_or_undead(l_b) {
 		/*
 		 *  Note that +10 is actually +10*100 == +1000
@@ -1900,7 +1912,7 @@ func real_resolve_hit(l_a []*fight, f *fight, l_b []*fight, g *fight, man int, t
 	 *  the bless won't work if the defender has undead or is a magician.
 	 *
 	 */
-	if get_effect(g.unit, ef_defense, 0, 0) != 0 && (!options.mp_antipathy || FALSE == contains_mu_undead(g.unit)) {
+	if get_effect(g.unit, ef_defense, 0, 0) != 0 && (!game_rules.MPAntipathy || FALSE == contains_mu_undead(g.unit)) {
 		defense += get_effect(g.unit, ef_defense, 0, 0) * 100
 	}
 
@@ -2001,10 +2013,12 @@ func real_resolve_hit(l_a []*fight, f *fight, l_b []*fight, g *fight, man int, t
 	n := rnd(1, attack+defense)
 	if n > attack {
 		out(combat_pl, "    %s.%d failed to hit %s.%d (%d/%d)", box_code_less(f.unit), f.kind, box_code_less(g.unit), g.kind, attack, attack+defense)
+		battle_attack(f, g, false)
 		return false
 	}
 
 	out(combat_pl, "    %s.%d hit %s.%d (%d/%d)", box_code_less(f.unit), f.kind, box_code_less(g.unit), g.kind, attack, attack+defense)
+	battle_attack(f, g, true)
 
 	decrement_num(l_b, f, g) /* f scores against g */
 	return true
@@ -2245,6 +2259,7 @@ func do_special_attack(l_a []*fight, f *fight, l_b []*fight) int {
 			}
 		}
 		out(combat_pl, "%s breathes dragonfire, killing %d.", box_name(f.unit), sum)
+		battle_special(battle.Breath, f, f.kind, sum)
 		print_special_banner()
 		if f.num > 1 {
 			wout(VECT, "    %s's dragons release their dragonfire, engulfing %s!", box_name(f.unit), nice_num(sum))
@@ -2292,6 +2307,7 @@ func special_attack(l_a []*fight, i int, l_b []*fight, type_ int) int {
 	if f.kind == FK_noble && count_any_real(f.unit, false, false) == 1 && has_skill(f.unit, sk_blinding_speed) != 0 && has_item(f.unit, item_warmount) > 0 && f.behind == 0 {
 		print_special_banner()
 		out(VECT, "      %s dashes out and attacks!", box_name(f.unit))
+		battle_special(battle.Dash, f, 0, 0)
 		num_defend := total_defenders(l_b, l_a, MELEE)
 		assert(num_defend > 0)
 		man := rnd(1, num_defend)
@@ -2345,6 +2361,7 @@ func resolve_dead(l_a []*fight) {
 			g.tmp_num = g.num
 		}
 		if g.kind == FK_fort {
+			battle_losses(g, g.tmp_num)
 			g.num -= g.tmp_num
 			wout(VECT, "      %s takes %s point%s damage.", box_name(g.unit),
 				nice_num(g.tmp_num),
@@ -2368,6 +2385,7 @@ func resolve_dead(l_a []*fight) {
 			if has_skill(g.unit, sk_avoid_wounds) != 0 && count_any(g.unit, FALSE, FALSE) == 1 {
 				damage -= (damage * min(2*skill_exp(g.unit, sk_avoid_wounds), 80)) / 100
 			}
+			battle_losses(g, min(damage, g.new_health))
 			g.new_health = max(g.new_health-damage, 0)
 			/*
 			 *  Now (possibly) adjust g.num to take the noble out of the
@@ -2382,6 +2400,7 @@ func resolve_dead(l_a []*fight) {
 				wout(VECT, "      %s heroically continues to fight!", box_name(g.unit))
 			}
 		} else {
+			battle_losses(g, g.tmp_num)
 			g.num -= g.tmp_num
 			wout(VECT, "      %s loses %s %s.", box_name(g.unit), nice_num(g.tmp_num), plural_item_box(g.kind, g.tmp_num))
 			/*
@@ -2401,6 +2420,7 @@ func resolve_dead(l_a []*fight) {
 				}
 				if brk != 0 {
 					wout(VECT, "        %d %s break and leave the battle!", brk, plural_item_box(g.kind, brk))
+					battle_event(battle.Flee, nil, g, brk)
 					g.num -= brk
 				}
 			}
@@ -2813,6 +2833,7 @@ func take_prisoners(winner int, l []*fight) {
 	for i := 0; i < len(l); i++ {
 		if l[i].prisoner != 0 && l[i].new_health > 0 {
 			take_prisoner(winner, l[i].unit)
+			battle_prisoner(l[i].unit, winner)
 		}
 	}
 }
@@ -3319,6 +3340,8 @@ func run_combat(lap, lbp *[]*fight) int {
 	defense_side = l_b
 	wout(VECT, " ")
 	if total_attackers(l_b, l_a, MISSILE) > 0 {
+		battle_round(round)
+		battle_phase(battle.Missile)
 		wout(VECT, "Initial missile round for the defense (%s):", box_name(lead_char(l_b)))
 		tmp := attack_round(l_b, l_a, MISSILE)
 		wout(VECT, "    %s missile%s hit%s!", cap_(nice_num(tmp)), or_string(tmp > 1, "s", ""), or_string(tmp > 1, "", "s"))
@@ -3332,6 +3355,7 @@ func run_combat(lap, lbp *[]*fight) int {
 
 	for num_a > thresh_a && num_b > thresh_b {
 		round++
+		battle_round(round)
 		out(combat_pl, "Combat round: %d", round)
 		wout(VECT, "Combat round: %s", nice_num(round))
 
@@ -3358,6 +3382,7 @@ func run_combat(lap, lbp *[]*fight) int {
 			l_a, l_b = *lap, *lbp
 
 			ready_fight_list(l_b)
+			battle_join(l_b)
 			wout(combat_pl, "  Old threshold: %d.", thresh_b)
 			thresh_b = special_total_combat_sum(l_b) * char_break(lead_b) / 100
 			wout(combat_pl, "  New threshold w/ allies: %d.", thresh_b)
@@ -3376,6 +3401,7 @@ func run_combat(lap, lbp *[]*fight) int {
 		 *
 		 */
 		special_attack_banner = false
+		battle_phase(battle.Special)
 		if rnd(1, 2) == 1 {
 			out(combat_pl, "  Special Phase: %s", box_name(l_a[0].unit))
 			_ = special_attacks(l_a, l_b, SPECIAL)
@@ -3397,6 +3423,7 @@ func run_combat(lap, lbp *[]*fight) int {
 		}
 
 		// missile weapons.
+		battle_phase(battle.Missile)
 		first = true
 		if tmp := attack_round(l_a, l_b, MISSILE); tmp != NO_ATTACKS {
 			if first {
@@ -3423,6 +3450,7 @@ func run_combat(lap, lbp *[]*fight) int {
 		}
 
 		// melee weapons.
+		battle_phase(battle.Melee)
 		wout(VECT, "  Melee phase:")
 		if tmp := attack_round(l_a, l_b, MELEE); tmp != NO_ATTACKS {
 			wout(VECT, "    %s's forces hit %s time%s.", just_name(lead_char(l_a)), nice_num(tmp), or_string(tmp > 1, "s", ""))
@@ -3527,6 +3555,7 @@ func combat_top(lap, lbp *[]*fight) bool {
 	assert(len(l_b) > 0)
 
 	combat_banner(l_a, l_b)
+	battle_begin(l_a, l_b)
 
 	var result int
 	result = run_combat(lap, lbp)
@@ -3559,6 +3588,9 @@ func combat_top(lap, lbp *[]*fight) bool {
 
 	show_to_garrison = false
 
+	battle_end(result, l_a, l_b)
+	combat_result, combat_side_a, combat_side_b = result, l_a, l_b
+
 	return result == A_WON
 }
 
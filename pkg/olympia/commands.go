@@ -0,0 +1,102 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+// Command is the json version of the command a unit is executing.
+// The command line is parsed again when the box is loaded;
+// the argument values are then restored from Args.
+type Command struct {
+	Line          string `json:"line"`                     // original command line
+	Args          []int  `json:"args,omitempty"`           // values of the a...i arguments
+	State         int    `json:"state,omitempty"`          // LOAD, RUN, ERROR, DONE
+	Wait          int    `json:"wait,omitempty"`           // time until completion
+	Status        int    `json:"status,omitempty"`         // success or failure
+	UseSkill      int    `json:"use-skill,omitempty"`      // skill we are using, if any
+	UseExp        int    `json:"use-exp,omitempty"`        // experience level at using this skill
+	DaysExecuting int    `json:"days-executing,omitempty"` // how long has this command been running
+	Poll          int    `json:"poll,omitempty"`           // call finish routine each day?
+	Pri           int    `json:"pri,omitempty"`            // command priority or precedence
+	InhibitFinish bool   `json:"inhibit-finish,omitempty"` // don't call d_xxx
+	SecondWait    int    `json:"second-wait,omitempty"`    // delay resulting from auto attacks
+}
+
+// ToCommand returns nil if the unit isn't executing a command.
+func (c *command) ToCommand() *Command {
+	if c == nil || c.cmd == 0 {
+		return nil
+	}
+	return &Command{
+		Line:          c.line,
+		Args:          []int{c.a, c.b, c.c, c.d, c.e, c.f, c.g, c.h, c.i},
+		State:         c.state,
+		Wait:          c.wait,
+		Status:        c.status,
+		UseSkill:      c.use_skill,
+		UseExp:        c.use_exp,
+		DaysExecuting: c.days_executing,
+		Poll:          c.poll,
+		Pri:           c.pri,
+		InhibitFinish: c.inhibit_finish,
+		SecondWait:    c.second_wait,
+	}
+}
+
+func (c *Command) toBox(who int) (*command, error) {
+	if c == nil {
+		return nil, nil
+	}
+	p := &command{who: who}
+	if !oly_parse_cmd(p, []byte(c.Line)) {
+		return nil, bad_command_error(who, c.Line)
+	}
+	for i, v := range c.Args {
+		switch i {
+		case 0:
+			p.a = v
+		case 1:
+			p.b = v
+		case 2:
+			p.c = v
+		case 3:
+			p.d = v
+		case 4:
+			p.e = v
+		case 5:
+			p.f = v
+		case 6:
+			p.g = v
+		case 7:
+			p.h = v
+		case 8:
+			p.i = v
+		}
+	}
+	p.state = c.State
+	p.wait = c.Wait
+	p.status = c.Status
+	p.use_skill = c.UseSkill
+	p.use_exp = c.UseExp
+	p.days_executing = c.DaysExecuting
+	p.poll = c.Poll
+	p.pri = c.Pri
+	p.inhibit_finish = c.InhibitFinish
+	p.second_wait = c.SecondWait
+	return p, nil
+}
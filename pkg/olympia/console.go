@@ -0,0 +1,202 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ConsoleInput supplies the GM console with lines of input.
+// ReadLine returns io.EOF when there is no more input.
+type ConsoleInput interface {
+	ReadLine(prompt string) (string, error)
+}
+
+var console_dry_run = false // only save the database when the GM asks for it
+
+// WithDryRun discards the changes made in the console unless the GM issues a save.
+func WithDryRun(flag bool) func() error {
+	return func() error {
+		console_dry_run = flag
+		return nil
+	}
+}
+
+// GMConsole loads the database and runs immediate commands as the GM until
+// the input runs out or the GM quits. The database is saved when the console
+// exits, unless WithDryRun is set, in which case it is only saved by the
+// console's "save" command.
+func GMConsole(input ConsoleInput, options ...Option) (err error) {
+	defer recover_turn("GMConsole", &err)
+	defer unlock_tag()
+
+	if input == nil {
+		return fmt.Errorf("GMConsole: missing input")
+	} else if err := start_turn(options...); err != nil {
+		return fmt.Errorf("GMConsole: %w", err)
+	}
+
+	if err := immed_loop(input); err != nil {
+		return fmt.Errorf("GMConsole: %w", err)
+	}
+
+	if console_dry_run {
+		log.Printf("GMConsole: dry run, unsaved changes discarded\n")
+		return nil
+	} else if err := console_save(); err != nil {
+		return fmt.Errorf("GMConsole: %w", err)
+	}
+
+	return nil
+}
+
+// ConsoleComplete returns the console commands, command names, and entity codes
+// that start with the prefix. It is meant for tab completion while the console is running.
+func ConsoleComplete(prefix string) []string {
+	prefix = strings.ToLower(prefix)
+	seen := make(map[string]bool)
+	var list []string
+	add := func(s string) {
+		if s != "" && !seen[s] && strings.HasPrefix(strings.ToLower(s), prefix) {
+			seen[s] = true
+			list = append(list, s)
+		}
+	}
+
+	add("save")
+	add("quit")
+	for _, e := range cmd_tbl {
+		add(e.name)
+	}
+	if prefix != "" {
+		for _, n := range sorted_box_ids() {
+			add(box_code_less(n))
+		}
+	}
+	sort.Strings(list)
+	return list
+}
+
+// immed_commands runs immediate commands from standard input.
+func immed_commands() {
+	if err := immed_loop(&stdin_input{}); err != nil {
+		log.Printf("immed_commands: %v\n", err)
+	}
+}
+
+// immed_loop reads and runs immediate commands as the GM.
+// Blank lines and lines starting with # are ignored.
+// The console handles "save" and "quit" itself.
+func immed_loop(input ConsoleInput) error {
+	log.Printf("Olympia immediate mode\n")
+
+	immediate = gm_player
+	out(immediate, "You are now %s.", box_name(immediate))
+	init_locs_touched()
+
+	show_day = true
+	defer func() {
+		show_day = false
+	}()
+
+	for {
+		line, err := input.ReadLine(fmt.Sprintf("%s> ", box_code_less(immediate)))
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		line = strings.TrimSpace(line)
+		switch strings.ToLower(line) {
+		case "":
+			continue
+		case "quit", "exit":
+			return nil
+		case "save":
+			if err := console_save(); err != nil {
+				log.Printf("save: %v\n", err)
+			} else {
+				log.Printf("save: database saved\n")
+			}
+			continue
+		}
+		if line[0] == '#' {
+			continue
+		}
+
+		c := p_command(immediate)
+		c.who = immediate
+		c.wait = 0
+
+		if !oly_parse(c, []byte(line)) {
+			log.Printf("Unrecognized command.\n")
+			continue
+		}
+		if c.fuzzy {
+			out(immediate, "(assuming you meant '%s')", cmd_tbl[c.cmd].name)
+		}
+
+		c.pri = cmd_tbl[c.cmd].pri
+		c.wait = cmd_tbl[c.cmd].time
+		c.poll = cmd_tbl[c.cmd].poll
+		c.days_executing = 0
+		c.state = LOAD
+
+		do_command(c)
+
+		for c.state == RUN {
+			evening = true
+			finish_command(c)
+			evening = false
+			olytime_increment(&sysclock)
+		}
+	}
+
+	log.Println("")
+
+	return nil
+}
+
+// console_save checks and saves the database.
+func console_save() error {
+	if err := check_db(); err != nil {
+		return err
+	}
+	return save_db()
+}
+
+// stdin_input reads console input from standard input without line editing.
+type stdin_input struct{}
+
+func (si *stdin_input) ReadLine(prompt string) (string, error) {
+	log.Printf("%s", prompt)
+	line := getlin(os.Stdin)
+	if line == nil {
+		return "", io.EOF
+	}
+	return string(line), nil
+}
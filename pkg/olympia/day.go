@@ -970,8 +970,8 @@ func increment_current_piety() {
 		 */
 		wout(priests[0], "%s honors you as High Priest of his worshippers!", god_name(religion))
 		wout(priests[0], "%s blesses you with great piety and the protection of angels.", god_name(religion))
-		add_piety(priests[0], options.top_piety, true)
-		wout(priests[0], "You gain %s piety.", nice_num(options.top_piety))
+		add_piety(priests[0], game_rules.Piety.Top, true)
+		wout(priests[0], "You gain %s piety.", nice_num(game_rules.Piety.Top))
 		gen_item(priests[0], item_angel, 10)
 		wout(priests[0], "%s sends you 10 angels.", god_name(religion))
 		rp_relig_skill(religion).high_priest = priests[0]
@@ -983,8 +983,8 @@ func increment_current_piety() {
 		for i := 1; i < len(priests) && i < 3; i++ {
 			wout(priests[i], "%s honors you as a great Bishop of the faithful!", god_name(religion))
 			wout(priests[i], "%s blesses you with great piety and the protection of angels.", god_name(religion))
-			add_piety(priests[i], options.middle_piety, true)
-			wout(priests[i], "You gain %s piety.", nice_num(options.middle_piety))
+			add_piety(priests[i], game_rules.Piety.Middle, true)
+			wout(priests[i], "You gain %s piety.", nice_num(game_rules.Piety.Middle))
 			gen_item(priests[i], item_angel, 5)
 			wout(priests[i], "%s sends you 5 angels.", god_name(religion))
 			rp_relig_skill(religion).bishops[i-1] = priests[i]
@@ -995,8 +995,8 @@ func increment_current_piety() {
 		 *
 		 */
 		for i := 3; i < (3 + (len(priests)-2)/2); i++ {
-			wout(priests[i], "As a faithful priest, %s blesses you with %s piety.", god_name(religion), nice_num(options.bottom_piety))
-			add_piety(priests[i], options.bottom_piety, true)
+			wout(priests[i], "As a faithful priest, %s blesses you with %s piety.", god_name(religion), nice_num(game_rules.Piety.Bottom))
+			add_piety(priests[i], game_rules.Piety.Bottom, true)
 		}
 
 		/*
@@ -1200,19 +1200,11 @@ func noncreator_curse_erode() {
 			if loyal_kind(who) == LOY_oath {
 				continue
 			}
-			/*
-			 *  NOTYET:  must fix
-			 */
+			delta_loyalty(who, -(im.CurseLoyalty), true)
 
-			//#if 1
-			log_output(LOG_CODE, "noncreator_curse_erode: NOTYET!")
-			//#else
-			//                                delta_loyalty(who, -(im.curse_loyalty), TRUE);
-			//
-			//                                log_output(LOG_SPECIAL, "%s loses %d loyalty from a curse on %s.",
-			//                                    box_name(who), im.curse_loyalty,
-			//                                    box_name(e.item));
-			//#endif
+			log_output(LOG_SPECIAL, "%s loses %d loyalty from a curse on %s.",
+				box_name(who), im.CurseLoyalty,
+				box_name(e.item))
 		}
 
 	}
@@ -1372,6 +1364,10 @@ func daily_auto_drop() {
 			queue(pl, "quit")
 			log_output(LOG_SPECIAL, "Queued drop for %s", box_name(pl))
 			log_output(LOG_SPECIAL, "    %s <%s>", s, email)
+		} else if !options.free && account_balance(pl) < 0 {
+			wout(pl, "You are being dropped because your account balance is %s.", money(account_balance(pl)))
+			queue(pl, "quit")
+			log_output(LOG_SPECIAL, "Queued drop for %s (balance %s)", box_name(pl), money(account_balance(pl)))
 		}
 	}
 
@@ -1399,7 +1395,11 @@ func turn_limit_drop(turns int) {
 }
 
 func maint_cost(item, who int) int {
-	return or_int(rp_item(item) != nil, rp_item(item).maintenance, 0)
+	cost, ok := game_rules.Maintenance.Items[item]
+	if !ok {
+		cost = or_int(rp_item(item) != nil, rp_item(item).maintenance, 0)
+	}
+	return cost * game_rules.Maintenance.Percent / 100
 
 	//switch (item)
 	//{
@@ -1734,7 +1734,7 @@ func temple_income() {
 			continue
 		}
 
-		if options.mp_antipathy {
+		if game_rules.MPAntipathy {
 			/*
 			 *  Is there a magician present?
 			 *
@@ -2016,7 +2016,7 @@ func collect_taxes() {
 		if garr != 0 &&
 			province_admin(where) != 0 &&
 			rp_loc(where).tax_rate != 0 &&
-			has_item(where, item_peasant) > or_int(city != 0, 1000, 100) {
+			has_item(where, item_peasant) > or_int(city != 0, game_rules.Taxes.MinPeasantsCity, game_rules.Taxes.MinPeasants) {
 			/*
 			 *  Figure out how many we're going to convert.
 			 *
@@ -2051,7 +2051,7 @@ func collect_taxes() {
 			 *
 			 */
 			if get_effect(where, ef_improve_taxes, 0, 0) != FALSE {
-				amount += amount + treasury
+				amount += (amount + treasury) * game_rules.Taxes.ImprovePercent / 100
 			}
 			/*
 			 *  Consume out of the province.
@@ -2921,7 +2921,7 @@ func post_month() {
 	decrement_region_shroud()
 	decrement_meditation_hinder()
 	hide_mage_decay()
-	if options.mp_antipathy {
+	if game_rules.MPAntipathy {
 		do_staff()
 	}
 
@@ -3083,8 +3083,9 @@ func daily_events() {
 
 		assert(4 <= MONTH_DAYS)
 
+		// the first four days of the scrambled list are the weather days
 		weather_days = shuffle_ints(weather_days)
-		sort.Slice(weather_days, func(i, j int) bool {
+		sort.Slice(weather_days[:4], func(i, j int) bool {
 			return int_comp(weather_days[i], weather_days[j]) < 0
 		})
 	}
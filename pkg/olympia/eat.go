@@ -21,9 +21,13 @@ package olympia
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net/mail"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
@@ -62,6 +66,7 @@ var (
 	pl            = 0
 	reply_addr    string
 	save_line     []byte
+	signed_in     = 0 // player already authenticated by the order source
 	unit          = 0
 	who_to        string
 )
@@ -166,6 +171,7 @@ func init_eat_vars() {
 
 	already_seen = false
 	pl = 0
+	signed_in = 0
 	unit = 0
 	n_queued = 0
 	n_fail = 0
@@ -173,77 +179,199 @@ func init_eat_vars() {
 	save_line = nil
 }
 
-// if the address contains "<.*>", return text
-// otherwise trim address and return first word
+// parse_reply reads the headers of an order message.
+// It returns the address to reply to, the body of the message, and the
+// pre-processor flag:
 //
-//	rmatch("?*<(?*)>", s, &pat))
-//	rmatch("(?*)[ \t]+\\(?**\\)", s, &pat))
-func crack_address_sup(addr []byte) []byte {
-	addr = bytes.TrimSpace(addr)
-	if start := bytes.IndexByte(addr, '<'); start != -1 {
-		if end := bytes.IndexByte(addr, '>'); end > start+1 {
-			return addr[start+1 : end]
+//	false -> no pre-processing requested
+//	 true -> something
+//
+// Messages from a mailbox may start with a "From " envelope line.
+// If there is no From: or Reply-To: header, we reply to the envelope sender.
+func parse_reply(data []byte) (string, []byte, bool, error) {
+	var from_space string
+	if bytes.HasPrefix(data, []byte("From ")) {
+		line := data
+		if nl := bytes.IndexByte(data, '\n'); nl != -1 {
+			line, data = data[:nl], data[nl+1:]
+		} else {
+			data = nil
+		}
+		if w := bytes.Fields(line[5:]); len(w) != 0 {
+			from_space = string(w[0])
 		}
 	}
-	if w := bytes.Fields(addr); len(w) != 0 {
-		return str_save(w[0])
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, false, fmt.Errorf("parse_reply: %w", err)
 	}
-	return str_save(addr)
-}
 
-func crack_address(addr []byte) []byte {
-	if addr = crack_address_sup(addr); len(addr) != 0 {
-		return addr
+	// subject line indicator asking for preprocessing?
+	subject := msg.Header.Get("Subject")
+	preFlag := strings.Contains(subject, "cpp") || strings.Contains(subject, "preprocess")
+
+	if len(msg.Header["X-Loop"]) != 0 {
+		already_seen = true
 	}
-	return nil
+
+	body, err := order_body(msg)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("parse_reply: %w", err)
+	}
+
+	for _, key := range []string{"Reply-To", "From"} {
+		if list, err := msg.Header.AddressList(key); err == nil && len(list) != 0 {
+			return list[0].Address, body, preFlag, nil
+		}
+	}
+	return from_space, body, preFlag, nil
 }
 
-// returns address and pre-processor flag
-//
-//	false -> no pre-processing requested
-//	 true -> something
-func parse_reply(fp *os.File) ([]byte, bool) {
-	s := getlin(fp)
-	if i_strncmp(s, []byte("From "), 5) != 0 {
-		return nil, false
+/*
+ *  The orders couldn't be preprocessed.  Scanning them as written
+ *  would queue the macros as orders, so reject the lot.
+ *
+ */
+func reject_orders(e error) {
+	last_line = line_count /* no order line to quote */
+	err(EAT_ERR, sout("Your orders asked for preprocessing, but it failed: %v", e))
+	err(EAT_WARN, "None of your orders were read.  Send them again without \"cpp\" in the subject.")
+}
+
+/*
+ *  Run the orders through the preprocessor named by options.cpp,
+ *  with -imacros libdir/defines.  Orders that use any directive
+ *  other than the macro and conditional ones are refused, so that
+ *  players can't read files from the server; see cpp_check.
+ *
+ *  We call the preprocessor directly to avoid the fact that GCC wants
+ *  a .c ending on a source file.  We want the GCC preprocessor because
+ *  it lets us do -imacros.
+ *
+ */
+func preprocess(body []byte) ([]byte, error) {
+	if options.cpp == "" {
+		return nil, fmt.Errorf("preprocess: the game has no preprocessor configured")
 	}
-	s = s[5:] // skip the From:
-	if sp := bytes.IndexAny(s, "\t "); sp != -1 {
-		s = s[:sp]
+
+	// cpp joins continued lines before it looks for directives, so we must too
+	body = bytes.ReplaceAll(body, []byte("\\\r\n"), nil)
+	body = bytes.ReplaceAll(body, []byte("\\\n"), nil)
+	if err := cpp_check(body); err != nil {
+		return nil, fmt.Errorf("preprocess: %w", err)
 	}
-	if len(s) == 0 { // we did not get a From address line in the header
-		return nil, false
+
+	defines, err := filepath.Abs(filepath.Join(libdir, "defines"))
+	if err != nil {
+		return nil, fmt.Errorf("preprocess: %w", err)
 	}
 
-	var from_colon, reply_to []byte
-	from_space := str_save(s)
-	preFlag := false
-	for s = getlin(fp); s != nil; s = getlin(fp) {
-		if len(s) == 0 {
-			break
-		} else if i_strncmp(s, []byte("Subject:"), 8) == 0 {
-			// subject line indicator asking for preprocessing?
-			if bytes.Contains(s, []byte("cpp")) || bytes.Contains(s, []byte("preprocess")) {
-				preFlag = true
+	// an empty directory, so that a quoted include has nothing to find
+	dir, err := os.MkdirTemp("", "cpp1.*")
+	if err != nil {
+		return nil, fmt.Errorf("preprocess: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "orders")
+	if err := os.WriteFile(name, body, 0600); err != nil {
+		return nil, fmt.Errorf("preprocess: %w", err)
+	}
+
+	cmd := exec.Command(options.cpp, "-P", "-nostdinc", "-imacros", defines, name)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	b, err := cmd.Output()
+	if err != nil {
+		if msg, _, _ := strings.Cut(strings.TrimSpace(stderr.String()), "\n"); msg != "" {
+			return nil, fmt.Errorf("preprocess: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("preprocess: %w", err)
+	}
+	return b, nil
+}
+
+// cpp_directives are the directives players may use in their orders.
+var cpp_directives = map[string]bool{
+	"define": true, "undef": true,
+	"if": true, "ifdef": true, "ifndef": true, "elif": true, "elifdef": true, "elifndef": true, "else": true, "endif": true,
+}
+
+// cpp_operators read files or run pragmas from inside an expression.
+var cpp_operators = [][]byte{[]byte("__has_include"), []byte("__has_embed"), []byte("_Pragma")}
+
+// cpp_check returns an error if the orders use a directive that isn't in
+// cpp_directives, or one of cpp_operators.  A comment that began on an
+// earlier line may hide or reveal a directive, so we don't try to track
+// comments across lines; instead each line is checked from its start
+// and from the end of every comment closed on it.  The continued lines
+// must already be joined.
+func cpp_check(body []byte) error {
+	for n, line := range bytes.Split(body, []byte("\n")) {
+		for _, op := range cpp_operators {
+			if bytes.Contains(line, op) {
+				return fmt.Errorf("line %d: %s is not allowed", n+1, op)
 			}
-		} else if w := 5; i_strncmp(s, []byte("From:"), w) == 0 {
-			from_colon = crack_address((s[w:]))
-		} else if w = 9; i_strncmp(s, []byte("Reply-To:"), w) == 0 {
-			reply_to = crack_address((s[w:]))
-		} else if w = 6; i_strncmp(s, []byte("X-Loop"), w) == 0 {
-			already_seen = true
 		}
+		for rest := line; ; {
+			if name, ok := cpp_directive(rest); ok && name != "" && !cpp_directives[name] {
+				return fmt.Errorf("line %d: #%s is not allowed", n+1, name)
+			}
+			i := bytes.Index(rest, []byte("*/"))
+			if i == -1 {
+				break
+			}
+			rest = rest[i+2:]
+		}
+	}
+	return nil
+}
+
+// cpp_directive returns the name of the directive at the start of the line.
+// Spaces and comments may come before and after the "#" (or its digraph
+// "%:" or trigraph "??=").  The name is empty for the null directive.
+func cpp_directive(line []byte) (string, bool) {
+	line = cpp_skip_space(line)
+	switch {
+	case bytes.HasPrefix(line, []byte("#")):
+		line = line[1:]
+	case bytes.HasPrefix(line, []byte("%:")):
+		line = line[2:]
+	case bytes.HasPrefix(line, []byte("??=")):
+		line = line[3:]
+	default:
+		return "", false
+	}
+	line = cpp_skip_space(line)
+	i := 0
+	for i < len(line) && (isalpha(line[i]) || isdigit(line[i]) || line[i] == '_') {
+		i++
+	}
+	if i == 0 && len(line) != 0 && !bytes.HasPrefix(line, []byte("//")) {
+		// not a name, or a comment that carries the name onto the next line
+		return string(line), true
 	}
+	return string(line[:i]), true
+}
 
-	if reply_to != nil {
-		return reply_to, preFlag
-	} else if from_colon != nil {
-		return from_colon, preFlag
+// cpp_skip_space skips spaces and complete /* */ comments.
+// A comment that isn't closed on the line is left in place.
+func cpp_skip_space(line []byte) []byte {
+	for {
+		line = bytes.TrimLeft(line, " \t\f\v\r")
+		if !bytes.HasPrefix(line, []byte("/*")) {
+			return line
+		}
+		i := bytes.Index(line[2:], []byte("*/"))
+		if i == -1 {
+			return line
+		}
+		line = line[2+i+2:]
 	}
-	return from_space, preFlag
 }
 
-func eat_line_2(fp *os.File, eat_white bool) []byte {
+func eat_line_2(fp io.Reader, eat_white bool) []byte {
 	var line []byte
 	if eat_white {
 		line = getlin_ew(fp)
@@ -264,7 +392,7 @@ func eat_line_2(fp *os.File, eat_white bool) []byte {
 	return line
 }
 
-func eat_next_line_sup(fp *os.File) []byte {
+func eat_next_line_sup(fp io.Reader) []byte {
 	line := getlin_ew(fp)
 	if line == nil {
 		return nil
@@ -280,7 +408,7 @@ func eat_next_line_sup(fp *os.File) []byte {
 }
 
 // consume empty lines
-func eat_next_line(fp *os.File) []byte {
+func eat_next_line(fp io.Reader) []byte {
 	for {
 		if line := eat_next_line_sup(fp); !(line != nil && len(line) == 0) {
 			return line
@@ -289,6 +417,7 @@ func eat_next_line(fp *os.File) []byte {
 }
 
 func err(k int, s string) {
+	check_message(k, s)
 	out_alt_who = k
 	if k == EAT_ERR {
 		n_fail++
@@ -305,7 +434,7 @@ func err(k int, s string) {
 	indent -= 3
 }
 
-func next_cmd(fp *os.File, c *command) {
+func next_cmd(fp io.Reader, c *command) {
 	c.cmd = 0
 	for {
 		line := eat_next_line(fp)
@@ -336,15 +465,25 @@ func next_cmd(fp *os.File, c *command) {
  *  How much should we charge someone?
  *
  */
-func turn_charge(pl int) []byte {
+func turn_charge(pl int) int {
 	nps := 0
 	for _, i := range loop_units(pl) {
 		nps += nps_invested(i)
 	}
-	if nps <= options.free_np_limit {
-		return str_save([]byte("0.00"))
+	if nps <= game_rules.FreeNPLimit {
+		return 0
 	}
-	return str_save([]byte(options.turn_charge))
+	charge, err := parse_money(options.turn_charge)
+	if err != nil {
+		log.Printf("turn_charge: %v\n", err)
+		return 0
+	}
+	return charge
+}
+
+// can_afford_turn returns true if the player's balance covers the next turn.
+func can_afford_turn(pl int) bool {
+	return account_balance(pl) >= turn_charge(pl)
 }
 
 func do_begin(c *command) bool {
@@ -367,10 +506,8 @@ func do_begin(c *command) bool {
 	 *  they can't afford to pay for the next turn.
 	 *
 	 */
-	if options.check_balance != FALSE {
-		cmd := sout("%s -p %s -g tag%d -T %s > /dev/null", options.accounting_prog, box_code_less(pl), game_number, turn_charge(pl))
-		result := system(cmd)
-		if result != -1 && result != 0 {
+	if options.check_balance != FALSE && !options.free {
+		if !can_afford_turn(c.a) {
 			err(EAT_ERR, "*********************************************************")
 			err(EAT_ERR, "**                                                     **")
 			err(EAT_ERR, "** Warning: Low account balance                        **")
@@ -387,7 +524,9 @@ func do_begin(c *command) bool {
 
 	pl_pass = str_save([]byte(p_player(c.a).Password))
 
-	if numargs(c) > 1 {
+	if c.a == signed_in {
+		/* the order source checked the password; the BEGIN line has none */
+	} else if numargs(c) > 1 {
 		if len(pl_pass) == 0 {
 			err(EAT_WARN, "No password is currently set")
 		} else if i_strcmp(pl_pass, c.parse[2]) != 0 {
@@ -1296,7 +1435,7 @@ func check_cmd(c *command) {
 	}
 }
 
-func do_eat_command(c *command, fp *os.File) bool {
+func do_eat_command(c *command, fp io.Reader) bool {
 
 	assert(c.cmd != cmd_end)
 
@@ -1426,12 +1565,15 @@ func do_eat_command(c *command, fp *os.File) bool {
 	return true
 }
 
-func parse_and_munch(fp *os.File) {
+func parse_and_munch(fp io.Reader) {
 	c := &command{}
 	first_admit_check = true
 	next_cmd(fp, c)
 	for c.cmd != cmd_end {
-		if !do_eat_command(c, fp) {
+		check_start_command(c)
+		ok := do_eat_command(c, fp)
+		check_end_command()
+		if !ok {
 			return
 		}
 		next_cmd(fp, c)
@@ -1465,19 +1607,23 @@ func eat_banner() {
 	var full_name string
 	if valid_box(pl) {
 		if p := rp_player(pl); p != nil && len(p.FullName) != 0 {
-			full_name = sout(" (%s)", p.FullName)
+			full_name = p.FullName
 		}
 	}
 
 	if already_seen {
 		to = "moderator@olytag.com" /*UPDATE*/
-		full_name = " (Error Watcher)"
+		full_name = "Error Watcher"
 		cc_addr = ""
 	}
 
 	who_to = to
 
-	out(eat_pl, "To:~%s%s", no_spaces(to), no_spaces(full_name))
+	var to_list []string
+	for _, email := range mail_addresses(to) {
+		to_list = append(to_list, (&mail.Address{Name: full_name, Address: email}).String())
+	}
+	out(eat_pl, "To:~%s", no_spaces(strings.Join(to_list, ", ")))
 
 	if cc_addr != "" {
 		out(eat_pl, "Cc:~%s", no_spaces(cc_addr))
@@ -1501,16 +1647,8 @@ func eat_banner() {
 	 *  Warning about low balances.
 	 *
 	 */
-	if pl != 0 {
-
-		cmd := sout("%s -p %s -g tag%d -T %s > /dev/null",
-			options.accounting_prog,
-			box_code_less(pl),
-			game_number,
-			options.turn_charge)
-		result := system(cmd)
-
-		if result != -1 && result != 0 {
+	if pl != 0 && !options.free {
+		if !can_afford_turn(pl) {
 			out(eat_pl, "*********************************************************")
 			out(eat_pl, "**                                                     **")
 			out(eat_pl, "** Warning: Low account balance                        **")
@@ -1528,17 +1666,11 @@ func eat_banner() {
 	out(eat_pl, "%d queued, %d error%s.", n_queued, n_fail, or_string(n_fail == 1, "", "s"))
 }
 
-func include_orig(fp *os.File) {
+func include_orig(data []byte) {
 	out_alt_who = EAT_HEADERS
 
-	// rewind(fp);
-	if i, err := fp.Seek(0, 0); err != nil {
-		panic(err)
-	} else if i != 0 {
-		panic("assert(fp.Seek(0,0) == 0)")
-	}
-
-	for s := getlin(fp); s != nil; s = getlin(fp) {
+	data = bytes.ReplaceAll(data, []byte("\r"), nil)
+	for _, s := range bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) {
 		/*
 		 *  Tue Nov  7 08:23:19 2000 -- Scott Turner
 		 *
@@ -1584,125 +1716,102 @@ func show_pending() {
 
 var eat_queue_mode = false
 
-func eat(fnam string, mail_now bool) {
-	ret := 0
-	var fnam_cpp string
-
+/*
+ *  Scan one set of orders and write the acknowledgement.
+ *
+ *  Returns the body of the acknowledgement.  It is mailed to the
+ *  player if mail_now is set.
+ *
+ */
+func eat(m *OrderMessage, mail_now bool) ([]byte, error) {
 	init_eat_vars()
 	eat_queue_mode = false
-
-	fp, err := fopen(fnam, "r")
+	defer func() {
+		eat_queue_mode = false
+	}()
+	last_order_check = nil
+	check_begin()
+	signed_in = m.Player
+
+	var body []byte
+	var cpp bool
+	var err error
+	reply_addr, body, cpp, err = parse_reply(m.Data)
 	if err != nil {
-		log.Printf("can't open %s: %v", fnam, err)
-		return
+		return nil, fmt.Errorf("eat: %w", err)
 	}
 
-	b, cpp := parse_reply(fp)
-	reply_addr = string(b)
-
+	var cpp_err error
 	if cpp {
-		/*
-		 *  We need to call the GCC preprocessor directly to avoid
-		 *  the fact that GCC wants a .c ending on a source file.  We
-		 *  want the GCC preprocessor because it let's us do -imacros.
-		 *
-		 */
-		fclose(fp)
-		buf := fmt.Sprintf("egrep -v '#include' %s > /tmp/cpp1.%d; %s -P -imacros %s/defines /tmp/cpp1.%d > /tmp/cpp.%d",
-			fnam, get_process_id(), options.cpp, libdir, get_process_id(), get_process_id())
-		system(buf)
-		fnam_cpp = fmt.Sprintf("/tmp/cpp.%d", get_process_id())
-		fp, err = fopen(fnam_cpp, "r")
-		/*
-		 *  Mon Mar  1 12:28:58 1999 -- Scott Turner
-		 *
-		 *  How to handle an error?
-		 *
-		 */
-		if err != nil {
-			log.Printf("Cannot open cpp file %s: %v\n", fnam_cpp, err)
-			fp, err = fopen(fnam, "r")
-			if err != nil {
-				log.Printf("can't open %s: %v", fnam, err)
-				return
-			}
-		}
-		/*
-		 *  Skip ahead to beginning of message.
-		 *
-		 */
-		for s := getlin(fp); s != nil; s = getlin(fp) {
-			if len(s) == 0 {
-				break
-			}
+		if body, cpp_err = preprocess(body); cpp_err != nil {
+			log.Printf("eat: %s: %v\n", m.ID, cpp_err)
 		}
 	}
 
-	if reply_addr != "" {
-		if i_strncmp([]byte(reply_addr), []byte("postmaster"), 10) == 0 ||
-			i_strncmp([]byte(reply_addr), []byte("mailer-daemon"), 13) == 0 ||
-			i_strncmp([]byte(reply_addr), []byte("mail-daemon"), 11) == 0 {
-			already_seen = true
-		}
-
-		unlink(sout("%s/log/%d", libdir, eat_pl))
-		open_logfile_nondestruct()
-		p_player(eat_pl).output = clear_know_rec(p_player(eat_pl).output)
-		out_path = MASTER
+	if reply_addr == "" && m.Player == 0 && !eat_dry_run {
+		return nil, fmt.Errorf("eat: %s: no reply address", m.ID)
+	}
 
-		parse_and_munch(fp)
-		eat_banner()
+	if i_strncmp([]byte(reply_addr), []byte("postmaster"), 10) == 0 ||
+		i_strncmp([]byte(reply_addr), []byte("mailer-daemon"), 13) == 0 ||
+		i_strncmp([]byte(reply_addr), []byte("mail-daemon"), 11) == 0 {
+		already_seen = true
+	}
 
-		if pl != 0 {
-			show_pending()
-		}
+	ack_log := filepath.Join(logdir(), fmt.Sprintf("%d", eat_pl))
+	if err := os.Remove(ack_log); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("eat: %w", err)
+	}
+	open_logfile_nondestruct()
+	p_player(eat_pl).output = nil
+	out_path = MASTER
 
-		include_orig(fp)
+	if cpp_err != nil {
+		reject_orders(cpp_err)
+	} else {
+		parse_and_munch(bytes.NewReader(body))
+	}
+	last_order_check = check_finish()
+	eat_banner()
 
-		out_alt_who = OUT_INCLUDE
-		gen_include_sup(eat_pl) /* must be last */
+	if pl != 0 {
+		show_pending()
+	}
 
-		out_path = 0
-		out_alt_who = 0
+	include_orig(m.Data)
 
-		if pl != 0 {
-			unlink(sout("%s/orders/%d", libdir, pl))
-			if err := save_player_orders(pl); err != nil {
-				log.Printf("eat: %v\n", err)
-			}
-			unlink(sout("%s/fact/%d", libdir, pl))
-			write_player(pl)
-		}
+	out_alt_who = OUT_INCLUDE
+	gen_include_sup(eat_pl) /* must be last */
 
-		close_logfile()
+	out_path = 0
+	out_alt_who = 0
 
-		if mail_now {
-			/* VLN		  ret = system(sout("rep %s/log/%d | sendmail -t", libdir, eat_pl)); */
-			ret = system(sout("rep %s/log/%d | msmtp -t", libdir, eat_pl))
-			if ret != 0 {
-				log.Printf("error: couldn't mail ack to %s\n", who_to)
-				/* VLN			log.Printf( "command was: %s\n", sout("rep %s/log/%d | sendmail -t", libdir, eat_pl)); */
-				log.Printf("command was: %s\n", sout("rep %s/log/%d | msmtp -t", libdir, eat_pl))
-				log.Printf("ret was = %d\n", ret)
-			} else {
-				// let's not overwhelm the system with a bunch of rapid-fire mail responses.
-				time.Sleep(5 * time.Second)
-			}
+	if pl != 0 && !eat_dry_run {
+		if err := os.Remove(filepath.Join(libdir, "orders", fmt.Sprintf("%d", pl))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("eat: %v\n", err)
+		}
+		if err := save_player_orders(pl); err != nil {
+			log.Printf("eat: %v\n", err)
+		}
+		if err := os.Remove(filepath.Join(libdir, "fact", fmt.Sprintf("%d", pl))); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("eat: %v\n", err)
+		}
+		if err := write_player(pl); err != nil {
+			log.Printf("eat: %v\n", err)
 		}
-
 	}
 
-	fclose(fp)
+	close_logfile()
 
-	if cpp {
-		var buf string
-		buf = fmt.Sprintf("/tmp/cpp1.%d", get_process_id())
-		unlink(buf)
-		buf = fmt.Sprintf("/tmp/cpp.%d", get_process_id())
-		unlink(buf)
+	ack, err := acknowledge(ack_log, mail_now && !eat_dry_run)
+	if err != nil {
+		log.Printf("error: couldn't mail ack to %s: %v\n", who_to, err)
+	} else if mail_now {
+		// let's not overwhelm the system with a bunch of rapid-fire mail responses.
+		time.Sleep(ack_delay)
 	}
 
-	eat_queue_mode = false
+	return ack, nil
 }
 
 func write_remind_list() {
@@ -1728,6 +1837,10 @@ func write_remind_list() {
 	fclose(fp)
 }
 
+// read_spool scans the orders waiting in the spool and any other order sources.
+// It returns false if a stop file was found.
+// Messages are only archived and removed from their source if mail_now is set;
+// otherwise the scan is a dry run that can be repeated.
 func read_spool(mail_now bool) bool {
 	dirSpool := filepath.Join(libdir, "spool")
 	files, err := os.ReadDir(dirSpool)
@@ -1747,23 +1860,36 @@ func read_spool(mail_now bool) bool {
 	}
 
 	remindMe := false
-	for _, f := range files {
-		// ignore hidden and editor temp files
-		fname := f.Name()
-		if strings.HasPrefix(fname, ".") || strings.HasSuffix(f.Name(), "~") || strings.HasSuffix(f.Name(), ".swp") {
-			log.Printf("read_spool: ignoring %q\n", fname)
+	for _, src := range order_sources() {
+		msgs, err := src.Fetch()
+		if err != nil {
+			log.Printf("read_spool: %s: %v\n", src.Name(), err)
 			continue
 		}
-		if strings.HasPrefix(fname, "m") {
-			log.Printf("read_spool: processing %q: mail_now %v\n", fname, mail_now)
-			mailFile := filepath.Join(libdir, "spool", fname)
-			eat(mailFile, mail_now)
-			if mail_now { // remove the spooled file if we're actually replying
-				// todo: brave of us to assume that we had no errors processing the file before deleting it
-				unlink(mailFile)
+		for _, m := range msgs {
+			log.Printf("read_spool: processing %q: mail_now %v\n", m.ID, mail_now)
+			if mail_now {
+				if err := archive_order_message(m); err != nil {
+					// don't consume what we couldn't save
+					log.Printf("read_spool: %v\n", err)
+					continue
+				}
+			}
+			if _, err := eat(m, mail_now); err != nil {
+				log.Printf("read_spool: %v\n", err)
+			}
+			if mail_now { // remove the message if we're actually replying
+				if err := src.Remove(m); err != nil {
+					log.Printf("read_spool: %s: %v\n", src.Name(), err)
+				}
 			}
 			remindMe = true
 		}
+		if c, ok := src.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				log.Printf("read_spool: %s: %v\n", src.Name(), err)
+			}
+		}
 	}
 
 	if remindMe {
@@ -1773,20 +1899,44 @@ func read_spool(mail_now bool) bool {
 	return true
 }
 
-func eat_loop(mail_now bool) {
+// eat_loop processes the spool directory and the other order sources.
+// If once is set, it returns after the first pass.
+// Otherwise, it keeps polling until read_spool finds a stop file.
+// Orders posted to the HTTP endpoint are scanned between polls.
+func eat_loop(mail_now, once bool) error {
 	if err := mkdir(filepath.Join(libdir, "orders")); err != nil {
-		panic(err)
+		return fmt.Errorf("eat_loop: %w", err)
 	}
 	if err := mkdir(filepath.Join(libdir, "spool")); err != nil {
-		panic(err)
+		return fmt.Errorf("eat_loop: %w", err)
 	}
 	//chmod(sout("%s/spool", libdir), 0777);
 
 	write_remind_list()
 
-	for read_spool(mail_now) {
-		time.Sleep(10 * time.Second)
+	var requests chan *order_request // nil unless the endpoint is running
+	if orders_http_addr != "" && !once {
+		srv, ch, err := start_orders_http(orders_http_addr)
+		if err != nil {
+			return fmt.Errorf("eat_loop: %w", err)
+		}
+		defer stop_orders_http(srv)
+		requests = ch
+	}
+
+	for read_spool(mail_now) && !once {
+		poll := time.After(10 * time.Second)
+		for waiting := true; waiting; {
+			select {
+			case r := <-requests:
+				r.reply <- eat_order_request(r, mail_now)
+			case <-poll:
+				waiting = false
+			}
+		}
 	}
+
+	return nil
 }
 
 func v_format(c *command) int {
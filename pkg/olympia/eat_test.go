@@ -0,0 +1,89 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import "testing"
+
+func TestCppCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		ok   bool
+	}{
+		{"orders", "begin ab1 \"secret\"\nmove n\nend\n", true},
+		{"macros", "#define SCOUT(x) explore x\n#ifdef FOO\nSCOUT(n)\n#endif\n#\n", true},
+		{"include", "#include \"/etc/passwd\"\n", false},
+		{"spaced include", "  #  include </etc/passwd>\n", false},
+		{"digraph", "%:include \"/etc/passwd\"\n", false},
+		{"trigraph", "??=include \"/etc/passwd\"\n", false},
+		{"include_next", "#include_next <passwd>\n", false},
+		{"import", "#import \"/etc/passwd\"\n", false},
+		{"embed", "#embed \"/etc/passwd\"\n", false},
+		{"comment before name", "#/* x */include \"/etc/passwd\"\n", false},
+		{"comment before hash", "/* x */ #include \"/etc/passwd\"\n", false},
+		{"comment across lines", "/* x\n*/ #include \"/etc/passwd\"\n", false},
+		{"name on next line", "# /* x\n*/ include \"/etc/passwd\"\n", false},
+		{"pragma", "#pragma GCC dependency \"/etc/passwd\"\n", false},
+		{"pragma operator", "_Pragma(\"GCC dependency \\\"/etc/passwd\\\"\")\n", false},
+		{"has_include", "#if __has_include(\"/etc/passwd\")\n#endif\n", false},
+		{"line marker", "# 1 \"/etc/passwd\"\n", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := cpp_check([]byte(tc.body)); (err == nil) != tc.ok {
+				t.Errorf("cpp_check(%q): ok %v, got %v", tc.body, tc.ok, err)
+			}
+		})
+	}
+}
+
+// TestBeginSignedIn accepts a BEGIN line without a password only
+// for the player that the order source already signed in.
+func TestBeginSignedIn(t *testing.T) {
+	defer clear_boxes()
+	clear_boxes()
+	init_cmd_tbl()
+	defer init_eat_vars()
+
+	const who = 1001
+	alloc_box(eat_pl, T_player, sub_pl_system)
+	alloc_box(who, T_player, sub_pl_regular)
+	p_player(who).Password = "secret"
+
+	for _, tc := range []struct {
+		line      string
+		signed_in int
+		ok        bool
+	}{
+		{"begin 1001", 0, false},
+		{"begin 1001 wrong", 0, false},
+		{"begin 1001 secret", 0, true},
+		{"begin 1001", who, true},
+	} {
+		init_eat_vars()
+		signed_in = tc.signed_in
+		c := &command{}
+		if !oly_parse_s(c, tc.line) {
+			t.Fatalf("%q: parse failed", tc.line)
+		}
+		if ok := do_begin(c); ok != tc.ok {
+			t.Errorf("%q signed in as %d: want %v, got %v", tc.line, tc.signed_in, tc.ok, ok)
+		}
+	}
+}
@@ -47,3 +47,15 @@ func (l effect_l) ToEffectList() (el EffectList) {
 	}
 	return el
 }
+
+func (l EffectList) toBox() (el effect_l) {
+	for _, e := range l {
+		el = append(el, &effect{
+			type_:   e.Type,
+			subtype: e.SubType,
+			days:    e.Days,
+			data:    e.Data,
+		})
+	}
+	return el
+}
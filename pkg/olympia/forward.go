@@ -22,54 +22,42 @@ package olympia
 import (
 	"fmt"
 	"os"
-	"strconv"
 )
 
-func d_build_wagons(c *command) int                 { panic("!implemented") }
-func d_cloak_creat(c *command) int                  { panic("!implemented") }
-func d_cloak_reg(c *command) int                    { panic("!implemented") }
-func d_curse_noncreat(c *command) int               { panic("!implemented") }
-func d_exhume(c *command) int                       { panic("!implemented") }
-func d_move(c *command) int                         { panic("!implemented") }
-func d_show_art_creat(c *command) int               { panic("!implemented") }
-func d_show_art_reg(c *command) int                 { panic("!implemented") }
-func d_teach(c *command) int                        { panic("!implemented") }
-func delta_loyalty(who int, amount int, silent int) { panic("!implemented") }
-func distance(orig, dest, gate int) int             { panic("!implemented") }
-func fetch_inside_name() string                     { panic("!implemented") }
-func first_char_here(where int) int                 { panic("!implemented") }
-func get_process_id() int                           { panic("!implemented") }
-func get_rid_of_building(fort int)                  { panic("!implemented") }
-func i_petty_thief(c *command) int                  { panic("!implemented") }
-func immediate_commands()                           { panic("!implemented") }
-func int_comp(q1, q2 interface{}) int      { panic("!implemented") }
-func is_artifact(item int) *EntityArtifact { panic("!implemented") }
-func is_port_city_where(where int) int     { panic("!implemented") }
-func my_free(ptr interface{})                       { panic("!implemented") }
-func my_malloc(size int)                            { panic("!implemented") }
-func my_realloc(ptr interface{}, size int)          { panic("!implemented") }
-func mylog(base int, num int) int                   { panic("!implemented") }
-func random() int                                   { panic("!implemented") }
-func rename(from, to string) error                  { panic("!implemented") }
-func srandom(seed uint32)                           { panic("!implemented") }
-func test_random() int                              { panic("!implemented") }
-func v_acquire(c *command) int                      { panic("!implemented") }
-func v_attack(c *command) int                       { panic("!implemented") }
-func v_build_wagons(c *command) int                 { panic("!implemented") }
-func v_cloak_creat(c *command) int                  { panic("!implemented") }
-func v_cloak_reg(c *command) int                    { panic("!implemented") }
-func v_curse_noncreat(c *command) int               { panic("!implemented") }
-func v_exhume(c *command) int                       { panic("!implemented") }
-func v_move(c *command) int                         { panic("!implemented") }
-func v_plugh(c *command) int                        { panic("!implemented") }
-func v_rem_art_cloak(c *command) int                { panic("!implemented") }
-func v_show_art_creat(c *command) int               { panic("!implemented") }
-func v_show_art_reg(c *command) int                 { panic("!implemented") }
-func wield_s(who int) string                        { panic("!implemented") }
+// int_comp compares two ints like the C qsort comparison function.
+func int_comp(a, b int) int {
+	return a - b
+}
+
+// is_artifact returns the artifact attributes of an item, or nil if the item isn't an artifact.
+func is_artifact(item int) *EntityArtifact {
+	if kind(item) != T_item {
+		return nil
+	}
+	return rp_item_artifact(item)
+}
+
+// my_free does nothing; the garbage collector reclaims the memory.
+func my_free(ptr interface{}) {}
 
+// atoi behaves like the C library function: it skips leading whitespace,
+// accepts an optional sign, and converts digits up to the first non-digit.
 func atoi(s string) int {
-	i, _ := strconv.Atoi(s)
-	return i
+	for len(s) != 0 && iswhite(s[0]) {
+		s = s[1:]
+	}
+	sign := 1
+	if len(s) != 0 && (s[0] == '-' || s[0] == '+') {
+		if s[0] == '-' {
+			sign = -1
+		}
+		s = s[1:]
+	}
+	n := 0
+	for ; len(s) != 0 && isdigit(s[0]); s = s[1:] {
+		n = n*10 + int(s[0]-'0')
+	}
+	return sign * n
 }
 
 func atoi_b(b []byte) int {
@@ -88,6 +76,11 @@ func mkdir(path string) error {
 	return nil
 }
 
+func isdir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
 func or_float(t bool, a, b float64) float64 {
 	if t {
 		return a
@@ -109,6 +102,10 @@ func or_string(t bool, a, b string) string {
 	return b
 }
 
+func rename(from, to string) error {
+	return os.Rename(from, to)
+}
+
 func rmdir(path string) error {
 	fi, err := os.Stat(path)
 	if err != nil {
@@ -124,3 +124,38 @@ func GateDataSave(name string) error {
 	log.Printf("GateDataSave: created %s\n", name)
 	return nil
 }
+
+// EntityGate is the json version of a gate.
+type EntityGate struct {
+	ToLoc        int `json:"to-loc,omitempty"`        // destination of gate
+	NotifyJumps  int `json:"notify-jumps,omitempty"`  // whom to notify
+	NotifyUnseal int `json:"notify-unseal,omitempty"` // whom to notify
+	SealKey      int `json:"seal-key,omitempty"`      // numeric gate password
+	RoadHidden   int `json:"road-hidden,omitempty"`   // this is a hidden road or passage
+}
+
+func (e *entity_gate) ToEntityGate() *EntityGate {
+	if e == nil {
+		return nil
+	}
+	return &EntityGate{
+		ToLoc:        e.to_loc,
+		NotifyJumps:  e.notify_jumps,
+		NotifyUnseal: e.notify_unseal,
+		SealKey:      e.seal_key,
+		RoadHidden:   e.road_hidden,
+	}
+}
+
+func (e *EntityGate) toBox() *entity_gate {
+	if e == nil {
+		return nil
+	}
+	return &entity_gate{
+		to_loc:        e.ToLoc,
+		notify_jumps:  e.NotifyJumps,
+		notify_unseal: e.NotifyUnseal,
+		seal_key:      e.SealKey,
+		road_hidden:   e.RoadHidden,
+	}
+}
@@ -903,6 +903,8 @@ func list_all_items(pl int) {
 
 func gm_report(pl int) {
 	stage("gm_report()")
+	gm_show_rules(pl)
+	gm_show_incidents(pl)
 	gm_show_gold(pl)
 	gm_show_control_arts(pl)
 	gm_count_priests_mages(pl)
@@ -87,8 +87,15 @@ func ilist_scramble(l ilist) ilist {
 	panic("!implemented")
 }
 
+// rem_value returns a copy of the list without any elements that match the value
 func rem_value(l []int, n int) []int {
-	panic("!implemented")
+	var cp []int
+	for _, e := range l {
+		if e != n {
+			cp = append(cp, e)
+		}
+	}
+	return cp
 }
 
 func (l ints_l) ToList() (list ints_l) {
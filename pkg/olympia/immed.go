@@ -20,68 +20,10 @@
 package olympia
 
 import (
-	"fmt"
 	"log"
 	"os"
 )
 
-func immed_commands() {
-	log.Printf("Olympia immediate mode\n")
-
-	immediate = gm_player
-	out(immediate, "You are now %s.", box_name(immediate))
-	init_locs_touched()
-
-	show_day = true
-
-	for {
-		c := p_command(immediate)
-		c.who = immediate
-		c.wait = 0
-
-		// todo: how to flush stdout?
-		log.Printf("%d> ", immediate)
-
-		line := getlin(os.Stdin)
-		if line == nil {
-			break
-		}
-		buf := str_save(line)
-
-		if !oly_parse(c, buf) {
-			log.Printf("Unrecognized command.\n")
-			continue
-		}
-		if c.fuzzy {
-			out(immediate, "(assuming you meant '%s')", cmd_tbl[c.cmd].name)
-		}
-
-		c.pri = cmd_tbl[c.cmd].pri
-		c.wait = cmd_tbl[c.cmd].time
-		c.poll = cmd_tbl[c.cmd].poll
-		c.days_executing = 0
-		c.state = LOAD
-
-		do_command(c)
-
-		for c.state == RUN {
-			evening = true
-			finish_command(c)
-			evening = false
-			olytime_increment(&sysclock)
-		}
-
-		// ifndef NEW_TRADE
-		//   if len(trades_to_check) > 0 {
-		//	   check_validated_trades()
-		//   }
-
-		// show_day = false
-	}
-
-	log.Println("")
-}
-
 func v_add_item(c *command) int {
 	if kind(c.a) == T_item {
 		if kind(c.who) != T_char {
@@ -116,17 +58,13 @@ func v_dump(c *command) int {
 func v_listcmds(c *command) int {
 	indent += 4
 
-	var buf []byte
 	for i := 1; cmd_tbl[i].name != ""; i++ {
-		buf = append(buf, []byte(fmt.Sprintf("%-12s", cmd_tbl[i].name))...)
-		if i%5 == 0 {
-			out(c.who, "%s", string(buf))
-			buf = nil
+		if cmd_tbl[i].help == "" {
+			out(c.who, "%s", cmd_tbl[i].name)
+		} else {
+			out(c.who, "%-12s %s", cmd_tbl[i].name, cmd_tbl[i].help)
 		}
 	}
-	if len(buf) != 0 {
-		out(c.who, "%s", string(buf))
-	}
 	indent -= 4
 
 	return TRUE
@@ -299,11 +237,29 @@ func v_seedmarket(c *command) int {
 }
 
 /*
- *  credit <who> <amount> <what -- defaults to gold, can be np>
+ *  credit <who> <amount> <what -- defaults to gold, can be np or account>
+ *
+ *  Crediting the account takes an amount like 5.00 and posts it
+ *  to the player's ledger.  A negative amount is a debit.
  *
  */
 func v_credit(c *command) int {
 	target, amount, item := c.a, c.b, c.c
+	if numargs(c) >= 3 && i_strcmp(c.parse[3], []byte("account")) == 0 {
+		if kind(target) != T_player {
+			wout(c.who, "%s not a player.", c.parse[1])
+			return FALSE
+		}
+		cents, err := parse_money(string(c.parse[2]))
+		if err != nil || cents == 0 {
+			wout(c.who, "You didn't specify an amount.")
+			return FALSE
+		}
+		a := player_account(target)
+		a.post(cents, "credit")
+		wout(c.who, "Credited %s %s.  Balance is now %s.", box_name(target), money(cents), money(a.Balance))
+		return TRUE
+	}
 	if amount == 0 {
 		wout(c.who, "You didn't specify an amount and/or item.")
 		return FALSE
@@ -0,0 +1,139 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// incident records a command that crashed while it was being executed.
+type incident struct {
+	day   int
+	who   int
+	line  string
+	phase string // start, finish, or interrupt
+	err   string
+	stack []string // function:file:line, innermost first
+}
+
+// incidents are the crashes recovered during the current turn.
+var incidents []incident
+
+// call_cmd calls one of a command's start, finish, or interrupt routines.
+// If the routine panics, the panic is recorded as an incident, the unit is told
+// that the command failed, and the command is marked as failed so that the
+// rest of the turn carries on.
+func call_cmd(c *command, phase string, fn func(*command) int) (status int) {
+	save_indent, save_out_path, save_out_alt_who := indent, out_path, out_alt_who
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+		indent, out_path, out_alt_who = save_indent, save_out_path, save_out_alt_who
+
+		inc := incident{
+			day:   sysclock.day,
+			who:   c.who,
+			line:  strings.TrimSpace(c.line),
+			phase: phase,
+			err:   fmt.Sprintf("%v", r),
+			stack: panic_stack(),
+		}
+		incidents = append(incidents, inc)
+		log.Printf("call_cmd: day %d: %s: %q: %s: %s\n", inc.day, box_code_less(inc.who), inc.line, inc.phase, inc.err)
+		for _, frame := range inc.stack {
+			log.Printf("call_cmd:     %s\n", frame)
+		}
+		if s, ok := r.(string); ok && s == "!implemented" {
+			out(c.who, "Internal error: part of the %s command is not implemented.", cmd_tbl[c.cmd].name)
+		} else {
+			out(c.who, "Internal error: the %s command failed and was cancelled.", cmd_tbl[c.cmd].name)
+		}
+
+		status = FALSE
+	}()
+	return fn(c)
+}
+
+// panic_stack returns the frames between the panic and call_cmd.
+// It must be called from call_cmd's deferred function.
+// Only function names, file names, and line numbers are kept. Addresses and
+// argument values change from run to run and would keep a replayed turn
+// from matching the original.
+func panic_stack() (stack []string) {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(1, pcs)])
+	for more := true; more; {
+		var frame runtime.Frame
+		frame, more = frames.Next()
+		name := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+		if name == "olympia.call_cmd" {
+			break
+		} else if strings.HasPrefix(name, "runtime.") || strings.HasPrefix(name, "olympia.panic_stack") || strings.HasPrefix(name, "olympia.call_cmd.") {
+			continue
+		}
+		stack = append(stack, fmt.Sprintf("%s:%s:%d", name, filepath.Base(frame.File), frame.Line))
+	}
+	return stack
+}
+
+// gm_show_incidents lists the commands that crashed during the turn,
+// with the stack for each, in the GM report.
+func gm_show_incidents(pl int) {
+	if len(incidents) == 0 {
+		return
+	}
+
+	out_path = MASTER
+	out_alt_who = OUT_LORE
+
+	out(pl, "")
+	out(pl, "Incidents")
+	out(pl, "---------")
+	out(pl, "")
+	for _, inc := range incidents {
+		out(pl, "day %2d  %-6s  %s", inc.day, box_code_less(inc.who), inc.line)
+		out(pl, "        %s: %s", inc.phase, inc.err)
+		for _, frame := range inc.stack {
+			out(pl, "            %s", frame)
+		}
+		out(pl, "")
+	}
+
+	out_path = 0
+	out_alt_who = 0
+}
+
+// log_incidents writes a summary of the turn's incidents to the log.
+func log_incidents() {
+	if len(incidents) == 0 {
+		log.Printf("incidents: none\n")
+		return
+	}
+	log.Printf("incidents: %d commands failed with internal errors\n", len(incidents))
+	for _, inc := range incidents {
+		log.Printf("incidents: day %2d  %-6s  %s: %s\n", inc.day, box_code_less(inc.who), inc.line, inc.err)
+	}
+}
@@ -469,7 +469,7 @@ func finish_command(c *command) int {
 
 	if c.wait <= 0 || c.poll != 0 {
 		if cmd_tbl[c.cmd].finish != nil && !c.inhibit_finish {
-			c.status = cmd_tbl[c.cmd].finish(c)
+			c.status = call_cmd(c, "finish", cmd_tbl[c.cmd].finish)
 		}
 	}
 
@@ -537,7 +537,7 @@ func do_command(c *command) {
 
 		c.debug = 0
 		c.inhibit_finish = false
-		c.status = cmd_tbl[c.cmd].start(c)
+		c.status = call_cmd(c, "start", cmd_tbl[c.cmd].start)
 
 		/*
 		 *  Thu Oct 24 15:48:47 1996 -- Scott Turner
@@ -775,7 +775,7 @@ func interrupt_order(who int) {
 
 	if c.state == RUN {
 		if cmd_tbl[c.cmd].interrupt != nil {
-			c.status = cmd_tbl[c.cmd].interrupt(c)
+			c.status = call_cmd(c, "interrupt", cmd_tbl[c.cmd].interrupt)
 		}
 		command_done(c)
 		if !(c.state != RUN) {
@@ -25,3 +25,10 @@ type Inventory struct {
 	Id  int `json:"id"`  // id of item
 	Qty int `json:"qty"` // quantity of item
 }
+
+func (l InventoryList) toBox() (il item_ent_l) {
+	for _, e := range l {
+		il = append(il, &item_ent{item: e.Id, qty: e.Qty})
+	}
+	return il
+}
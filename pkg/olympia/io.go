@@ -26,6 +26,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 )
 
@@ -1386,6 +1387,62 @@ func known_scan(s []byte, kn sparse, box_num int) sparse {
 	return kn
 }
 
+// visited_print writes the location and turn pairs in location order.
+func visited_print(fp *os.File, header []byte, v map[int]int) {
+	var l []int
+	for i := range v {
+		if valid_box(i) {
+			l = append(l, i)
+		}
+	}
+	if len(l) == 0 {
+		return
+	}
+	sort.Ints(l)
+
+	fputb(header, fp)
+	for count, i := range l {
+		if count != 0 && count%6 == 0 {
+			fprintf(fp, "\\\n\t")
+		}
+		fprintf(fp, "%d %d ", i, v[i])
+	}
+	fprintf(fp, "\n")
+}
+
+func visited_scan(s []byte, v map[int]int, box_num int) map[int]int {
+	var l []int
+	for len(s) != 0 {
+		if iswhite(s[0]) {
+			s = s[1:]
+		} else if s[0] == '\\' { /* continuation line follows */
+			s = readlin_ew()
+		} else if isdigit(s[0]) {
+			l = append(l, atoi_b(s))
+			for len(s) != 0 && isdigit(s[0]) {
+				s = s[1:]
+			}
+		} else {
+			break
+		}
+	}
+	if len(l)%2 != 0 {
+		fprintf(os.Stderr, "visited_scan(%d): odd number of values\n", box_num)
+		l = l[:len(l)-1]
+	}
+	for i := 0; i < len(l); i += 2 {
+		if !valid_box(l[i]) {
+			fprintf(os.Stderr, "visited_scan(%d): bad box reference: %d\n", box_num, l[i])
+			continue
+		}
+		if v == nil {
+			v = make(map[int]int)
+		}
+		v[l[i]] = l[i+1]
+	}
+	return v
+}
+
 func skill_list_print(fp *os.File, header []byte, l skill_ent_l) {
 	count := 0
 	for i := 0; i < len(l); i++ {
@@ -1707,7 +1764,7 @@ func req_list_print(fp *os.File, header []byte, l []*req_ent) {
 	}
 }
 
-func eq_list_scan(s []byte, l []*req_ent, box_num int) []*req_ent {
+func req_list_scan(s []byte, l []*req_ent, box_num int) []*req_ent {
 	s = bytes.TrimSpace(s)
 	for len(s) != 0 {
 		foundContinuation := false
@@ -2289,11 +2346,8 @@ func mine_info_scan(s []byte, l *entity_mine, bn int) *entity_mine {
 		}
 		s = bytes.TrimSpace(readlin_ew())
 		// sscanf(s, "ms %d", &m.shoring[i]);
-		for idx, f := range bytes.Fields(s) {
-			switch idx {
-			case 0:
-				m.shoring[i] = atoi_b(f) // todo: change to append
-			}
+		if f := bytes.Fields(s); len(f) > 1 && bytes.Equal(f[0], []byte("ms")) {
+			m.shoring[i] = atoi_b(f[1])
 		}
 		s = bytes.TrimSpace(readlin_ew())
 	}
@@ -2363,7 +2417,7 @@ func print_loc(fp *os.File, p *entity_loc) {
 	}
 
 	if p.control.closed {
-		fprintf(fp, " cd %d\n", p.control.closed)
+		fprintf(fp, " cd %d\n", TRUE)
 	}
 
 	box_print(fp, []byte(" ng "), p.near_grave)
@@ -2586,9 +2640,7 @@ func print_subloc(fp *os.File, p *entity_subloc) {
 
 	boxlist_print(fp, []byte(" lt "), p.link_to)
 	boxlist_print(fp, []byte(" lf "), p.link_from)
-	/*
-	   boxlist_print(fp, " bs ", p.bound_storms);
-	*/
+	boxlist_print(fp, []byte(" bs "), p.bound_storms)
 
 	/*
 	   if (p.link_when)
@@ -2720,9 +2772,7 @@ func scan_subloc(p *entity_subloc, box_num int) {
 			break
 
 		case `bs`:
-			/*
-			   boxlist_scan(t, box_num, &(p.bound_storms));
-			*/
+			p.bound_storms = boxlist_scan(t, box_num, p.bound_storms)
 			break
 
 		case `bl`:
@@ -2987,9 +3037,7 @@ func print_item_magic(fp *os.File, p *ItemMagic) {
 
 	box_print(fp, []byte(" ti "), p.TokenNI)
 
-	//#if 0
-	//    box_print(fp, " rc ", p.region_created);
-	//#endif
+	box_print(fp, []byte(" rc "), p.RegionCreat)
 
 	if valid_box(p.ProjectCast) {
 		box_print(fp, []byte(" pc "), p.ProjectCast)
@@ -3031,7 +3079,7 @@ func scan_item_magic(p *ItemMagic, box_num int) {
 			p.UseKey = atoi_b(t)
 			break
 		case `rc`:
-			/* p.region_created = box_scan(t); */
+			p.RegionCreat = box_scan(t)
 			break
 		case `pc`:
 			p.ProjectCast = box_scan(t)
@@ -3122,7 +3170,7 @@ func print_player(fp *os.File, p *EntityPlayer) {
 	}
 
 	if len(p.DBPath) != 0 {
-		fprintf(fp, " db%s\n", p.DBPath)
+		fprintf(fp, " db %s\n", p.DBPath)
 	}
 
 	if p.NoTab {
@@ -3165,6 +3213,10 @@ func print_player(fp *os.File, p *EntityPlayer) {
 		fprintf(fp, " na %d\n", p.Nation)
 	}
 
+	if p.NationList != FALSE {
+		fprintf(fp, " nl %d\n", p.NationList)
+	}
+
 	if p.Magic != FALSE {
 		fprintf(fp, " ma %d\n", p.Magic)
 	}
@@ -3174,6 +3226,7 @@ func print_player(fp *os.File, p *EntityPlayer) {
 	}
 
 	known_print(fp, []byte(" kn "), p.Known)
+	visited_print(fp, []byte(" lv "), p.Visited)
 	boxlist_print(fp, []byte(" un "), p.Units)
 	boxlist_print(fp, []byte(" uf "), p.Unformed)
 	admit_print(fp, p)
@@ -3258,6 +3311,9 @@ func scan_player(p *EntityPlayer, box_num int) {
 				}
 				break
 			}
+		case `nl`:
+			p.NationList = atoi_b(t)
+			break
 		case `ma`:
 			p.Magic = atoi_b(t)
 			break
@@ -3269,6 +3325,10 @@ func scan_player(p *EntityPlayer, box_num int) {
 			p.Known = known_scan(t, p.Known, box_num)
 			break
 
+		case `lv`:
+			p.Visited = visited_scan(t, p.Visited, box_num)
+			break
+
 		case `un`:
 			p.Units = boxlist_scan(t, box_num, (p.Units))
 			break
@@ -3497,8 +3557,6 @@ func scan_skill(p *entity_skill, box_num int) {
 	}
 }
 
-func req_list_scan(s []byte, l []*req_ent, box_num int) []*req_ent { panic("!implemented") }
-
 func print_command(fp *os.File, p *command) {
 	if p.cmd == 0 {
 		return
@@ -3583,7 +3641,7 @@ func scan_command(p *command, box_num int) {
 		switch c {
 		case `li`:
 			if !oly_parse_cmd(p, t) {
-				fprintf(os.Stderr, "scan_command(%d): bad cmd %s\n", box_num, t)
+				fprintf(os.Stderr, "scan_command: %v\n", bad_command_error(box_num, string(t)))
 			}
 			break
 
@@ -4027,73 +4085,60 @@ func print_nation(fp *os.File, n *entity_nation) {
 	fprintf(fp, "\n")
 }
 
-// todo: save_box should return a struct that can save as JSON
-// todo: must move id into entity_item
-func save_box(fp *os.File, n int) (*Box, error) {
-	log.Printf("save_box: please make this JSON\n")
+func save_box(fp *os.File, n int) {
 	if kind(n) == T_deleted {
-		return nil, nil
+		return
 	}
 
 	assert(valid_box(n))
 
 	p := bx[n]
 
-	b := &Box{
-		Id:      n,
-		Name:    p.name,
-		Kind:    p.kind,
-		SubKind: p.skind,
-		//if pd := rp_disp(n); pd != nil {
-		//	boxlist_print(fp, []byte("an "), pd.neutral)
-		//	boxlist_print(fp, []byte("ad "), pd.defend)
-		//	boxlist_print(fp, []byte("ah "), pd.hostile)
-		//}
-		Attitudes: p.ToAttitudes(),
-		//if vp := rp_magic(n); vp != nil {
-		//	print_magic(fp, vp)
-		//}
-		CharMagic: p.ToCharMagic(),
-		//effect_list_print(fp, []byte("el\t"), p.effects)
-		Effects: p.ToEffectList(),
-		//if vp := rp_item_artifact(n); vp != nil {
-		//	print_artifact(fp, vp)
-		//}
-		EntityArtifact: p.ToEntityArtifact(n),
-		//if vp := rp_char(n); vp != nil {
-		//	print_char(fp, vp)
-		//}
-		EntityChar: p.ToEntityChar(),
-		//if vp := rp_item(n); vp != nil {
-		//	print_item(fp, vp)
-		//}
-		EntityItem: p.ToEntityItem(n),
-		//if vp := rp_loc(n); vp != nil {
-		//	print_loc(fp, vp)
-		//}
-		EntityLoc: p.ToEntityLoc(),
-		//if vp := rp_player(n); vp != nil {
-		//	print_player(fp, vp)
-		//}
-		EntityPlayer: p.ToEntityPlayer(),
-		//if vp := rp_subloc(n); vp != nil {
-		//	print_subloc(fp, vp)
-		//}
-		EntitySubLoc: p.ToEntitySubLoc(),
-		//if vp := rp_item_magic(n); vp != nil {
-		//	print_item_magic(fp, vp)
-		//}
-		ItemMagic: p.ToItemMagic(n),
-		//item_list_print(fp, []byte("il\t"), p.items)
-		Items: p.ToInventoryList(),
-		//if vp := rp_loc_info(n); vp != nil {
-		//	print_loc_info(fp, vp)
-		//}
-		LocationInfo: p.ToLocationInfo(),
-		//trade_list_print(fp, []byte("tl\t"), p.trades)
-		Trades: p.ToTradeList(),
+	if p.skind != 0 {
+		fprintf(fp, "%d %s %s\n", n, kind_s[p.kind], subkind_s[p.skind])
+	} else {
+		fprintf(fp, "%d %s 0\n", n, kind_s[p.kind])
+	}
+
+	if p.name != "" {
+		fprintf(fp, "na %s\n", p.name)
+	}
+
+	print_loc_info(fp, &p.x_loc_info)
+	item_list_print(fp, []byte("il\t"), p.items)
+	trade_list_print(fp, []byte("tl\t"), p.trades)
+	effect_list_print(fp, []byte("el\t"), p.effects)
+
+	if pd := rp_disp(n); pd != nil {
+		boxlist_print(fp, []byte("an "), pd.neutral)
+		boxlist_print(fp, []byte("ad "), pd.defend)
+		boxlist_print(fp, []byte("ah "), pd.hostile)
 	}
 
+	if vp := rp_char(n); vp != nil {
+		print_char(fp, vp)
+	}
+	if vp := rp_magic(n); vp != nil {
+		print_magic(fp, vp)
+	}
+	if vp := rp_loc(n); vp != nil {
+		print_loc(fp, vp)
+	}
+	if vp := rp_subloc(n); vp != nil {
+		print_subloc(fp, vp)
+	}
+	if vp := rp_item(n); vp != nil {
+		print_item(fp, vp)
+	}
+	if vp := rp_item_magic(n); vp != nil {
+		print_item_magic(fp, vp)
+	}
+	if vp := rp_item_artifact(n); vp != nil {
+		print_artifact(fp, vp)
+	}
+	if vp := rp_player(n); vp != nil {
+		print_player(fp, vp)
+	}
 	if vp := rp_skill(n); vp != nil {
 		print_skill(fp, vp)
 	}
@@ -4126,8 +4171,6 @@ func save_box(fp *os.File, n int) (*Box, error) {
 	fprintf(fp, "\n")
 
 	bx[n].temp = 1 /* mark for write_leftovers() */
-
-	return b, fmt.Errorf("not implemented")
 }
 
 func open_write_fp(fnam string) (*os.File, error) {
@@ -4146,9 +4189,7 @@ func write_kind(box_kind int, fnam string) error {
 		return fmt.Errorf("write_kind: %w", err)
 	}
 	for _, i := range loop_kind(box_kind) {
-		if _, err := save_box(fp, i); err != nil {
-			return fmt.Errorf("write_kind: %w", err)
-		}
+		save_box(fp, i)
 	}
 
 	_ = fp.Close()
@@ -4156,19 +4197,24 @@ func write_kind(box_kind int, fnam string) error {
 	return nil
 }
 
+// write_player saves the player and its units in the format the database is saved in.
 func write_player(pl int) error {
+	if save_format() == DB_JSON {
+		return write_json_player(filepath.Join(libdir, FACTIONS_DIR), pl)
+	}
+	return write_text_player(pl)
+}
+
+func write_text_player(pl int) error {
 	fp, err := open_write_fp(sout("fact/%d", pl))
 	if err != nil {
-		return fmt.Errorf("write_player: %w", err)
-	} else if _, err = save_box(fp, pl); err != nil {
-		return fmt.Errorf("write_player: %w", err)
+		return fmt.Errorf("write_text_player: %w", err)
 	}
 
+	save_box(fp, pl)
 	for _, who := range loop_units(pl) {
 		assert(kind(who) == T_char || kind(who) == T_deleted)
-		if _, err = save_box(fp, who); err != nil {
-			return fmt.Errorf("write_player: %w", err)
-		}
+		save_box(fp, who)
 	}
 
 	_ = fp.Close()
@@ -4178,7 +4224,7 @@ func write_player(pl int) error {
 
 func write_chars() error {
 	for _, pl := range loop_player() {
-		if err := write_player(pl); err != nil {
+		if err := write_text_player(pl); err != nil {
 			return fmt.Errorf("write_chars: %w", err)
 		}
 	}
@@ -4194,9 +4240,7 @@ func write_leftovers() error {
 	for i := 0; i < MAX_BOXES; i++ {
 		if bx[i] != nil && kind(i) != T_nation && bx[i].temp == 0 {
 			if kind(i) != T_deleted {
-				if _, err = save_box(fp, i); err != nil {
-					return fmt.Errorf("write_player: %w", err)
-				}
+				save_box(fp, i)
 			}
 		}
 	}
@@ -4207,10 +4251,6 @@ func write_leftovers() error {
 }
 
 func read_boxes(fnam string) {
-	if fnam != "okay-doay" {
-		panic("!")
-	}
-
 	fnam = filepath.Join(libdir, fnam)
 	if !readfile(fnam) {
 		return
@@ -4249,41 +4289,35 @@ func read_chars() error {
 	return nil
 }
 
+/*
+ *  fast_scan reads libdir/master, which lists the number, kind and
+ *  subkind of every box.  It is much quicker than scanning all the
+ *  entity files.  If master is missing, the caller falls back to
+ *  scan_all_boxes.
+ */
 func fast_scan() error {
-	if _, err := MasterDataLoad(filepath.Join(libdir, "master.json")); err != nil {
-		return fmt.Errorf("fast_scan: %w", err)
-	}
-
-	path := filepath.Join(libdir, "master.json")
+	path := filepath.Join(libdir, "master")
 	if !readfile(path) {
-		return fmt.Errorf("fast_scan: %w", fmt.Errorf("something?"))
+		return fmt.Errorf("fast_scan: can't read %s", path)
 	}
 
 	for s := readlin(); s != nil; s = readlin() {
-		if len(s) == 0 {
+		/*
+		 *  Parse something of the form: box-number kind.subkind
+		 *  example:  10	2.0	fact/10	Osswid
+		 */
+		f := bytes.Fields(s)
+		if len(f) < 2 {
 			continue
 		}
-		num := atoi_b(s)
-
-		p := s
-		for len(p) != 0 && isdigit(p[0]) {
-			p = p[1:]
-		}
-		for len(p) != 0 && iswhite(p[0]) {
-			p = p[1:]
-		}
-
-		var q []byte
-		for n, i := 0, bytes.IndexByte(q, '.'); i != -1; n, i = n+1, bytes.IndexByte(q, '.') {
-			if n == 0 {
-				p = p[:i]
-			}
-			q = q[i:]
+		num := atoi_b(f[0])
+		ks := bytes.SplitN(f[1], []byte{'.'}, 2)
+		if !(num > 0 && num < MAX_BOXES) || len(ks) != 2 {
+			fprintf(os.Stderr, "fast_scan: bad line: %s\n", s)
+			continue
 		}
 
-		kind, sk := atoi_b(p), atoi_b(q)
-
-		alloc_box(num, kind, sk)
+		alloc_box(num, atoi_b(ks[0]), atoi_b(ks[1]))
 	}
 
 	return nil
@@ -4300,69 +4334,38 @@ func scan_boxes(fnam string) {
 			continue
 		}
 
-		/*
-		   if (*s == '#')
-		       continue;
-		*/
-
 		/*
 		 *  Parse something of the form: box-number kind subkind
 		 *  example:  10 item artifact
 		 */
+		f := bytes.Fields(s)
+		box_num := atoi_b(f[0])
 
-		box_num := atoi_b(s)
-
-		/* skip over space to kind */
-		for len(s) != 0 && s[0] != ' ' {
-			s = s[1:]
-		}
-		if len(s) != 0 && s[0] == ' ' {
-			s = s[1:]
-		}
-
-		/* skip to subkind */
-		var t []byte
-		var i int
-		for i = 0; i < len(s) && s[i] != ' '; i++ {
-			//
-		}
-		if i < len(s) && s[i] == ' ' {
-			s, t = s[:i], s[i+i:]
+		kind := -1
+		if len(f) > 1 {
+			kind = lookup_sb(kind_s, f[1])
 		}
-
-		kind := lookup_sb(kind_s, s)
-
 		if kind < 0 {
-			fprintf(os.Stderr, "read_boxes(%d): bad kind: %s\n", box_num, s)
+			fprintf(os.Stderr, "scan_boxes(%d): bad kind: %s\n", box_num, s)
 			kind = 0
 		}
 
-		// todo: this can pass nil to lookup()
-		var sk int
-		if len(t) != 0 && t[0] == '0' {
+		sk := -1
+		if len(f) > 2 && bytes.Equal(f[2], []byte{'0'}) {
 			sk = 0
-		} else {
-			sk = lookup_sb(subkind_s, t)
+		} else if len(f) > 2 {
+			sk = lookup_sb(subkind_s, bytes.Join(f[2:], []byte{' '}))
 		}
 		if sk < 0 {
-			fprintf(os.Stderr, "read_boxes(%d): bad subkind: %s\n", box_num, t)
+			fprintf(os.Stderr, "scan_boxes(%d): bad subkind: %s\n", box_num, s)
 			sk = 0
 		}
 
 		alloc_box(box_num, kind, sk)
 
-		for s = readlin(); s != nil; s = readlin() {
-			if len(s) == 0 || s[0] == '#' { /* skip blank lines and comments */
-				continue
-			}
-
-			for len(s) != 0 && iswhite(s[0]) {
-				s = s[1:]
-			}
-
-			if len(s) == 0 { /* blank line, end of entry */
-				break
-			}
+		/* skip to the blank line that ends the entry */
+		for s = readlin(); s != nil && len(bytes.TrimSpace(s)) != 0; s = readlin() {
+			//
 		}
 	}
 }
@@ -4394,58 +4397,11 @@ func scan_chars() error {
 func scan_all_boxes() error {
 	stage("scan_all_boxes")
 
-	scanOnly := true
-
-	//scan_boxes("loc")
-	if _, err := LocationDataLoad(filepath.Join(libdir, "locations.json"), scanOnly); err != nil {
-		return fmt.Errorf("scan_all_boxes: %s: %w", "loc", err)
-	}
-
-	//scan_boxes("item")
-	if _, err := EntityItemDataLoad(filepath.Join(libdir, "items.json"), scanOnly); err != nil {
-		return fmt.Errorf("scan_all_boxes: %s: %w", "items", err)
-	}
-
-	//scan_boxes("skill")
-	if _, err := SkillDataLoad(filepath.Join(libdir, "skills.json"), scanOnly); err != nil {
-		return fmt.Errorf("scan_all_boxes: %s: %w", "skills", err)
+	for _, fnam := range []string{"loc", "item", "skill", "gate", "road", "ship", "unform", "misc", "nation"} {
+		scan_boxes(fnam)
 	}
-
-	//scan_boxes("gate")
-	if _, err := GateDataLoad(filepath.Join(libdir, "gates.json"), scanOnly); err != nil {
-		return fmt.Errorf("scan_all_boxes: %s: %w", "gates", err)
-	}
-
-	//scan_boxes("road")
-	if _, err := RoadDataLoad(filepath.Join(libdir, "roads.json"), scanOnly); err != nil {
-		return fmt.Errorf("scan_all_boxes: %s: %w", "roads", err)
-	}
-
-	//scan_boxes("ship")
-	if _, err := ShipDataLoad(filepath.Join(libdir, "ships.json"), scanOnly); err != nil {
-		return fmt.Errorf("scan_all_boxes: %s: %w", "ships", err)
-	}
-
-	//scan_boxes("unform")
-	if _, err := UnformDataLoad(filepath.Join(libdir, "unform.json"), scanOnly); err != nil {
-		return fmt.Errorf("scan_all_boxes: %s: %w", "unform", err)
-	}
-
-	//scan_boxes("misc")
-	if _, err := MiscDataLoad(filepath.Join(libdir, "misc.json"), scanOnly); err != nil {
-		return fmt.Errorf("scan_all_boxes: %s: %w", "misc", err)
-	}
-
-	//scan_boxes("nation")
-	if _, err := NationDataLoad(filepath.Join(libdir, "nations.json"), scanOnly); err != nil {
-		return fmt.Errorf("scan_all_boxes: %s: %w", "nations", err)
-	}
-
-	//if err := scan_chars(); err != nil {
-	//	log.Printf("scan_all_boxes: %+v\n", err)
-	//}
-	if err := CharactersLoad(scanOnly); err != nil {
-		return fmt.Errorf("scan_all_boxes: %s: %w", "characters", err)
+	if err := scan_chars(); err != nil {
+		return fmt.Errorf("scan_all_boxes: %w", err)
 	}
 
 	return nil
@@ -4454,58 +4410,11 @@ func scan_all_boxes() error {
 func read_all_boxes() error {
 	stage("read_all_boxes")
 
-	scanOnly := false
-
-	//read_boxes("loc")
-	if _, err := LocationDataLoad(filepath.Join(libdir, "locations.json"), scanOnly); err != nil {
-		return fmt.Errorf("read_all_boxes: %s: %w", "loc", err)
-	}
-
-	//read_boxes("item")
-	if _, err := EntityItemDataLoad(filepath.Join(libdir, "items.json"), scanOnly); err != nil {
-		return fmt.Errorf("read_all_boxes: %s: %w", "items", err)
-	}
-
-	//read_boxes("skill")
-	if _, err := SkillDataLoad(filepath.Join(libdir, "skills.json"), scanOnly); err != nil {
-		return fmt.Errorf("read_all_boxes: %s: %w", "skills", err)
-	}
-
-	//read_boxes("gate")
-	if _, err := GateDataLoad(filepath.Join(libdir, "gates.json"), scanOnly); err != nil {
-		return fmt.Errorf("read_all_boxes: %s: %w", "gates", err)
+	for _, fnam := range []string{"loc", "item", "skill", "gate", "road", "ship", "unform", "misc", "nation"} {
+		read_boxes(fnam)
 	}
-
-	//read_boxes("road")
-	if _, err := RoadDataLoad(filepath.Join(libdir, "roads.json"), scanOnly); err != nil {
-		return fmt.Errorf("read_all_boxes: %s: %w", "roads", err)
-	}
-
-	//read_boxes("ship")
-	if _, err := ShipDataLoad(filepath.Join(libdir, "ships.json"), scanOnly); err != nil {
-		return fmt.Errorf("read_all_boxes: %s: %w", "ships", err)
-	}
-
-	//read_boxes("unform")
-	if _, err := UnformDataLoad(filepath.Join(libdir, "unform.json"), scanOnly); err != nil {
-		return fmt.Errorf("read_all_boxes: %s: %w", "unform", err)
-	}
-
-	//read_boxes("misc")
-	if _, err := MiscDataLoad(filepath.Join(libdir, "misc.json"), scanOnly); err != nil {
-		return fmt.Errorf("read_all_boxes: %s: %w", "misc", err)
-	}
-
-	//read_boxes("nation")
-	if _, err := NationDataLoad(filepath.Join(libdir, "nations.json"), scanOnly); err != nil {
-		return fmt.Errorf("read_all_boxes: %s: %w", "nations", err)
-	}
-
-	//if err := read_chars(); err != nil {
-	//	log.Printf("read_all_boxes: %+v\n", err)
-	//}
-	if err := CharactersLoad(scanOnly); err != nil {
-		return fmt.Errorf("read_all_boxes: %s: %w", "characters", err)
+	if err := read_chars(); err != nil {
+		return fmt.Errorf("read_all_boxes: %w", err)
 	}
 
 	return nil
@@ -4760,17 +4669,38 @@ func load_db() error {
 	if err := load_system(); err != nil {
 		return fmt.Errorf("load_db: %w", err)
 	}
+	if err := load_seed(filepath.Join(libdir, SEED_FILE)); err != nil {
+		return fmt.Errorf("load_db: %w", err)
+	}
+	if err := load_ledger(ledger_path()); err != nil {
+		return fmt.Errorf("load_db: %w", err)
+	}
+	if err := load_command_defs(); err != nil {
+		return fmt.Errorf("load_db: %w", err)
+	}
 
-	// pass 1: call alloc_box for each entity
-	if err := fast_scan(); err != nil {
-		log.Printf("load_db: fast_scan failed\n")
-		if err := scan_all_boxes(); err != nil {
+	switch load_format() {
+	case DB_JSON:
+		if err := read_json_boxes(); err != nil {
 			return fmt.Errorf("load_db: %w", err)
 		}
-	}
+	case DB_TEXT:
+		// pass 1: call alloc_box for each entity
+		if err := fast_scan(); err != nil {
+			log.Printf("load_db: %v: scanning all boxes\n", err)
+			if err := scan_all_boxes(); err != nil {
+				return fmt.Errorf("load_db: %w", err)
+			}
+		}
 
-	// pass 2: read the entity attributes
-	if err := read_all_boxes(); err != nil {
+		// pass 2: read the entity attributes
+		if err := read_all_boxes(); err != nil {
+			return fmt.Errorf("load_db: %w", err)
+		}
+	default:
+		return fmt.Errorf("load_db: unknown db format %q", load_format())
+	}
+	if err := load_rules(); err != nil {
 		return fmt.Errorf("load_db: %w", err)
 	}
 
@@ -4889,7 +4819,7 @@ func save_logdir() error {
 		return fmt.Errorf("save_logdir: %w", err)
 	}
 
-	s = filepath.Join(libdir, "players.html", libdir)
+	s = filepath.Join(libdir, "players.html")
 	t = filepath.Join(libdir, "save", fmt.Sprintf("%d", sysclock.turn), "players.html")
 
 	if err := rename(s, t); err != nil {
@@ -4906,13 +4836,30 @@ func save_db() error {
 	cleanup_posts()
 	if err := save_system(); err != nil {
 		return fmt.Errorf("save_db: %w", err)
-	} else if err = write_all_boxes(); err != nil {
+	} else if err := save_seed(filepath.Join(libdir, SEED_FILE)); err != nil {
 		return fmt.Errorf("save_db: %w", err)
-	} else if err = write_master(); err != nil {
+	} else if err := save_ledger(ledger_path()); err != nil {
 		return fmt.Errorf("save_db: %w", err)
-	} else if err = save_orders(); err != nil {
+	}
+
+	switch save_format() {
+	case DB_JSON:
+		if err := write_json_boxes(); err != nil {
+			return fmt.Errorf("save_db: %w", err)
+		}
+	case DB_TEXT:
+		if err := write_all_boxes(); err != nil {
+			return fmt.Errorf("save_db: %w", err)
+		} else if err = write_master(); err != nil {
+			return fmt.Errorf("save_db: %w", err)
+		}
+	default:
+		return fmt.Errorf("save_db: unknown db format %q", save_format())
+	}
+
+	if err := save_orders(); err != nil {
 		return fmt.Errorf("save_db: %w", err)
-	} else if err = rename_act_join_files(); err != nil {
+	} else if err = retire_join_files(); err != nil {
 		return fmt.Errorf("save_db: %w", err)
 	}
 
@@ -257,11 +257,11 @@ type EntityItem struct {
 	PluralName    string          `json:"plural-name,omitempty"`   //
 	Kind          string          `json:"kind,omitempty"`          //
 	SubKind       string          `json:"sub-kind,omitempty"`      //
-	Animal        bool            `json:"animal,omitempty"`        // unit is or contains a horse or an ox
+	Animal        int             `json:"animal,omitempty"`        // unit is or contains a horse or an ox
 	AnimalPart    int             `json:"animal_part,omitempty"`   // Produces this when killed.
 	Attack        int             `json:"attack,omitempty"`        // fighter attack rating
 	BasePrice     int             `json:"base_price,omitempty"`    // base price of item for market seeding
-	Capturable    bool            `json:"capturable,omitempty"`    // ni-char contents are capturable
+	Capturable    int             `json:"capturable,omitempty"`    // ni-char contents are capturable
 	Defense       int             `json:"defense,omitempty"`       // fighter defense rating
 	FlyCap        int             `json:"fly-cap,omitempty"`       //
 	IsManItem     int             `json:"is_man_item,omitempty"`   // unit is a character like thing
@@ -269,13 +269,13 @@ type EntityItem struct {
 	Maintenance   int             `json:"maintenance,omitempty"`   // Maintenance cost
 	Missile       int             `json:"missle,omitempty"`        // capable of missile attacks?
 	NpcSplit      int             `json:"npc_split,omitempty"`     // Size to "split" at...
-	Prominent     bool            `json:"prominent,omitempty"`     // big things that everyone sees
+	Prominent     int             `json:"prominent,omitempty"`     // big things that everyone sees
 	RideCap       int             `json:"ride-cap,omitempty"`      //
-	TradeGood     bool            `json:"trade_good,omitempty"`    // Is this thing a trade good? & how much
-	Ungiveable    bool            `json:"ungiveable,omitempty"`    //  Can't be transferred between nobles.
+	TradeGood     int             `json:"trade_good,omitempty"`    // Is this thing a trade good? & how much
+	Ungiveable    int             `json:"ungiveable,omitempty"`    //  Can't be transferred between nobles.
 	Weight        int             `json:"weight,omitempty"`        //
 	WhoHas        int             `json:"who_has,omitempty"`       // who has this unique item
-	Wild          bool            `json:"wild,omitempty"`          // appears in the wild as a random encounter. (value is actually the NPC_prog.)
+	Wild          int             `json:"wild,omitempty"`          // appears in the wild as a random encounter. (value is actually the NPC_prog.)
 	XItemArtifact *EntityArtifact `json:"item-artifact,omitempty"` // eventually will replace XItemMagic
 	XItemMagic    *ItemMagic      `json:"item-magic,omitempty"`    // will be replaced by XItemArtifact
 }
@@ -343,24 +343,12 @@ func (e *EntityItem) toBox() *entity_item {
 		ride_cap:    e.RideCap,
 		weight:      e.Weight,
 		who_has:     e.WhoHas,
-	}
-	if e.Animal {
-		ei.animal = TRUE
-	}
-	if e.Prominent {
-		ei.prominent = TRUE
-	}
-	if e.Capturable {
-		ei.capturable = TRUE
-	}
-	if e.Ungiveable {
-		ei.ungiveable = TRUE
-	}
-	if e.Wild {
-		ei.wild = TRUE
-	}
-	if e.TradeGood {
-		ei.trade_good = TRUE
+		animal:      e.Animal,
+		prominent:   e.Prominent,
+		capturable:  e.Capturable,
+		ungiveable:  e.Ungiveable,
+		wild:        e.Wild,
+		trade_good:  e.TradeGood,
 	}
 	return ei
 }
@@ -0,0 +1,449 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// JOINS_DIR holds the queue of join requests, relative to libdir.
+// Requests that are added move to JOINS_DIR/added and requests that
+// are rejected move to JOINS_DIR/rejected when the database is saved.
+const JOINS_DIR = "joins"
+
+var (
+	ErrBadNation        = errors.New("bad nation")
+	ErrBadStartCity     = errors.New("bad start city")
+	ErrDuplicateAccount = errors.New("duplicate account")
+	ErrNationFull       = errors.New("nation is full")
+)
+
+// JoinRequest is a new player's signup.
+// Account is the faction code to use; if it is empty, one is assigned when
+// the player is added. StartCity is a city code, "random", or empty for the
+// nation's capital.
+type JoinRequest struct {
+	Account   string `json:"account,omitempty"`
+	Faction   string `json:"faction"`
+	Character string `json:"character"`
+	FullName  string `json:"full-name,omitempty"`
+	EMail     string `json:"e-mail"`
+	Password  string `json:"password,omitempty"`
+	Nation    string `json:"nation"`
+	StartCity string `json:"start-city,omitempty"`
+}
+
+// JoinResult is the outcome of checking a join request.
+type JoinResult struct {
+	Name    string       `json:"name"` // where the request came from
+	Request *JoinRequest `json:"request"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// ParseJoinRequests decodes a single join request or a list of them.
+// Unknown fields are an error so that typos aren't silently dropped.
+func ParseJoinRequests(data []byte) ([]*JoinRequest, error) {
+	data = bytes.TrimSpace(data)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	var list []*JoinRequest
+	if len(data) != 0 && data[0] == '[' {
+		if err := dec.Decode(&list); err != nil {
+			return nil, fmt.Errorf("join request: %w", err)
+		}
+	} else {
+		j := &JoinRequest{}
+		if err := dec.Decode(j); err != nil {
+			return nil, fmt.Errorf("join request: %w", err)
+		}
+		list = append(list, j)
+	}
+	return list, nil
+}
+
+// QueueJoins checks the requests against the database and the joins already
+// queued, and queues the ones that pass. With dryRun, nothing is queued.
+// Queued requests don't count against a nation's player limit; the limit
+// is enforced when the turn adds the players.
+func QueueJoins(joins []*JoinRequest, dryRun bool, options ...Option) (results []JoinResult, err error) {
+	defer recover_turn("QueueJoins", &err)
+	defer unlock_tag()
+
+	if err := start_turn(options...); err != nil {
+		return nil, fmt.Errorf("QueueJoins: %w", err)
+	}
+
+	return queue_joins(joins, dryRun)
+}
+
+// CheckJoins reports which of the queued join requests would be added
+// if the turn were run now.
+func CheckJoins(options ...Option) (results []JoinResult, err error) {
+	defer recover_turn("CheckJoins", &err)
+	defer unlock_tag()
+
+	if err := start_turn(options...); err != nil {
+		return nil, fmt.Errorf("CheckJoins: %w", err)
+	}
+
+	names, joins, err := pending_joins()
+	if err != nil {
+		return nil, fmt.Errorf("CheckJoins: %w", err)
+	}
+	b := new_join_batch()
+	for i, j := range joins {
+		r := JoinResult{Name: names[i], Request: j}
+		if _, _, _, err := b.check(j); err != nil {
+			r.Error = err.Error()
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// queue_joins checks and queues the requests.
+// The caller must have loaded the database.
+func queue_joins(joins []*JoinRequest, dryRun bool) (results []JoinResult, err error) {
+	_, pending, err := pending_joins()
+	if err != nil {
+		return nil, fmt.Errorf("queue_joins: %w", err)
+	}
+	b := new_join_batch()
+	for _, j := range pending {
+		_, _, _, _ = b.check(j)
+	}
+	// Pending requests keep their accounts, but anyone can queue a request,
+	// so they must not be able to fill a nation before the turn runs.
+	b.nations = make(map[int]int)
+
+	for _, j := range joins {
+		r := JoinResult{Request: j}
+		if pl, _, _, err := b.check(j); err != nil {
+			r.Error = err.Error()
+		} else if !dryRun {
+			if r.Name, err = write_join(j, pl); err != nil {
+				return results, fmt.Errorf("queue_joins: %w", err)
+			}
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// write_join saves the request in the queue.
+// The file is named for the account that check returned, if there is one,
+// never for the account as the request spelled it.
+func write_join(j *JoinRequest, pl int) (string, error) {
+	dir := filepath.Join(libdir, JOINS_DIR)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("j%d.json", time.Now().UnixNano())
+	if pl != 0 {
+		name = box_code_less(pl) + ".json"
+	}
+
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	tmp := filepath.Join(dir, "."+name)
+	if err := os.WriteFile(tmp, data, 0666); err != nil {
+		return "", err
+	} else if err := os.Rename(tmp, filepath.Join(dir, name)); err != nil {
+		_ = os.Remove(tmp)
+		return "", err
+	}
+	return name, nil
+}
+
+// pending_joins returns the queued requests in name order.
+// A request that can't be read is returned with a nil entry.
+func pending_joins() (names []string, joins []*JoinRequest, err error) {
+	dir := filepath.Join(libdir, JOINS_DIR)
+	files, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		names = append(names, f.Name())
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var j *JoinRequest
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err != nil {
+			log.Printf("pending_joins: %v\n", err)
+		} else if list, err := ParseJoinRequests(data); err != nil || len(list) != 1 {
+			log.Printf("pending_joins: %s: not a join request\n", name)
+		} else {
+			j = list[0]
+		}
+		joins = append(joins, j)
+	}
+	return names, joins, nil
+}
+
+// join_batch tracks the accounts and nations claimed by the requests
+// checked so far, so that a batch is checked as if the earlier requests
+// had already been added.
+type join_batch struct {
+	accounts map[int]bool
+	nations  map[int]int
+}
+
+func new_join_batch() *join_batch {
+	return &join_batch{accounts: make(map[int]bool), nations: make(map[int]int)}
+}
+
+// check validates the request and returns the account (zero if one is to
+// be assigned), nation, and start city for it. If the request is valid,
+// the batch is updated.
+func (b *join_batch) check(j *JoinRequest) (pl, nat, start int, err error) {
+	if j == nil {
+		return 0, 0, 0, fmt.Errorf("not a join request")
+	} else if strings.TrimSpace(j.Faction) == "" {
+		return 0, 0, 0, fmt.Errorf("missing faction name")
+	} else if strings.TrimSpace(j.Character) == "" {
+		return 0, 0, 0, fmt.Errorf("missing character name")
+	} else if !is_bare_address(j.EMail) {
+		return 0, 0, 0, fmt.Errorf("missing or invalid e-mail address %q", j.EMail)
+	} else if strings.TrimSpace(j.Nation) == "" {
+		return 0, 0, 0, fmt.Errorf("missing nation: %w", ErrBadNation)
+	}
+
+	if account := strings.TrimSpace(j.Account); account != "" {
+		pl = scode([]byte(strings.ToLower(account)))
+		if pl <= 0 || pl >= MAX_BOXES || box_code_less(pl) != strings.ToLower(account) {
+			return 0, 0, 0, fmt.Errorf("account %q: not a faction code", account)
+		} else if bx[pl] != nil || b.accounts[pl] {
+			return 0, 0, 0, fmt.Errorf("account %q: %w", account, ErrDuplicateAccount)
+		}
+	}
+
+	if nat = find_nation(strings.TrimSpace(j.Nation)); nat == 0 {
+		return 0, 0, 0, fmt.Errorf("nation %q: %w", j.Nation, ErrBadNation)
+	}
+	if limit := rp_nation(nat).player_limit; limit != 0 {
+		total := b.nations[nat]
+		for _, i := range loop_player() {
+			if nation(i) == nat {
+				total++
+			}
+		}
+		if total >= limit {
+			return 0, 0, 0, fmt.Errorf("%s has reached its limit of %d players: %w", rp_nation(nat).name, limit, ErrNationFull)
+		}
+	}
+
+	switch city := strings.TrimSpace(j.StartCity); {
+	case city == "":
+		start = rp_nation(nat).capital
+	case strings.HasPrefix(strings.ToLower(city), "rand"):
+		start = RANDOM_START
+	default:
+		start = scode([]byte(strings.ToLower(city)))
+		if !valid_box(start) || subkind(start) != sub_city {
+			return 0, 0, 0, fmt.Errorf("start city %q: not a city: %w", city, ErrBadStartCity)
+		} else if nation(player_controls_loc(start)) != nat {
+			return 0, 0, 0, fmt.Errorf("start city %q: not controlled by %s: %w", city, rp_nation(nat).name, ErrBadStartCity)
+		}
+	}
+
+	if pl != 0 {
+		b.accounts[pl] = true
+	}
+	b.nations[nat]++
+	return pl, nat, start, nil
+}
+
+// is_bare_address returns true if s is a single e-mail address with no
+// display name, comment, or angle brackets.
+func is_bare_address(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Name == "" && addr.Address == s
+}
+
+// join_file is a request that was added or rejected this turn.
+// save_db moves it out of the queue so that it isn't processed again.
+type join_file struct {
+	from, to string
+	result   *JoinResult // written to the new location if set
+}
+
+var join_files []join_file
+
+// make_new_players adds the players from the queued join requests, and
+// from the legacy Join-tag files in the accounting directory.
+func make_new_players() {
+	b := new_join_batch()
+
+	names, joins, err := pending_joins()
+	if err != nil {
+		log.Printf("make_new_players: %v\n", err)
+	}
+	dir := filepath.Join(libdir, JOINS_DIR)
+	for i, j := range joins {
+		r := &JoinResult{Name: names[i], Request: j}
+		if err := add_join(b, j); err != nil {
+			r.Error = err.Error()
+			join_files = append(join_files, join_file{from: filepath.Join(dir, names[i]), to: filepath.Join(dir, "rejected", names[i]), result: r})
+			continue
+		}
+		join_files = append(join_files, join_file{from: filepath.Join(dir, names[i]), to: filepath.Join(dir, "added", names[i])})
+	}
+
+	if options.accounting_dir == "" {
+		return
+	}
+	files, err := os.ReadDir(options.accounting_dir)
+	if err != nil {
+		log.Printf("make_new_players: %v\n", err)
+		return
+	}
+	for _, f := range files {
+		if strings.HasPrefix(f.Name(), ".") || !f.IsDir() {
+			continue
+		}
+		acct := f.Name()
+		fnam := filepath.Join(options.accounting_dir, acct, fmt.Sprintf("Join-tag-%d", game_number))
+		j, err := read_legacy_join(acct, fnam)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			log.Printf("make_new_players: %s: %v\n", acct, err)
+			out(gm_player, "Couldn't add player %s: %v.", acct, err)
+			continue
+		}
+		if err := add_join(b, j); err != nil {
+			continue
+		}
+		join_files = append(join_files, join_file{from: fnam, to: fnam + "-"})
+	}
+}
+
+// add_join checks the request and adds the player.
+// Failures are reported to the GM.
+func add_join(b *join_batch, j *JoinRequest) error {
+	pl, nat, start, err := b.check(j)
+	if err != nil {
+		who := "?"
+		if j != nil {
+			who = fmt.Sprintf("%s <%s>", j.FullName, j.EMail)
+		}
+		log.Printf("make_new_players: unable to add %s: %v\n", who, err)
+		out(gm_player, "Couldn't add player %s: %v.", who, err)
+		return err
+	}
+
+	if pl == 0 {
+		pl = new_ent(T_player, sub_pl_regular)
+	} else {
+		alloc_box(pl, T_player, sub_pl_regular)
+	}
+
+	add_new_player(pl, j.Faction, j.Character, j.FullName, j.EMail, nat, start)
+	if j.Password != "" {
+		p_player(pl).Password = j.Password
+	}
+	// the player exists now, so check counts it with the others in the nation
+	b.nations[nat]--
+	log.Printf("\tadded player %s\n", box_name(pl))
+
+	return nil
+}
+
+// read_legacy_join reads the positional format written by the old
+// accounting system: faction, character, full name, e-mail, nation,
+// and an optional start city, one per line.
+func read_legacy_join(acct, fnam string) (*JoinRequest, error) {
+	fp, err := os.Open(fnam)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+
+	j := &JoinRequest{Account: acct}
+	for _, field := range []struct {
+		name  string
+		value *string
+	}{
+		{"faction", &j.Faction},
+		{"character", &j.Character},
+		{"full name", &j.FullName},
+		{"e-mail", &j.EMail},
+		{"nation", &j.Nation},
+	} {
+		if *field.value = fetch_inp(fp); *field.value == "" {
+			return nil, fmt.Errorf("%s: partial read of %s", fnam, field.name)
+		}
+	}
+	j.StartCity = fetch_inp(fp)
+
+	return j, nil
+}
+
+// retire_join_files moves this turn's join requests out of the queue.
+func retire_join_files() error {
+	for _, f := range join_files {
+		if err := os.MkdirAll(filepath.Dir(f.to), 0755); err != nil {
+			return fmt.Errorf("retire_join_files: %w", err)
+		}
+		if f.result != nil {
+			r := *f.result
+			if r.Request != nil {
+				// the player wasn't added, so the password isn't kept
+				j := *r.Request
+				j.Password = ""
+				r.Request = &j
+			}
+			data, err := json.MarshalIndent(r, "", "  ")
+			if err != nil {
+				return fmt.Errorf("retire_join_files: %w", err)
+			} else if err := os.WriteFile(f.to, data, 0666); err != nil {
+				return fmt.Errorf("retire_join_files: %w", err)
+			} else if err := os.Remove(f.from); err != nil {
+				return fmt.Errorf("retire_join_files: %w", err)
+			}
+			continue
+		}
+		if err := rename(f.from, f.to); err != nil {
+			return fmt.Errorf("retire_join_files: %w", err)
+		}
+	}
+	join_files = nil
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestAddJoinNationLimit adds players to a nation with a limit of two.
+// The turn must accept the same requests that the dry run accepts.
+func TestAddJoinNationLimit(t *testing.T) {
+	defer clear_boxes()
+	clear_boxes()
+
+	const prov, city, nat = 10101, 36200, 5001
+	for _, item := range []int{item_gold, item_peasant, item_lumber} {
+		alloc_box(item, T_item, 0)
+	}
+	alloc_box(prov, T_loc, sub_plain)
+	alloc_box(city, T_loc, sub_city)
+	set_where(city, prov)
+	alloc_box(nat, T_nation, 0)
+	p_nation(nat).name = "Mandor Empire"
+	p_nation(nat).player_limit = 2
+	p_nation(nat).capital = city
+
+	join := func(i int) *JoinRequest {
+		return &JoinRequest{
+			Faction:   fmt.Sprintf("Faction %d", i),
+			Character: fmt.Sprintf("Noble %d", i),
+			EMail:     fmt.Sprintf("player%d@example.com", i),
+			Nation:    "Mandor",
+		}
+	}
+
+	b := new_join_batch()
+	for i := 1; i <= 2; i++ {
+		if err := add_join(b, join(i)); err != nil {
+			t.Fatalf("join %d: %v", i, err)
+		}
+	}
+	if n := len(loop_player()); n != 2 {
+		t.Fatalf("players: want 2, got %d", n)
+	}
+	if _, _, _, err := b.check(join(3)); !errors.Is(err, ErrNationFull) {
+		t.Errorf("join 3: want %v, got %v", ErrNationFull, err)
+	}
+}
+
+// TestJoinEMail accepts only a single bare address.
+func TestJoinEMail(t *testing.T) {
+	defer clear_boxes()
+	clear_boxes()
+
+	for _, tc := range []struct {
+		email string
+		ok    bool
+	}{
+		{"player@example.com", true},
+		{"", false},
+		{"player", false},
+		{"Player <player@example.com>", false},
+		{"<player@example.com>", false},
+		{"player@example.com (Player)", false},
+		{"player@example.com, other@example.com", false},
+		{" player@example.com", false},
+	} {
+		j := &JoinRequest{Faction: "Faction", Character: "Noble", EMail: tc.email, Nation: "Mandor"}
+		// there are no nations, so a request that gets past the address fails on the nation
+		_, _, _, err := new_join_batch().check(j)
+		if ok := errors.Is(err, ErrBadNation); ok != tc.ok {
+			t.Errorf("%q: want ok %v, got %v", tc.email, tc.ok, err)
+		}
+	}
+}
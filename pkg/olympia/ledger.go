@@ -0,0 +1,157 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LEDGER_FILE holds the player accounts, relative to libdir.
+const LEDGER_FILE = "ledger.json"
+
+// Account is a player's billing account.
+// Amounts are in cents; charges are negative and credits are positive.
+type Account struct {
+	Player  int            `json:"player"`
+	Balance int            `json:"balance"`
+	Entries []*LedgerEntry `json:"entries,omitempty"`
+}
+
+// LedgerEntry is a single charge or credit.
+type LedgerEntry struct {
+	Turn    int    `json:"turn"`
+	Amount  int    `json:"amount"`
+	Balance int    `json:"balance"` // after this entry
+	Memo    string `json:"memo,omitempty"`
+}
+
+var ledger = make(map[int]*Account)
+
+// load_ledger reads the accounts. A missing file is an empty ledger.
+func load_ledger(name string) error {
+	ledger = make(map[int]*Account)
+
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("load_ledger: %w", err)
+	}
+
+	var list []*Account
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("load_ledger: %s: %w", name, err)
+	}
+	for _, a := range list {
+		ledger[a.Player] = a
+	}
+	return nil
+}
+
+// save_ledger writes the accounts in player order.
+func save_ledger(name string) error {
+	list := []*Account{}
+	for _, a := range ledger {
+		list = append(list, a)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Player < list[j].Player
+	})
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("save_ledger: %w", err)
+	} else if err := os.WriteFile(name, data, 0666); err != nil {
+		return fmt.Errorf("save_ledger: %w", err)
+	}
+	return nil
+}
+
+// player_account returns the player's account, opening one if needed.
+func player_account(pl int) *Account {
+	a, ok := ledger[pl]
+	if !ok {
+		a = &Account{Player: pl}
+		ledger[pl] = a
+	}
+	return a
+}
+
+func account_balance(pl int) int {
+	if a, ok := ledger[pl]; ok {
+		return a.Balance
+	}
+	return 0
+}
+
+// post adds an entry to the account and updates the balance.
+func (a *Account) post(amount int, memo string) {
+	a.Balance += amount
+	a.Entries = append(a.Entries, &LedgerEntry{Turn: sysclock.turn, Amount: amount, Balance: a.Balance, Memo: memo})
+}
+
+// parse_money converts an amount like "2.50" into cents.
+func parse_money(s string) (int, error) {
+	s = strings.TrimSpace(s)
+	sign := 1
+	if strings.HasPrefix(s, "-") {
+		sign, s = -1, s[1:]
+	}
+	units, frac, _ := strings.Cut(s, ".")
+	if units == "" {
+		units = "0"
+	}
+	if len(frac) > 2 {
+		return 0, fmt.Errorf("parse_money: %q: too many decimal places", s)
+	}
+	for len(frac) < 2 {
+		frac += "0"
+	}
+	n, err := strconv.Atoi(units)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("parse_money: %q: not an amount", s)
+	}
+	cents, err := strconv.Atoi(frac)
+	if err != nil || cents < 0 {
+		return 0, fmt.Errorf("parse_money: %q: not an amount", s)
+	}
+	return sign * (n*100 + cents), nil
+}
+
+// money formats cents as an amount like "2.50".
+func money(cents int) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+// ledger_path returns the name of the ledger file.
+func ledger_path() string {
+	return filepath.Join(libdir, LEDGER_FILE)
+}
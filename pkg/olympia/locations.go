@@ -222,12 +222,21 @@ func (b *box) ToLocationInfo() *LocationInfo {
 	return li
 }
 
+func (li *LocationInfo) toBox() loc_info {
+	if li == nil {
+		return loc_info{}
+	}
+	return loc_info{
+		where:     li.Where,
+		here_list: append([]int(nil), li.HereList...),
+	}
+}
+
 func (li loc_info) IsZero() bool {
 	return li.where == 0 && len(li.here_list) == 0
 }
 
 type EntityLoc struct {
-	Barrier       int            `json:"barrier,omitempty"`         // magical barrier
 	Control       *LocControlEnt `json:"control,omitempty"`         //
 	DistFromGate  int            `json:"dist-from-gate,omitempty"`  //
 	DistFromSea   int            `json:"dist-from-sea,omitempty"`   // provinces to sea province
@@ -236,7 +245,6 @@ type EntityLoc struct {
 	MineInfo      *EntityMine    `json:"mine-info,omitempty"`       // If there's a mine.
 	NearGrave     int            `json:"near-grave,omitempty"`      // nearest graveyard
 	ProvDest      ints_l         `json:"prov-dest,omitempty"`       // cached exits for flood fills
-	Recruited     int            `json:"recruited,omitempty"`       // How many recruited this month
 	SeaLane       int            `json:"sea-lane,omitempty"`        // fast ocean travel here, also "tracks" for npc ferries
 	Shroud        int            `json:"shroud,omitempty"`          // magical scry shroud
 	TaxRate       int            `json:"tax-rate,omitempty"`        // Tax rate for this loc.
@@ -252,7 +260,6 @@ func (e *entity_loc) ToEntityLoc() *EntityLoc {
 		return nil
 	}
 	el := &EntityLoc{
-		Barrier:       e.barrier,
 		Control:       e.control.ToLocControlEnt(),
 		DistFromGate:  e.dist_from_gate,
 		DistFromSea:   e.dist_from_sea,
@@ -261,7 +268,6 @@ func (e *entity_loc) ToEntityLoc() *EntityLoc {
 		MineInfo:      e.mine_info.ToEntityMine(),
 		NearGrave:     e.near_grave,
 		ProvDest:      e.prov_dest,
-		Recruited:     e.recruited,
 		SeaLane:       e.sea_lane,
 		Shroud:        e.shroud,
 		TaxRate:       e.tax_rate,
@@ -270,6 +276,25 @@ func (e *entity_loc) ToEntityLoc() *EntityLoc {
 	return el
 }
 
+func (e *EntityLoc) toBox() *entity_loc {
+	if e == nil {
+		return nil
+	}
+	return &entity_loc{
+		prov_dest:       append([]int(nil), e.ProvDest...),
+		near_grave:      e.NearGrave,
+		shroud:          e.Shroud,
+		tax_rate:        e.TaxRate,
+		hidden:          e.Hidden,
+		dist_from_sea:   e.DistFromSea,
+		dist_from_swamp: e.DistFromSwamp,
+		dist_from_gate:  e.DistFromGate,
+		sea_lane:        e.SeaLane,
+		mine_info:       e.MineInfo.toBox(),
+		control:         e.Control.toBox(),
+	}
+}
+
 // LocControlEnt is fees plus open/closed
 type LocControlEnt struct {
 	Open   bool `json:"open,omitempty"`
@@ -279,7 +304,7 @@ type LocControlEnt struct {
 }
 
 func (e *loc_control_ent) IsZero() bool {
-	return e == nil || (e.closed && e.men == 0 && e.nobles == 0 && e.weight == 0)
+	return e == nil || (!e.closed && e.men == 0 && e.nobles == 0 && e.weight == 0)
 }
 
 func (e *loc_control_ent) ToLocControlEnt() *LocControlEnt {
@@ -294,6 +319,18 @@ func (e *loc_control_ent) ToLocControlEnt() *LocControlEnt {
 	}
 }
 
+func (e *LocControlEnt) toBox() loc_control_ent {
+	if e == nil {
+		return loc_control_ent{}
+	}
+	return loc_control_ent{
+		closed: !e.Open,
+		nobles: e.Nobles,
+		men:    e.Men,
+		weight: e.Weight,
+	}
+}
+
 type EntitySubLoc struct {
 	BoundStorms  ints_l          `json:"bound-storms,omitempty"`  // storms bound to this ship
 	Builds       EntityBuildList `json:"builds,omitempty"`        // Ongoing builds here.
@@ -313,13 +350,12 @@ type EntitySubLoc struct {
 	Prominence   int             `json:"prominence,omitempty"`    // prominence of city
 	Safe         bool            `json:"safe,omitempty"`          // safe haven
 	TaxMarket    int             `json:"tax-market,omitempty"`    // Market tax rate.
-	TaxMarket2   int             `json:"tax-market-2,omitempty"`  // Temporary until end of month
 	Teaches      ints_l          `json:"teaches,omitempty"`       // skills location offers
 	XShip        *EntityShip     `json:"x-ship,omitempty"`        // Maybe a ship?
 
-	// location control -- either here or loc
-	Control  *LocControlEnt `json:"control,omitempty"`   //
-	Control2 *LocControlEnt `json:"control-2,omitempty"` //
+	// location control -- either here or loc.
+	// control2 holds changes until the end of the month and isn't saved.
+	Control *LocControlEnt `json:"control,omitempty"` //
 
 	//short shaft_depth;		/* depth of mine shaft */
 	//int capacity;			/* capacity of ship */
@@ -352,10 +388,37 @@ func (e *entity_subloc) ToEntitySubLoc() *EntitySubLoc {
 		Prominence:   e.prominence,
 		Safe:         e.safe,
 		TaxMarket:    e.tax_market,
-		TaxMarket2:   e.tax_market2,
 		Teaches:      e.teaches.ToList(),
 		XShip:        e.x_ship.ToEntityShip(),
 		Control:      e.control.ToLocControlEnt(),
-		Control2:     e.control2.ToLocControlEnt(),
+	}
+}
+
+func (e *EntitySubLoc) toBox() *entity_subloc {
+	if e == nil {
+		return nil
+	}
+	return &entity_subloc{
+		teaches:       append(ints_l(nil), e.Teaches...),
+		opium_econ:    e.OpiumEcon,
+		defense:       e.Defense,
+		loot:          e.Loot,
+		hp:            e.Hp,
+		damage:        e.Damage,
+		moat:          e.Moat,
+		builds:        e.Builds.toBox(),
+		moving:        e.Moving,
+		x_ship:        e.XShip.toBox(),
+		near_cities:   append(ints_l(nil), e.NearCities...),
+		safe:          e.Safe,
+		major:         e.Major,
+		prominence:    e.Prominence,
+		link_to:       append(ints_l(nil), e.LinkTo...),
+		link_from:     append(ints_l(nil), e.LinkFrom...),
+		bound_storms:  append(ints_l(nil), e.BoundStorms...),
+		guild:         e.Guild,
+		tax_market:    e.TaxMarket,
+		control:       e.Control.toBox(),
+		entrance_size: e.EntranceSize,
 	}
 }
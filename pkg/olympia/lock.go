@@ -0,0 +1,269 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LOCK_FILE is the name of the advisory lock file in libdir.
+const LOCK_FILE = "lock.json"
+
+// ErrLocked is returned when another process holds the library lock.
+var ErrLocked = errors.New("library is locked")
+
+var (
+	lock_held = false           // true if this process owns the lock file
+	lock_info *LockInfo         // what we wrote to the lock file
+	lock_wait time.Duration     // how long to wait for another process to release the lock
+	lock_poll = 2 * time.Second // how often to check the lock while waiting
+)
+
+// LockInfo is the json version of the lock file.
+type LockInfo struct {
+	PID       int       `json:"pid"`
+	Host      string    `json:"host"`
+	StartedAt time.Time `json:"started-at"`
+	Command   string    `json:"command,omitempty"`
+	Stale     bool      `json:"-"` // set when the owner is known to be dead
+}
+
+func (li *LockInfo) String() string {
+	s := fmt.Sprintf("pid %d on %s since %s", li.PID, li.Host, li.StartedAt.Format(time.RFC3339))
+	if li.Command != "" {
+		s += fmt.Sprintf(" (%s)", li.Command)
+	}
+	if li.Stale {
+		s += " [stale]"
+	}
+	return s
+}
+
+func WithLockWait(d time.Duration) func() error {
+	return func() error {
+		if d < 0 {
+			return fmt.Errorf("lock wait: %w", fmt.Errorf("negative duration"))
+		}
+		lock_wait = d
+		return nil
+	}
+}
+
+// LockStatus returns the current owner of the lock, or nil if the library is not locked.
+func LockStatus(options ...Option) (*LockInfo, error) {
+	for _, option := range options {
+		if err := option(); err != nil {
+			return nil, fmt.Errorf("LockStatus: %w", err)
+		}
+	}
+	li, err := read_lock()
+	if err != nil {
+		return nil, fmt.Errorf("LockStatus: %w", err)
+	}
+	return li, nil
+}
+
+// BreakLock removes the lock file. Unless force is set, it refuses to
+// remove a lock unless the owner is known to be dead, since the owner may
+// still be using the library. We can only tell that for an owner on this host.
+// It returns the information from the lock that was removed, or nil if there was no lock.
+func BreakLock(force bool, options ...Option) (*LockInfo, error) {
+	for _, option := range options {
+		if err := option(); err != nil {
+			return nil, fmt.Errorf("BreakLock: %w", err)
+		}
+	}
+	li, err := read_lock()
+	if err != nil {
+		// a corrupt lock file is still a lock file; let the operator remove it.
+		log.Printf("BreakLock: %v\n", err)
+	} else if li != nil && !li.Stale && !force {
+		if host, _ := os.Hostname(); host == li.Host {
+			return li, fmt.Errorf("BreakLock: %w: %s is still running", ErrLocked, li)
+		}
+		return li, fmt.Errorf("BreakLock: %w: %s may still be running", ErrLocked, li)
+	}
+	if err := os.Remove(filepath.Join(libdir, LOCK_FILE)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("BreakLock: %w", err)
+	}
+	return li, nil
+}
+
+// lock_tag prevents multiple TAGs running against the same library.
+// If another process holds the lock, we wait up to lock_wait for it to be released.
+// A lock left behind by a dead process on this host is removed, as is a
+// lock file that can't be read and is older than the wait.
+func lock_tag() error {
+	if lock_held {
+		return nil
+	}
+
+	deadline := time.Now().Add(lock_wait)
+	for {
+		mine, err := create_lock()
+		if err == nil {
+			lock_held, lock_info = true, mine
+			return nil
+		} else if !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("lock_tag: %w", err)
+		}
+
+		li, err := read_lock()
+		if err != nil {
+			// the owner may still be writing the file; give it a chance to finish.
+			if fi, serr := os.Stat(filepath.Join(libdir, LOCK_FILE)); serr == nil && time.Since(fi.ModTime()) > max_duration(lock_wait, lock_poll) {
+				log.Printf("lock_tag: removing unreadable lock: %v\n", err)
+				if err := break_lock(nil); err != nil {
+					return fmt.Errorf("lock_tag: %w", err)
+				}
+				continue
+			} else if !time.Now().Before(deadline) {
+				return fmt.Errorf("lock_tag: %w", err)
+			}
+			time.Sleep(lock_poll)
+			continue
+		} else if li == nil {
+			// the owner released the lock while we were looking at it
+			continue
+		} else if li.Stale {
+			log.Printf("lock_tag: removing stale lock: %s\n", li)
+			if err := break_lock(li); err != nil {
+				return fmt.Errorf("lock_tag: %w", err)
+			}
+			continue
+		}
+
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("lock_tag: %w: %s", ErrLocked, li)
+		}
+		log.Printf("lock_tag: waiting for lock: %s\n", li)
+		time.Sleep(lock_poll)
+	}
+}
+
+// unlock_tag removes the lock file if we own it.
+// If the lock was broken and taken by another process, that lock is left alone.
+func unlock_tag() {
+	if !lock_held {
+		return
+	}
+	if err := break_lock(lock_info); err != nil {
+		log.Printf("unlock_tag: %v\n", err)
+	}
+	lock_held, lock_info = false, nil
+}
+
+// break_lock removes the lock file if it still holds the given lock,
+// or if it is still unreadable when want is nil. The file is renamed
+// out of the way before it is checked, so that we never remove a lock
+// that another process took after we decided to break the old one.
+// If the lock changed hands, it is put back.
+func break_lock(want *LockInfo) error {
+	name := filepath.Join(libdir, LOCK_FILE)
+	aside := fmt.Sprintf("%s.%d.%d", name, os.Getpid(), time.Now().UnixNano())
+	if err := os.Rename(name, aside); errors.Is(err, os.ErrNotExist) {
+		// someone else broke or released it
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	li := &LockInfo{}
+	data, err := os.ReadFile(aside)
+	if err != nil {
+		return err
+	}
+	var same bool
+	if err := json.Unmarshal(data, li); want == nil {
+		same = err != nil
+	} else {
+		same = err == nil && li.PID == want.PID && li.Host == want.Host && li.StartedAt.Equal(want.StartedAt)
+	}
+	if !same {
+		// link fails rather than replace a lock taken since the rename
+		if err := os.Link(aside, name); err != nil {
+			log.Printf("break_lock: restoring %s: %v\n", li, err)
+		}
+	}
+	return os.Remove(aside)
+}
+
+func max_duration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// create_lock creates the lock file and returns what it wrote to it.
+// It returns an error wrapping os.ErrExist if the file already exists.
+func create_lock() (*LockInfo, error) {
+	host, _ := os.Hostname()
+	li := &LockInfo{
+		PID:       os.Getpid(),
+		Host:      host,
+		StartedAt: time.Now().UTC(),
+	}
+	if len(os.Args) > 1 {
+		li.Command = fmt.Sprintf("%v", os.Args[1:])
+	}
+	data, err := json.MarshalIndent(li, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	fp, err := os.OpenFile(filepath.Join(libdir, LOCK_FILE), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0666)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = fp.Write(data); err != nil {
+		_ = fp.Close()
+		_ = os.Remove(filepath.Join(libdir, LOCK_FILE))
+		return nil, err
+	}
+	return li, fp.Close()
+}
+
+// read_lock returns the contents of the lock file, or nil if there is no lock.
+func read_lock() (*LockInfo, error) {
+	data, err := os.ReadFile(filepath.Join(libdir, LOCK_FILE))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	li := &LockInfo{}
+	if err := json.Unmarshal(data, li); err != nil {
+		return nil, fmt.Errorf("%s: %w", LOCK_FILE, err)
+	}
+
+	// we can only tell if the owner is dead when it ran on this host
+	if host, _ := os.Hostname(); host == li.Host && li.PID != os.Getpid() {
+		li.Stale = !process_alive(li.PID)
+	}
+
+	return li, nil
+}
@@ -0,0 +1,39 @@
+//go:build !unix
+
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import "os"
+
+// process_alive can't check other processes on this platform,
+// so it assumes the owner is alive and leaves the lock alone.
+func process_alive(pid int) bool {
+	return pid > 0
+}
+
+// flock_file does nothing on this platform; the dot lock is all we have.
+func flock_file(fp *os.File) error {
+	return nil
+}
+
+func funlock_file(fp *os.File) error {
+	return nil
+}
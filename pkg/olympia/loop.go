@@ -497,8 +497,8 @@ func loop_loc_teach(where int) []int {
 
 func loop_units(pl int) []int {
 	var ll_l []int
-	if rp_player(hades_player) != nil {
-		ll_l = append(ll_l, rp_player(hades_player).Units...)
+	if rp_player(pl) != nil {
+		ll_l = append(ll_l, rp_player(pl).Units...)
 	}
 	return ll_l
 }
@@ -0,0 +1,156 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/mailer"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SMTP_PASSWORD_FILE holds the password for the mail server, relative to
+// libdir. It is kept out of sysdata.json so that the password isn't copied
+// into every turn snapshot. The GOLY_SMTP_PASSWORD environment variable,
+// if set, is used instead.
+const SMTP_PASSWORD_FILE = "smtp-password"
+
+// mail_transport_override lets callers replace the transport configured in
+// the system data, e.g. for a dry run or for testing against a local server.
+var mail_transport_override mailer.Transport
+
+// WithMailDir sends all mail to .eml files in the named directory instead of the mail server.
+func WithMailDir(name string) func() error {
+	return func() error {
+		if name == "" {
+			return fmt.Errorf("mail dir: missing path name")
+		}
+		mail_transport_override = &mailer.Dir{Path: name}
+		return nil
+	}
+}
+
+// WithMailTransport sends all mail through the given transport.
+func WithMailTransport(t mailer.Transport) func() error {
+	return func() error {
+		if t == nil {
+			return fmt.Errorf("mail transport: missing transport")
+		}
+		mail_transport_override = t
+		return nil
+	}
+}
+
+// mail_transport returns the transport for outgoing mail.
+func mail_transport() (mailer.Transport, error) {
+	if mail_transport_override != nil {
+		return mail_transport_override, nil
+	} else if options.mail_dir != "" {
+		mail_transport_override = &mailer.Dir{Path: options.mail_dir}
+		return mail_transport_override, nil
+	} else if options.smtp_host == "" {
+		return nil, fmt.Errorf("mail_transport: smtp-host and mail-dir are not set")
+	}
+	password, err := smtp_password()
+	if err != nil {
+		return nil, fmt.Errorf("mail_transport: %w", err)
+	}
+	mail_transport_override = &mailer.SMTP{
+		Host:     options.smtp_host,
+		Port:     options.smtp_port,
+		Username: options.smtp_user,
+		Password: password,
+		StartTLS: options.smtp_starttls,
+	}
+	return mail_transport_override, nil
+}
+
+// smtp_password returns the password for the mail server from the
+// environment or SMTP_PASSWORD_FILE. A password read from an old
+// sysdata.json is used if there is neither.
+func smtp_password() (string, error) {
+	if password, ok := os.LookupEnv("GOLY_SMTP_PASSWORD"); ok {
+		return password, nil
+	}
+	data, err := os.ReadFile(filepath.Join(libdir, SMTP_PASSWORD_FILE))
+	if errors.Is(err, os.ErrNotExist) {
+		return options.smtp_password, nil
+	} else if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// save_smtp_password writes the password to the named file, readable only
+// by its owner. A file that already exists is left alone.
+func save_smtp_password(name, password string) error {
+	fp, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(fp, password)
+	if cerr := fp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(name)
+	}
+	return err
+}
+
+// mail_addresses splits a player's email field into separate addresses.
+// Players may list several, separated by commas or spaces.
+func mail_addresses(email string) []string {
+	return strings.FieldsFunc(email, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+}
+
+// read_report_file returns the contents of a raw report file.
+// If the file doesn't exist, we look for a gzip'd copy.
+func read_report_file(fnam string) ([]byte, error) {
+	data, err := os.ReadFile(fnam)
+	if err == nil {
+		return data, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	zdata, err := os.ReadFile(fnam + ".gz")
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zdata))
+	if err != nil {
+		return nil, fmt.Errorf("%s.gz: %w", fnam, err)
+	}
+	defer zr.Close()
+	if data, err = io.ReadAll(zr); err != nil {
+		return nil, fmt.Errorf("%s.gz: %w", fnam, err)
+	}
+	return data, nil
+}
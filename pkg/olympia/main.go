@@ -20,8 +20,13 @@
 package olympia
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/mdhender/golympia/pkg/battle"
+	"github.com/mdhender/golympia/pkg/mailer"
+	"github.com/mdhender/golympia/pkg/report"
 	"log"
+	"net/mail"
 	"os"
 	"path/filepath"
 	"strings"
@@ -42,414 +47,6 @@ var (
 	time_self         = false /* print timing info */
 )
 
-func getopt(argc int, argv []string, flags string) int {
-	panic("!implemented")
-}
-
-func RunOly(args ...string) error {
-	add_flag := false
-	art_flag := false
-	combat_test_flag := false
-	eat_flag := false
-	errflag := false
-	inhibit_add_flag := false
-	lore_flag := false
-	mail_now := false
-	map_flag := false
-	//map_test_flag := false
-	run_flag := false
-	unspool_first_flag := false
-	var c int
-
-	if err := call_init_routines(); err != nil {
-		return fmt.Errorf("RunOly: %w", err)
-	}
-
-	argc := len(args)
-	argv := args
-	EOF := -1
-	for {
-		if c = getopt(argc, argv, "axefirmLl:pR?StMTAqXE"); c == EOF {
-			break
-		}
-		switch byte(c) {
-		case 'm':
-			map_flag = true
-
-		case 'a':
-			add_flag = true
-			immediate = FALSE
-
-		case 'x':
-			inhibit_add_flag = true
-
-		case 'X':
-			combat_test_flag = true
-
-		case 'A':
-			acct_flag = TRUE
-
-		case 'f':
-			flush_always = true
-
-		case 'e':
-			eat_flag = true
-			immediate = FALSE
-
-		case 'E':
-			unspool_first_flag = true
-
-		case 'i':
-			immed_after = TRUE
-
-		case 'l': /* set libdir */
-			//libdir = string(optarg)
-			return fmt.Errorf("RunOly: --l not implemented")
-
-		case 'L':
-			lore_flag = true
-
-		case 'p':
-			if pretty_data_files == FALSE {
-				pretty_data_files = TRUE
-			} else {
-				pretty_data_files = FALSE
-			}
-
-		case 'q': /* test artifacts */
-			art_flag = true
-
-		case 'r': /* run a turn */
-			immediate = FALSE
-			run_flag = true
-
-		case 'R': /* test random number generator */
-			return fmt.Errorf("error: '%s -R' has been replaced with 'random/random_test'\n", argv[0])
-
-		case 'S': /* save database when done */
-			save_flag = true
-
-		case 't':
-			return fmt.Errorf("error: '%s -t' has been replaced with 'vectors/ilist_test'\n", argv[0])
-
-		case 'T':
-			time_self = true
-
-		case 'M':
-			mail_now = true
-
-		default:
-			errflag = true
-		}
-	}
-
-	if errflag {
-		log.Printf("usage: oly [options]\n")
-		log.Printf("  -a        Add new players mode\n")
-		log.Printf("  -x        Inhibit adding players during turn.\n")
-		log.Printf("  -e        Eat orders from libdir/spool\n")
-		log.Printf("  -f        Don't buffer files for debugging\n")
-		log.Printf("  -i        Immediate mode\n")
-		log.Printf("  -l dir    Specify libdir, default ./lib\n")
-		log.Printf("  -L        Generate lore dictionary.\n")
-		log.Printf("  -p        Don't make data files pretty\n")
-		log.Printf("  -r        Run a turn\n")
-		log.Printf("  -R        Test the random number generator\n")
-		log.Printf("  -S        Save the database at completion\n")
-		log.Printf("  -t        Test ilist code\n")
-		log.Printf("  -T        Print timing info\n")
-		log.Printf("  -M        Mail reports\n")
-		log.Printf("  -A        Charge player accounts\n")
-		log.Printf("  -X        Combat test\n")
-		os.Exit(1)
-	}
-
-	/*
-	 *  Sat Apr 15 11:55:36 2000 -- Scott Turner
-	 *
-	 *  Lock up; prevents multiple TAGs running simultaneously.
-	 *
-	 */
-	lock_tag()
-
-	if err := load_db(); err != nil {
-		return fmt.Errorf("RunOly: %w", err)
-	}
-
-	/*
-	 *  Create a couple of stacks and have them battle
-	 *  it out a few hundred times and report the results.
-	 *
-	 */
-	if combat_test_flag {
-		var result int
-		open_logfile()
-		sum_a := 0
-		for i := 0; i < 100; i++ {
-			a := new_ent(T_char, 0)
-			b := new_ent(T_char, 0)
-			c := new_ent(T_char, 0)
-			d := new_ent(T_char, 0)
-			p := p_char(a)
-			p.behind = 9
-			p.health = 100
-			p.attack = 80
-			p.defense = 80
-			p.break_point = 0
-			s := p_skill_ent(a, sk_control_battle)
-			s.know = SKILL_know
-			s.experience = 500
-			s = p_skill_ent(a, sk_use_beasts)
-			s.know = SKILL_know
-			s.experience = 500
-			set_where(a, 10201)
-			set_lord(a, gm_player, LOY_oath, 1)
-			set_name(a, "A")
-			p = p_char(b)
-			p.behind = 0
-			p.health = 100
-			p.attack = 80
-			p.defense = 80
-			p.break_point = 0
-			s = p_skill_ent(b, sk_control_battle)
-			s.know = SKILL_know
-			s.experience = 500
-			s = p_skill_ent(b, sk_use_beasts)
-			s.know = SKILL_know
-			s.experience = 500
-			set_where(b, 10201)
-			set_lord(b, gm_player, LOY_oath, 1)
-			set_name(b, "B")
-			p = p_char(c)
-			p.behind = 0
-			p.health = 100
-			p.attack = 360
-			p.defense = 360
-			p.break_point = 0
-			s = p_skill_ent(c, sk_control_battle)
-			s.know = SKILL_know
-			s.experience = 500
-			s = p_skill_ent(c, sk_use_beasts)
-			s.know = SKILL_know
-			s.experience = 500
-			set_where(c, 10201)
-			set_lord(c, gm_player, LOY_oath, 1)
-			set_name(c, "C")
-			p = p_char(d)
-			p.behind = 9
-			p.health = 100
-			p.attack = 80
-			p.defense = 80
-			p.break_point = 0
-			s = p_skill_ent(d, sk_control_battle)
-			s.know = SKILL_know
-			s.experience = 500
-			s = p_skill_ent(d, sk_use_beasts)
-			s.know = SKILL_know
-			s.experience = 500
-			set_where(d, 10201)
-			set_lord(d, gm_player, LOY_oath, 1)
-			set_name(d, "D")
-			/*
-			 *  A & B stacked together and C & D stacked
-			 *  together.  A & D are behind.
-			 *
-			 */
-			gen_item(a, item_soldier, 10)
-			gen_item(a, item_angel, 15)
-			gen_item(c, item_nazgul, 3)
-			if result = regular_combat(c, a, 0, 0); result != 0 {
-				sum_a++
-			}
-		}
-		close_logfile()
-		log.Printf("Sum = %d.\n", sum_a)
-		os.Exit(1)
-	}
-
-	if map_flag {
-		if asciiMap := load_cmap(); asciiMap != nil {
-			for _, row := range asciiMap {
-				fmt.Printf("%s\n", row)
-			}
-		}
-		log.Println("")
-		if asciiMap := load_cmap_players(); asciiMap != nil {
-			for _, row := range asciiMap {
-				fmt.Printf("%s\n", row)
-			}
-		}
-		os.Exit(1)
-	}
-
-	if art_flag {
-		var i int
-		var c command
-		c.who = gm_player
-		for i = 0; i < 10; i++ {
-			c.a = create_random_artifact(gm_player)
-			v_identify(&c)
-			save_box(os.Stderr, c.a)
-		}
-		/*
-		 *  Look to see if anyone owns a crown, palantir, etc.
-		 *
-		 */
-		for _, i = range loop_subkind(sub_npc_token) {
-			if item_unique(i) != FALSE && !is_real_npc(player(item_unique(i))) {
-				log.Printf("Crown %s held by %s.\n", box_name(i), box_name(player(item_unique(i))))
-			}
-		}
-		for _, i = range loop_subkind(sub_palantir) {
-			if item_unique(i) != FALSE && !is_real_npc(player(item_unique(i))) {
-				log.Printf("Palantir %s held by %s.\n", box_name(i), box_name(player(item_unique(i))))
-			}
-		}
-		for _, i = range loop_subkind(sub_scroll) {
-			if item_unique(i) != FALSE && !is_real_npc(player(item_unique(i))) {
-				log.Printf("Scroll %s held by %s.\n", box_name(i), box_name(player(item_unique(i))))
-			}
-		}
-		for _, i = range loop_subkind(sub_suffuse_ring) {
-			if item_unique(i) != FALSE && !is_real_npc(player(item_unique(i))) {
-				log.Printf("Suffuse ring %s held by %s.\n", box_name(i), box_name(player(item_unique(i))))
-			}
-		}
-		for _, i = range loop_subkind(sub_artifact) {
-			if item_unique(i) != FALSE && !is_real_npc(player(item_unique(i))) {
-				log.Printf("Artifact %s held by %s.\n", box_name(i), box_name(player(item_unique(i))))
-			}
-		}
-		os.Exit(0)
-	}
-
-	if lore_flag {
-		open_logfile()
-		gm_show_all_skills(skill_player, true)
-		os.Exit(0)
-	}
-
-	if eat_flag {
-		if mail_now {
-			eat_loop(true)
-		} else {
-			eat_loop(false)
-		}
-		os.Exit(0)
-	}
-
-	if run_flag {
-		// if unspool_first_flag is on, then before running the turn, eat up any waiting mail.
-		if unspool_first_flag {
-			if err := mkdir(filepath.Join(libdir, "orders")); err != nil {
-				panic(err)
-			}
-			if err := mkdir(filepath.Join(libdir, "spool")); err != nil {
-				panic(err)
-			}
-			//chmod(filepath.Join(libdir, "spool"), 0777)
-			if mail_now {
-				read_spool(true)
-			} else {
-				read_spool(false)
-			}
-		}
-
-		open_logfile()
-		open_times()
-
-		show_day = true
-		pre_month()
-		process_orders()
-		post_month()
-		//#if 0
-		//        /* This was only to translate to the new system. */
-		//        artifact_fixer();
-		//#endif
-		show_day = false
-
-		determine_output_order()
-		turn_end_loc_reports()
-		list_order_templates()
-		player_ent_info()
-		character_report()
-
-		player_banner()
-		// if (acct_flag && !options.free)
-		//    charge_account();
-		// report_account();
-		summary_report()
-		player_report()
-
-		scan_char_skill_lore()
-		show_lore_sheets()
-		//#if 0
-		//        list_new_players();
-		//#endif
-		if !options.open_ended {
-			check_win_conditions()
-		}
-		gm_report(gm_player)
-		gm_show_all_skills(skill_player, true)
-		if !inhibit_add_flag {
-			add_new_players()
-		}
-		gen_include_section() /* must be last */
-		close_logfile()
-
-		write_player_list()
-		write_nations_lists()
-		write_email()
-		write_totimes()
-		write_forwards()
-		write_factions()
-	}
-
-	if add_flag {
-		if mail_now {
-			new_player_top(TRUE)
-		} else {
-			new_player_top(FALSE)
-		}
-		mail_now = false
-	}
-
-	if immediate != FALSE || immed_after != FALSE {
-		immediate = TRUE
-
-		open_logfile()
-		immediate_commands()
-		close_logfile()
-	}
-
-	/* check database integrity */
-	if err := check_db(); err != nil {
-		return fmt.Errorf("RunOly: %w", err)
-	}
-
-	if save_flag {
-		if err := save_db(); err != nil {
-			return fmt.Errorf("RunOly: %w", err)
-		}
-	}
-
-	if save_flag && run_flag {
-		if err := save_logdir(); err != nil {
-			return fmt.Errorf("RunOly: %w", err)
-		}
-	}
-
-	do_times()
-
-	if mail_now {
-		mail_reports()
-	}
-
-	stage("")
-
-	return nil
-}
-
 func call_init_routines() error {
 	init_lower()
 	dir_assert()
@@ -735,29 +332,41 @@ func write_factions() {
 }
 
 /*
- *  Make a report from a raw file into the specified file.
+ *  Make a report from a raw file.
+ *
+ *  The report is rendered in-process by the report package rather
+ *  than by piping the raw file through the external "rep" program.
  *
  */
-func make_report(format int, fnam string, report string, pl int) int {
-	var form, entab, tags string
-	switch format {
-	case HTML:
-		form = "-html"
-	case RAW:
-		form = "-raw"
-	case TAGS:
-		form = "-text"
-		tags = "-tags"
-	default:
-		form = "-text"
+func make_report(format int, fnam string, pl int) ([]byte, error) {
+	data, err := read_report_file(fnam)
+	if err != nil {
+		return nil, fmt.Errorf("make_report: %w", err)
+	}
+	rl, err := report.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("make_report: %s: %w", fnam, err)
 	}
 
-	if player_notab(pl) {
-		entab = "-notab"
+	opts := report.Options{
+		Format: report.Format(format),
+		NoTab:  player_notab(pl),
+		Title:  fmt.Sprintf("Olympia:TAG game %d turn %d report for %s", game_number, sysclock.turn, box_code_less(pl)),
+	}
+	if format != HTML && format != RAW && format != TAGS {
+		opts.Format = report.TEXT
+	}
+	if opts.Format == report.HTML {
+		if opts.Battles, err = battle.Read(fnam + ".battles.json"); err != nil {
+			return nil, fmt.Errorf("make_report: %w", err)
+		}
 	}
 
-	tmp := fmt.Sprintf("rep %s %s %s %s >> %s", form, tags, entab, fnam, report)
-	return system(tmp)
+	var b bytes.Buffer
+	if err := report.Render(&b, rl, opts); err != nil {
+		return nil, fmt.Errorf("make_report: %w", err)
+	}
+	return b.Bytes(), nil
 }
 
 /*
@@ -785,133 +394,91 @@ func format_string(i int) string {
  *
  *  Added a loop over all the formats.
  *
+ *  The reports are built in memory and split into numbered parts
+ *  here rather than by mailsplit.  The Times rides along as an
+ *  attachment on the last part of the first report.
+ *
  */
 func send_rep(pl, turn int) int {
-	var p *EntityPlayer
-	var ret int
-	var zfnam string
-	var fnam string
-	split_lines := player_split_lines(pl)
-	split_bytes := player_split_bytes(pl)
-	formats := p_player(pl).Format
-	var i int
-	var email string
-
-	p = rp_player(pl)
-
+	p := rp_player(pl)
 	if p == nil || p.EMail == "" {
 		return FALSE
 	}
 
+	split_lines := player_split_lines(pl)
+	split_bytes := player_split_bytes(pl)
+
 	/*
 	 *  Default for text format if nothing is set.
 	 *
 	 */
+	formats := p.Format
 	if formats == 0 {
 		formats = TEXT
 	}
 
-	/*
-	 *  Prepare the report file and the input file before the
-	 *  format loop.
-	 *
-	 */
-	report := fmt.Sprintf("/tmp/sendrep%d.%s", get_process_id(), box_code_less(pl))
-
-	fnam = filepath.Join(libdir, "save", fmt.Sprintf("%d", turn), fmt.Sprintf("%d", pl))
-
-	if access(fnam, R_OK) < 0 {
-		zfnam = filepath.Join(libdir, "save", fmt.Sprintf("%d", turn), fmt.Sprintf("%d.gz", pl))
+	transport, err := mail_transport()
+	if err != nil {
+		log.Printf("send_rep: %v\n", err)
+		return FALSE
+	}
 
-		if access(zfnam, R_OK) < 0 {
-			unlink(report)
-			return FALSE
-		}
+	fnam := filepath.Join(libdir, "save", fmt.Sprintf("%d", turn), fmt.Sprintf("%d", pl))
 
-		fnam = sout("/tmp/zrep.%d", pl)
+	var times []byte
+	if data, err := os.ReadFile(filepath.Join(libdir, "Times")); err == nil && len(data) != 0 {
+		times = data
+	}
 
-		ret = system(sout("gzcat %s > %s", zfnam, fnam))
-		if ret == 0 {
-			log.Printf("couldn't unpack %s\n", zfnam)
-			unlink(fnam)
-			unlink(report)
-			return FALSE
-		}
+	var to []string
+	for _, email := range mail_addresses(p.EMail) {
+		to = append(to, (&mail.Address{Name: p.FullName, Address: email}).String())
 	}
 
 	/*
 	 *  Here's the format loop.
 	 *
 	 */
-	for i = 1; i < ALT; i = i << 1 {
-		if (formats & i) != 0 {
-
-			fp, err := fopen(report, "w")
-			if err != nil {
-				log.Printf("send_rep: can't write %s: %v", report, err)
-				return FALSE
-			}
+	ret := TRUE
+	for i := 1; i < ALT; i = i << 1 {
+		if (formats & i) == 0 {
+			continue
+		}
 
-			fprintf(fp, "From: %s\n", from_host)
-			if reply_host != "" {
-				fprintf(fp, "Reply-To: %s\n", reply_host)
-			}
-			fprintf(fp, "To: %s (%s)\n", p.EMail, or_string(p.FullName != "", p.FullName, "???"))
-			fprintf(fp, "Subject: Olympia:TAG game %d turn %d report [%s]\n", game_number, turn, format_string(i))
-			fprintf(fp, "\n")
-			_ = fp.Close()
-
-			ret = make_report(i, fnam, report, pl)
-			if ret != 0 {
-				log.Printf("send_rep: failed: make_report(%d, %q, %q, %d)\n", i, fnam, report, pl)
-				unlink(report)
-				if zfnam != "" {
-					unlink(fnam)
-				}
-				return FALSE
-			}
+		body, err := make_report(i, fnam, pl)
+		if err != nil {
+			log.Printf("send_rep: %s: %v\n", box_code_less(pl), err)
+			return FALSE
+		}
 
-			var cmd string
-			if split_lines == 0 && split_bytes == 0 {
-				/* VLN cmd = sout("sendmail -t -odq < %s", report); */
-				cmd = sout("msmtp -t < %s", report)
-			} else {
-				/* VLN cmd = sout("mailsplit -s %d -l %d -c 'sendmail -t -odq' < %s", split_bytes, split_lines, report); */
-				cmd = sout("mailsplit -s %d -l %d -c 'msmtp -t' < %s", split_bytes, split_lines, report)
-			}
+		msg := &mailer.Message{
+			From:    from_host,
+			ReplyTo: reply_host,
+			To:      to,
+			Subject: fmt.Sprintf("Olympia:TAG game %d turn %d report [%s]", game_number, turn, format_string(i)),
+			Body:    body,
+		}
+		if i == HTML {
+			msg.ContentType = "text/html; charset=utf-8"
+		}
+		if times != nil {
+			msg.Attachments = append(msg.Attachments, mailer.Attachment{
+				Name: fmt.Sprintf("times-%d-%d.txt", game_number, turn),
+				Data: times,
+			})
+			times = nil
+		}
 
-			log.Printf("   %s\n", cmd)
-			ret = system(cmd)
-			if ret != 0 {
-				log.Printf("send_rep: mail to %s failed: %s\n", p.EMail, cmd)
+		for _, part := range mailer.Split(msg, split_lines, split_bytes) {
+			log.Printf("   mail %s to %s\n", part.Subject, p.EMail)
+			if err := transport.Send(part); err != nil {
+				log.Printf("send_rep: mail to %s failed: %v\n", p.EMail, err)
+				ret = FALSE
 			}
-			unlink(report)
 		}
 	}
 
-	if zfnam != "" {
-		unlink(fnam)
-	}
-
-	/*
-	 *  Sun Dec 31 17:45:28 2000 -- Scott Turner
-	 *
-	 *  Send the Times also.
-	 *
-	 */
-	/* VLN cmd = sout("sendmail -odq %s < %s/Times", p.email, libdir); */
-
-	/* VLN remove "," from email adddress string */
-	email = strings.ReplaceAll(p.EMail, ",", " ")
-
-	/*VLN cmd = sout("msmtp %s < %s/Times", p.email, libdir); */
-	cmd := sout("msmtp %s < %s/Times", email, libdir)
-	ret = system(cmd)
-	if ret != 0 {
-		log.Printf("send_rep: mail to %s failed: %q\n", p.EMail, cmd)
-	}
-
-	return TRUE
+	return ret
 }
 
 func mail_reports() {
@@ -926,258 +493,6 @@ func mail_reports() {
 	for _, pl = range loop_player() {
 		send_rep(pl, sysclock.turn)
 	}
-
-	setup_html_all()
-}
-
-func preprocess(in string, out string, args string) int {
-	var buf string
-	err := 0
-
-	buf = fmt.Sprintf("%s %s < %s > %s", options.cpp, args, in, out)
-	err = system(buf)
-	if err != 0 {
-		log.Printf("Error preprocessing %s.\n", in)
-	}
-	return err
-}
-
-/*
- *  Rich's on-line web stuff
- *
- *  Fri Oct 15 10:22:40 1999 -- Scott Turner
- *
- *  Modify our umask and permissions so that any file created is
- *  readable by all.  This is necessary for the reports-on-line stuff.
- *
- *  Thu Nov 11 06:56:58 1999 -- Scott Turner
- *
- *  Add stuff to write out the %d.pre file.
- *
- */
-func setup_html_all() {
-	var pl int
-	var fnam, fnam2 string
-
-	stage("setup_html()")
-
-	fnam = filepath.Join(options.html_path, fmt.Sprintf("%d", game_number), fmt.Sprintf("%d.pre", game_number))
-	fp, err := fopen(fnam, "w")
-	if err != nil {
-		log.Printf("Can't open %s for writing?", fnam)
-		fp = os.Stderr
-	}
-	fprintf(fp, "<TABLE CELLSPACING=0 CELLPADDING=5 WIDTH=\"100%%\" BGCOLOR=\"#48D1CC\" >\n")
-
-	/* write and execute only for self. */
-	umask(S_IWGRP | S_IXGRP | S_IWOTH | S_IXOTH)
-
-	for _, pl = range loop_player() {
-		if !is_real_npc(pl) {
-			setup_html_dir(pl)
-			set_html_pass(pl)
-			/* VLN output_html_rep(pl);  because these are broken */
-			/*VLN output_html_map(pl); */
-			fprintf(fp, "<TR><TD><A HREF=\"http://UPDATE.com/oly/tag/reports/%d/%s/tag%d-%d.html.gz\">[%s] %s</A></TD></TR>\n",
-				game_number,
-				box_code_less(pl),
-				game_number,
-				sysclock.turn,
-				box_code_less(pl),
-				just_name(pl))
-		}
-	}
-
-	/* Now switch back. */
-	umask(S_IRWXO)
-
-	fprintf(fp, "</TABLE>")
-
-	if fp != os.Stderr {
-		_ = fp.Close()
-	}
-
-	// now we need to call the C pre-processor on the reports.pre file to incorporate this latest set of changes.
-	fnam = filepath.Join(options.html_path, fmt.Sprintf("%d", game_number), "reports.pre")
-	fnam2 = filepath.Join(options.html_path, fmt.Sprintf("%d", game_number), "reports.html")
-	preprocess(fnam, fnam2, "-P")
-
-	/* copy_public_turns(); */
-}
-
-func setup_html_dir(pl int) {
-	var fnam string
-	var fnam2 string
-	var fp *os.File
-
-	/* read and execute for all. */
-	umask(S_IWGRP | S_IWOTH)
-	fnam = filepath.Join(options.html_path, fmt.Sprintf("%d", game_number), box_code_less(pl))
-	if err := mkdir(fnam); err != nil {
-		panic(err)
-	}
-	umask(S_IWGRP | S_IXGRP | S_IWOTH | S_IXOTH)
-
-	fnam2 = filepath.Join(fnam, ".htaccess")
-	fp, err := fopen(fnam2, "w")
-	if err != nil {
-		log.Printf("can't write %s: %v", fnam2, err)
-		return
-	}
-
-	fprintf(fp, "AuthUserFile %s.%d\n", options.html_passwords, game_number)
-	fprintf(fp, "AuthGroupFile /dev/null\n")
-	fprintf(fp, "AuthName ByPassword\n")
-	fprintf(fp, "AuthType Basic\n")
-	fprintf(fp, "\n")
-	fprintf(fp, "<Limit GET>\n")
-	fprintf(fp, "require user %s admin\n", box_code_less(pl))
-	fprintf(fp, "</Limit>\n")
-
-	_ = fp.Close()
-}
-
-func set_html_pass(pl int) {
-	var buf string
-	var p *EntityPlayer
-
-	p = rp_player(pl)
-	if p == nil {
-		return
-	}
-
-	pw := p.Password
-	if len(pw) == 0 {
-		pw = DEFAULT_PASSWORD
-	}
-	/*
-	   Usage:
-	   	htpasswd [-cmdpsD] passwordfile username
-	   	htpasswd -b[cmdpsD] passwordfile username password
-
-	   	htpasswd -n[mdps] username
-	   	htpasswd -nb[mdps] username password
-	*/
-	buf = fmt.Sprintf("htpasswd -b %s.%d %s \"%s\"", options.html_passwords, game_number, box_code_less(pl), pw)
-
-	system(buf)
-}
-
-func output_html_rep(pl int) {
-	var fnam, report string
-	var ret int
-
-	fnam = filepath.Join(libdir, "save", fmt.Sprintf("%d", sysclock.turn), fmt.Sprintf("%d", pl))
-	report = filepath.Join(options.html_path, fmt.Sprintf("%d", game_number), fmt.Sprintf("tag%d-%d.html", box_code_less(pl), game_number, sysclock.turn))
-	ret = make_report(HTML, fnam, report, pl)
-	if ret != 0 {
-		log.Printf("Cannot make HTML report for %s?", box_code_less(pl))
-		return
-	}
-
-	/*
-	 *  Compress the output.
-	 *
-	 */
-	system(sout("gzip -f %s", report))
-	/* VLN
-
-	     system(sout("rm %s/%d/%s/index.html; ln -s %s.gz %s/%d/%s/index.html",
-	   	      options.html_path, game_number, box_code_less(pl),
-	   	      report, options.html_path, game_number, box_code_less(pl)));
-	*/
-}
-
-/*
- *  Mon May 14 13:53:57 2001 -- Scott Turner
- *
- *  Add in what we need to create the info subdirectory and the
- *  map.
- *
- */
-func output_html_map(pl int) {
-	var info, fnam string
-	minx := 1000
-	miny := 1000
-	maxx := 0
-	maxy := 0
-	var i int
-
-	fnam = filepath.Join(libdir, "save", fmt.Sprintf("%d", sysclock.turn), fmt.Sprintf("%d", pl))
-	info = filepath.Join(options.html_path, fmt.Sprintf("%d", game_number), box_code_less(pl), "info")
-	// create info subdir if necessary.
-	umask(S_IWGRP | S_IWOTH)
-	if err := mkdir(info); err != nil {
-		panic(err)
-	}
-	umask(S_IWOTH | S_IXOTH)
-
-	/*
-	 *  Run the analyze script on the current turn report, outputting
-	 *  to the info directory.
-	 *
-	 */
-	if system(sout("anlz.pl %s %s", fnam, info)) != 0 {
-		log.Printf("Cannot run anlyz for %s?", box_code_less(pl))
-		umask(S_IRWXO)
-		return
-	}
-
-	/*
-	 *  Now run map3.pl.
-	 *
-	 *  Mon May 14 17:47:14 2001 -- Scott Turner
-	 *
-	 *  Have to figure out min/max values for x and y.
-	 */
-	for _, i = range known_sparse_loop(p_player(pl).Known) {
-		if kind(i) == T_loc &&
-			loc_depth(i) == LOC_province &&
-			region(i) != hades_region &&
-			region(i) != faery_region &&
-			region(i) != cloud_region {
-			if region_col(i) < minx {
-				minx = region_col(i)
-			}
-			if region_col(i) > maxx {
-				maxx = region_col(i)
-			}
-			if region_row(i) < miny {
-				miny = region_row(i)
-			}
-			if region_row(i) > maxy {
-				maxy = region_row(i)
-			}
-		}
-	}
-
-	for _, i = range known_sparse_loop(p_player(pl).locs) {
-		if kind(i) == T_loc &&
-			loc_depth(i) == LOC_province &&
-			region(i) != hades_region &&
-			region(i) != faery_region &&
-			region(i) != cloud_region {
-			if region_col(i) < minx {
-				minx = region_col(i)
-			}
-			if region_col(i) > maxx {
-				maxx = region_col(i)
-			}
-			if region_row(i) < miny {
-				miny = region_row(i)
-			}
-			if region_row(i) > maxy {
-				maxy = region_row(i)
-			}
-		}
-	}
-
-	if system(sout("cd %s;map3.pl map.html %d %d %d %d full", info, minx, maxx, miny, maxy)) != 0 {
-		log.Printf("Cannot run map3 for %s?", box_code_less(pl))
-		umask(S_IRWXO)
-		return
-	}
-	umask(S_IRWXO)
 }
 
 //#if 0
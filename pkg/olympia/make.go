@@ -584,3 +584,29 @@ func v_use_train_war(c *command) int {
 
 	return v_make(c)
 }
+
+/*
+ *  use 1801 [wagon] [qty]
+ *
+ *  Build wagons is the make order for the wagons; the default is a
+ *  plain wagon.  Once parsed as a make, the order finishes as one.
+ *
+ */
+func v_build_wagons(c *command) int {
+	item, qty := item_new_wagon, 0
+
+	if numargs(c) >= 1 {
+		if t := find_make(c.a); t == nil || t.req_skill != sk_build_wagons {
+			wout(c.who, "Don't know how to build %s.", box_code(c.a))
+			return FALSE
+		}
+		item, qty = c.a, c.b
+	}
+
+	ret := oly_parse(c, []byte(sout("make %s %d", box_code_less(item), qty)))
+	if !ret {
+		panic("assert(ret)")
+	}
+
+	return v_make(c)
+}
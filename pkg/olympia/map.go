@@ -20,6 +20,8 @@
 package olympia
 
 import (
+	"fmt"
+	"github.com/mdhender/golympia/pkg/worldmap"
 	"io"
 	"log"
 )
@@ -131,3 +133,104 @@ func print_cmap(w io.Writer) {
 		_, _ = w.Write([]byte{'\n'})
 	}
 }
+
+// WorldMap loads the database and returns the surface of the world.
+// If faction is empty, the map shows everything (the GM view).
+// Otherwise it only shows the provinces, cities, roads, and gates
+// that the faction knows about.
+func WorldMap(faction string, options ...Option) (m *worldmap.Map, err error) {
+	defer recover_turn("WorldMap", &err)
+	defer unlock_tag()
+
+	if err := start_turn(options...); err != nil {
+		return nil, fmt.Errorf("WorldMap: %w", err)
+	}
+
+	pl := 0
+	if faction != "" {
+		if pl = code_to_int([]byte(faction)); kind(pl) != T_player {
+			return nil, fmt.Errorf("WorldMap: %q is not a faction", faction)
+		}
+	}
+
+	return world_map(pl), nil
+}
+
+// is_surface_province returns true for provinces on the main map,
+// which excludes Faery, Hades, and the Cloudlands.
+func is_surface_province(n int) bool {
+	if kind(n) != T_loc || loc_depth(n) != LOC_province {
+		return false
+	}
+	r := region(n)
+	return r != faery_region && r != hades_region && r != cloud_region
+}
+
+// world_map builds the map for the GM (pl == 0) or for a faction.
+func world_map(pl int) *worldmap.Map {
+	known := func(n int) bool {
+		return pl == 0 || known_to(pl, n)
+	}
+
+	m := &worldmap.Map{Title: fmt.Sprintf("Olympia game %d, turn %d", game_number, sysclock.turn)}
+	if pl != 0 {
+		m.Title += fmt.Sprintf(", %s [%s]", just_name(pl), box_code_less(pl))
+	}
+
+	for _, i := range loop_province() {
+		if !is_surface_province(i) {
+			continue
+		}
+		row, col := region_row_col(i)
+		if row+1 > m.Rows {
+			m.Rows = row + 1
+		}
+		if col+1 > m.Cols {
+			m.Cols = col + 1
+		}
+		if !known(i) {
+			continue
+		}
+
+		p := &worldmap.Province{
+			Id:      i,
+			Row:     row,
+			Col:     col,
+			Name:    just_name(i),
+			Terrain: subkind_s[subkind(i)],
+			Region:  region(i),
+			SeaLane: loc_sea_lane(i) != FALSE,
+		}
+		for _, j := range loop_all_here(i) {
+			if subkind(j) == sub_city && known(j) {
+				p.Cities = append(p.Cities, fmt.Sprintf("%s [%s]", just_name(j), box_code_less(j)))
+			}
+		}
+		m.Provinces = append(m.Provinces, p)
+	}
+
+	// a link is shown if the faction knows both ends, and, for
+	// gates and hidden roads, the link itself.
+	link := func(n, from, to int, secret bool) (worldmap.Link, bool) {
+		from, to = province(from), province(to)
+		if from == to || !is_surface_province(from) || !is_surface_province(to) {
+			return worldmap.Link{}, false
+		} else if !known(from) || !known(to) || (secret && !known(n)) {
+			return worldmap.Link{}, false
+		}
+		return worldmap.Link{Id: n, From: from, To: to}, true
+	}
+	for _, i := range loop_kind(T_road) {
+		if l, ok := link(i, loc(i), road_dest(i), road_hidden(i) != FALSE); ok {
+			m.Roads = append(m.Roads, l)
+		}
+	}
+	for _, i := range loop_gate() {
+		if l, ok := link(i, loc(i), gate_dest(i), true); ok {
+			m.Gates = append(m.Gates, l)
+		}
+	}
+
+	m.Sort()
+	return m
+}
@@ -0,0 +1,387 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/worldmap"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// mapPreviewFilename is where GenerateMap draws the map before saving it.
+var mapPreviewFilename string
+
+// WithMapPreview draws the generated map to name, as PNG if the name
+// ends in ".png" and as SVG otherwise.
+func WithMapPreview(name string) func() error {
+	return func() error {
+		if name == "" {
+			return fmt.Errorf("map preview: missing file name")
+		}
+		mapPreviewFilename = name
+		return nil
+	}
+}
+
+// MapIssue is a problem found while validating a generated map.
+// Fatal issues keep the map from being saved unless it is forced.
+type MapIssue struct {
+	Kind    string `json:"kind"` // "unreachable", "unnamed-region", "ambiguous-hidden", "no-cities", "landlocked", "gate", "road", "start"
+	Row     int    `json:"row"`
+	Col     int    `json:"col"`
+	Fatal   bool   `json:"fatal,omitempty"`
+	Message string `json:"message"`
+}
+
+// MapContinent summarizes one land region of a generated map.
+type MapContinent struct {
+	Name      string `json:"name"`
+	Row       int    `json:"row"`
+	Col       int    `json:"col"`
+	Provinces int    `json:"provinces"`
+	Cities    int    `json:"cities"`
+	GatesOut  int    `json:"gates-out"`
+	GatesIn   int    `json:"gates-in"`
+	Roads     int    `json:"roads"`   // roads leading to other regions
+	Coastal   bool   `json:"coastal"` // borders an ocean
+}
+
+// MapValidation is the result of checking a generated map.
+type MapValidation struct {
+	Issues     []MapIssue      `json:"issues,omitempty"`
+	Continents []*MapContinent `json:"continents"`
+}
+
+// Fatal returns the number of issues that keep the map from being saved.
+func (v *MapValidation) Fatal() int {
+	n := 0
+	for _, i := range v.Issues {
+		if i.Fatal {
+			n++
+		}
+	}
+	return n
+}
+
+func (v *MapValidation) add(kind string, t *tile, fatal bool, format string, args ...interface{}) {
+	v.Issues = append(v.Issues, MapIssue{Kind: kind, Row: t.row, Col: t.col, Fatal: fatal, Message: fmt.Sprintf(format, args...)})
+}
+
+// mg_tiles returns the provinces and sublocations of the generated map, keyed by entity number.
+func mg_tiles() map[int]*tile {
+	tiles := make(map[int]*tile)
+	for row := 0; row < MAX_ROW; row++ {
+		for col := 0; col < MAX_COL; col++ {
+			if map_[row][col] != nil {
+				tiles[map_[row][col].region] = map_[row][col]
+			}
+		}
+	}
+	for i := 1; i <= top_subloc; i++ {
+		tiles[subloc_mg[i].region] = subloc_mg[i]
+	}
+	return tiles
+}
+
+// mg_province returns the province a tile is in.
+func mg_province(t *tile) *tile {
+	if t.depth == 3 {
+		return map_[t.row][t.col]
+	}
+	return t
+}
+
+// is_inferred_land is true for map characters that don't give a terrain,
+// so the terrain is taken from a neighbor.
+func is_inferred_land(c byte) bool {
+	return c == '?' || c == '*' || c == '%'
+}
+
+// validate_map checks the generated map for land that can't be reached
+// from the start cities, regions that weren't named in the region file,
+// hidden provinces whose terrain couldn't be inferred cleanly, and
+// continents without cities or connections.
+func validate_map() *MapValidation {
+	v := &MapValidation{}
+	tiles := mg_tiles()
+
+	// ambiguous hidden provinces: the terrain is copied from a neighbor,
+	// so the neighbors should agree on what it is.
+	for row := 0; row < MAX_ROW; row++ {
+		for col := 0; col < MAX_COL; col++ {
+			t := map_[row][col]
+			if t == nil || t.save_char != '?' {
+				continue
+			}
+			seen := make(map[int]bool)
+			var terrains []string
+			for dir := 1; dir < MG_MAX_DIR; dir++ {
+				p := adjacent_tile_sup(row, col, dir)
+				if p == nil || p.terrain == terr_ocean || is_inferred_land(p.save_char) || seen[p.terrain] {
+					continue
+				}
+				seen[p.terrain] = true
+				terrains = append(terrains, terrainStr[p.terrain])
+			}
+			sort.Strings(terrains)
+			if len(terrains) == 0 {
+				v.add("ambiguous-hidden", t, false, "hidden province has no land neighbors to take its terrain from; made it %s", terrainStr[t.terrain])
+			} else if len(terrains) > 1 {
+				v.add("ambiguous-hidden", t, false, "hidden province borders %s; made it %s", strings.Join(terrains, ", "), terrainStr[t.terrain])
+			}
+		}
+	}
+
+	// regions without a name in the region file
+	for i := 1; i <= inside_top; i++ {
+		if len(inside_list[i]) == 0 || inside_names[i] != "" {
+			continue
+		}
+		p := inside_list[i][0]
+		what := "land"
+		if p.terrain == terr_ocean {
+			what = "sea"
+		}
+		v.add("unnamed-region", p, false, "%s region of %d provinces is not in the region file", what, len(inside_list[i]))
+	}
+
+	// reachability, starting from the safe havens (or any city if there are none)
+	var start []*tile
+	for i := 1; i <= top_subloc; i++ {
+		if subloc_mg[i].terrain == terr_city && subloc_mg[i].safe_haven != FALSE {
+			start = append(start, subloc_mg[i])
+		}
+	}
+	if len(start) == 0 {
+		for i := 1; i <= top_subloc; i++ {
+			if subloc_mg[i].terrain == terr_city {
+				start = append(start, subloc_mg[i])
+			}
+		}
+	}
+	reached := make(map[int]bool)
+	for _, t := range start {
+		reached[t.region] = true
+	}
+	for len(start) != 0 {
+		t := start[0]
+		start = start[1:]
+
+		var next []int
+		if t.depth == 3 {
+			next = append(next, t.inside)
+		} else {
+			for _, dir := range []int{MG_DIR_N, MG_DIR_E, MG_DIR_S, MG_DIR_W} {
+				next = append(next, prov_dest(t, dir))
+			}
+		}
+		next = append(next, t.subs...)
+		next = append(next, t.gates_dest...)
+		for _, r := range t.roads {
+			if r != nil {
+				next = append(next, r.to_loc)
+			}
+		}
+
+		for _, n := range next {
+			if p, ok := tiles[n]; ok && !reached[n] {
+				reached[n] = true
+				start = append(start, p)
+			}
+		}
+	}
+	if len(reached) == 0 {
+		v.Issues = append(v.Issues, MapIssue{Kind: "start", Fatal: true, Message: "the map has no cities to start from"})
+	} else {
+		for i := 1; i <= inside_top; i++ {
+			if len(inside_list[i]) == 0 || inside_list[i][0].terrain == terr_ocean {
+				continue
+			}
+			var cut []*tile
+			for _, t := range inside_list[i] {
+				if !reached[t.region] {
+					cut = append(cut, t)
+				}
+			}
+			if len(cut) != 0 {
+				v.add("unreachable", cut[0], true, "%d of %d provinces in %s can't be reached from the start cities", len(cut), len(inside_list[i]), region_label(i))
+			}
+		}
+	}
+
+	// gates and roads must lead somewhere
+	for _, t := range tiles {
+		for _, dest := range t.gates_dest {
+			if _, ok := tiles[dest]; !ok {
+				v.add("gate", t, true, "gate leads to %d, which isn't on the map", dest)
+			}
+		}
+		for _, r := range t.roads {
+			if r == nil {
+				continue
+			} else if _, ok := tiles[r.to_loc]; !ok {
+				v.add("road", t, true, "road %q leads to %d, which isn't on the map", r.name, r.to_loc)
+			}
+		}
+	}
+
+	// the continents, with their cities and connections
+	for i := 1; i <= inside_top; i++ {
+		if len(inside_list[i]) == 0 || inside_list[i][0].terrain == terr_ocean {
+			continue
+		}
+		p := inside_list[i][0]
+		c := &MapContinent{
+			Name:      region_label(i),
+			Row:       p.row,
+			Col:       p.col,
+			Provinces: len(inside_list[i]),
+			Cities:    inside_num_cities[i],
+			GatesOut:  inside_gates_from[i],
+			GatesIn:   inside_gates_to[i],
+		}
+		for _, t := range inside_list[i] {
+			for _, dir := range []int{MG_DIR_N, MG_DIR_E, MG_DIR_S, MG_DIR_W} {
+				if n, ok := tiles[prov_dest(t, dir)]; ok && n.terrain == terr_ocean {
+					c.Coastal = true
+				}
+			}
+			here := []*tile{t}
+			for _, s := range t.subs {
+				if st, ok := tiles[s]; ok {
+					here = append(here, st)
+				}
+			}
+			for _, h := range here {
+				for _, r := range h.roads {
+					if r == nil {
+						continue
+					} else if d, ok := tiles[r.to_loc]; ok && mg_province(d).inside != i {
+						c.Roads++
+					}
+				}
+			}
+		}
+		v.Continents = append(v.Continents, c)
+
+		if c.Cities == 0 {
+			v.add("no-cities", p, false, "%s has no cities", c.Name)
+		}
+		if !c.Coastal && c.GatesIn == 0 && c.Roads == 0 {
+			v.add("landlocked", p, false, "%s has no coast, gates, or roads to other regions", c.Name)
+		}
+	}
+
+	sort.SliceStable(v.Issues, func(i, j int) bool {
+		if v.Issues[i].Fatal != v.Issues[j].Fatal {
+			return v.Issues[i].Fatal
+		} else if v.Issues[i].Row != v.Issues[j].Row {
+			return v.Issues[i].Row < v.Issues[j].Row
+		}
+		return v.Issues[i].Col < v.Issues[j].Col
+	})
+	return v
+}
+
+// region_label returns the name of a region, or its location if it has none.
+func region_label(i int) string {
+	if inside_names[i] != "" {
+		return inside_names[i]
+	}
+	p := inside_list[i][0]
+	return fmt.Sprintf("the unnamed region at (%d,%d)", p.row, p.col)
+}
+
+// map_preview draws the generated map the same way "map render" draws the database.
+func map_preview() *worldmap.Map {
+	tiles := mg_tiles()
+	m := &worldmap.Map{
+		Title: fmt.Sprintf("Generated map (%d provinces, %d sublocations)", land_count+water_count, top_subloc),
+		Rows:  max_row + 1,
+		Cols:  max_col + 1,
+	}
+
+	for row := 0; row <= max_row; row++ {
+		for col := 0; col <= max_col; col++ {
+			t := map_[row][col]
+			if t == nil {
+				continue
+			}
+			p := &worldmap.Province{
+				Id:      t.region,
+				Row:     row,
+				Col:     col,
+				Name:    or_string(t.name == "Unnamed", "", t.name),
+				Terrain: terrainStr[t.terrain],
+				Region:  t.inside,
+				SeaLane: t.sea_lane != FALSE,
+			}
+			for _, s := range t.subs {
+				if st, ok := tiles[s]; ok && st.terrain == terr_city {
+					p.Cities = append(p.Cities, st.name)
+				}
+			}
+			m.Provinces = append(m.Provinces, p)
+		}
+	}
+
+	for _, t := range tiles {
+		from := mg_province(t)
+		for i, dest := range t.gates_dest {
+			if d, ok := tiles[dest]; ok {
+				m.Gates = append(m.Gates, worldmap.Link{Id: t.gates_num[i], From: from.region, To: mg_province(d).region})
+			}
+		}
+		for _, r := range t.roads {
+			if r == nil {
+				continue
+			} else if d, ok := tiles[r.to_loc]; ok {
+				m.Roads = append(m.Roads, worldmap.Link{Id: r.ent_num, From: from.region, To: mg_province(d).region})
+			}
+		}
+	}
+
+	m.Sort()
+	return m
+}
+
+// write_map_preview draws the generated map to mapPreviewFilename.
+func write_map_preview() error {
+	m := map_preview()
+	b := &bytes.Buffer{}
+	var err error
+	if strings.EqualFold(filepath.Ext(mapPreviewFilename), ".png") {
+		err = m.PNG(b, worldmap.Options{})
+	} else {
+		err = m.SVG(b, worldmap.Options{})
+	}
+	if err != nil {
+		return fmt.Errorf("write_map_preview: %w", err)
+	} else if err = os.WriteFile(mapPreviewFilename, b.Bytes(), 0666); err != nil {
+		return fmt.Errorf("write_map_preview: %w", err)
+	}
+	log.Printf("write_map_preview: created %s\n", mapPreviewFilename)
+	return nil
+}
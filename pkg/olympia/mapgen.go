@@ -24,7 +24,6 @@ import (
 	"fmt"
 	"github.com/mdhender/golympia/pkg/io"
 	"log"
-	"math/rand"
 	"os"
 	"strings"
 )
@@ -373,7 +372,7 @@ func bridge_corner_sup(row, col int) int {
 		return FALSE
 	}
 	e := adjacent_tile_sup(row, col, MG_DIR_E)
-	if s != nil && e.mark != 0 {
+	if e != nil && e.mark != 0 {
 		return FALSE
 	}
 	w := adjacent_tile_sup(row, col, MG_DIR_W)
@@ -496,7 +495,7 @@ func bridge_map_hole_sup(row, col int) int {
 		return FALSE
 	}
 	e := adjacent_tile_sup(row, col, MG_DIR_E)
-	if s != nil && e.mark != 0 {
+	if e != nil && e.mark != 0 {
 		return FALSE
 	}
 	w := adjacent_tile_sup(row, col, MG_DIR_W)
@@ -1266,7 +1265,7 @@ func make_islands() {
 			}
 		}
 	}
-	rand.Shuffle(len(available), func(i, j int) {
+	shuffle(len(available), func(i, j int) {
 		available[i], available[j] = available[j], available[i]
 	})
 
@@ -1520,7 +1519,11 @@ func random_city_name() string {
 		var data struct {
 			Cities []string `json:"cities"`
 		}
-		buf, err := os.ReadFile("cities.json")
+		name := cityDataFilename
+		if name == "" {
+			name = "cities.json"
+		}
+		buf, err := os.ReadFile(name)
 		if err != nil {
 			log.Printf("random_city_name: %+v\n", err)
 		} else if err = json.Unmarshal(buf, &data); err != nil {
@@ -1528,7 +1531,7 @@ func random_city_name() string {
 		} else {
 			_static_random_city_name.cities = data.Cities
 		}
-		log.Printf("%q: loaded %d city names\n", "cities.json", len(_static_random_city_name.cities))
+		log.Printf("%q: loaded %d city names\n", name, len(_static_random_city_name.cities))
 		if _static_random_city_name.cities == nil {
 			_static_random_city_name.cities = []string{"T'othville"}
 		}
@@ -1677,7 +1680,7 @@ func random_tile_from_each_region() []*tile {
 
 func randomize_dir_vector() {
 	snap := []int{MG_DIR_N, MG_DIR_NE, MG_DIR_E, MG_DIR_SE, MG_DIR_S, MG_DIR_SW, MG_DIR_W, MG_DIR_NW}
-	rand.Shuffle(len(snap), func(i, j int) {
+	shuffle(len(snap), func(i, j int) {
 		snap[i], snap[j] = snap[j], snap[i]
 	})
 	dir_vector[0] = 0
@@ -1790,9 +1793,10 @@ func read_map(name string) {
 				case 10:
 					terrain = terr_desert
 				}
-			//case '?':
-			//	map_[row][col].hidden = TRUE;
-			//	terrain = terr_land;
+			case '?':
+				// the terrain is inferred from a neighbor by fix_terrain_land
+				map_[row][col].hidden = TRUE
+				terrain = terr_land
 
 			// special stuff
 
@@ -2099,7 +2103,7 @@ func show_gate_coverage() {
 func shuffle_exits(l []*exit_view) []*exit_view {
 	var cp []*exit_view
 	cp = append(cp, l...)
-	rand.Shuffle(len(l), func(i, j int) {
+	shuffle(len(l), func(i, j int) {
 		cp[i], cp[j] = cp[j], cp[i]
 	})
 	return cp
@@ -2107,7 +2111,7 @@ func shuffle_exits(l []*exit_view) []*exit_view {
 
 func shuffle_ints(i []int) (l []int) {
 	l = append(l, i...)
-	rand.Shuffle(len(l), func(i, j int) {
+	shuffle(len(l), func(i, j int) {
 		l[i], l[j] = l[j], l[i]
 	})
 	return l
@@ -2115,7 +2119,7 @@ func shuffle_ints(i []int) (l []int) {
 
 func shuffle_tiles(t []*tile) (l []*tile) {
 	l = append(l, t...)
-	rand.Shuffle(len(l), func(i, j int) {
+	shuffle(len(l), func(i, j int) {
 		l[i], l[j] = l[j], l[i]
 	})
 	return l
@@ -46,25 +46,32 @@ func (m *entity_mine) IsZero() bool {
 	return true
 }
 
+// ToEntityMine keeps every level of the mine, even the empty ones,
+// since the position in the list is the depth of the shaft.
 func (m *entity_mine) ToEntityMine() *EntityMine {
-	if m == nil || m.IsZero() {
+	if m == nil {
 		return nil
 	}
 	em := &EntityMine{}
-	for _, e := range m.mc {
-		if e.IsZero() {
-			continue
-		}
-		em.Contents = append(em.Contents, e.ToMineContents())
+	for i := 0; i < MINE_MAX; i++ {
+		em.Contents = append(em.Contents, m.mc[i].ToMineContents())
+		em.Shoring = append(em.Shoring, m.shoring[i])
 	}
-	for _, e := range m.shoring {
-		if e == 0 {
-			continue
+	return em
+}
+
+func (m *EntityMine) toBox() *entity_mine {
+	if m == nil {
+		return nil
+	}
+	em := &entity_mine{}
+	for i := 0; i < MINE_MAX && i < len(m.Contents); i++ {
+		if m.Contents[i] != nil {
+			em.mc[i].items = m.Contents[i].Items.toBox()
 		}
-		em.Shoring = append(em.Shoring, e)
 	}
-	if len(em.Contents) == 0 && len(em.Shoring) == 0 {
-		return nil
+	for i := 0; i < MINE_MAX && i < len(m.Shoring); i++ {
+		em.shoring[i] = m.Shoring[i]
 	}
 	return em
 }
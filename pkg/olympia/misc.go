@@ -63,3 +63,74 @@ func MiscDataSave(name string) error {
 	}
 	return nil
 }
+
+// EntityMisc is the json version of the miscellaneous entity data.
+type EntityMisc struct {
+	Display        string `json:"display,omitempty"`         // entity display banner
+	NpcCreated     int    `json:"npc-created,omitempty"`     // turn peasant mob created
+	NpcHome        int    `json:"npc-home,omitempty"`        // where npc was created
+	NpcCookie      int    `json:"npc-cookie,omitempty"`      // allocation cookie item for us
+	SummonedBy     int    `json:"summoned-by,omitempty"`     // who summoned us?
+	SaveName       string `json:"save-name,omitempty"`       // orig name of noble for dead bodies
+	OldLord        int    `json:"old-lord,omitempty"`        // who did this dead body used to belong to
+	NpcMemory      sparse `json:"npc-memory,omitempty"`      //
+	OnlyVulnerable int    `json:"only-vulnerable,omitempty"` // only defeatable with this rare artifact
+	GarrCastle     int    `json:"garr-castle,omitempty"`     // castle which owns this garrison
+	BindStorm      int    `json:"bind-storm,omitempty"`      // storm bound to this ship
+	StormStr       int    `json:"storm-str,omitempty"`       // storm strength
+	NpcDir         int    `json:"npc-dir,omitempty"`         // last direction npc moved
+	MineDelay      int    `json:"mine-delay,omitempty"`      // time until collapsed mine vanishes
+	CmdAllow       string `json:"cmd-allow,omitempty"`       // unit under restricted control
+}
+
+func (e *entity_misc) ToEntityMisc() *EntityMisc {
+	if e == nil {
+		return nil
+	}
+	em := &EntityMisc{
+		Display:        e.display,
+		NpcCreated:     e.npc_created,
+		NpcHome:        e.npc_home,
+		NpcCookie:      e.npc_cookie,
+		SummonedBy:     e.summoned_by,
+		SaveName:       e.save_name,
+		OldLord:        e.old_lord,
+		NpcMemory:      append(sparse(nil), e.npc_memory...),
+		OnlyVulnerable: e.only_vulnerable,
+		GarrCastle:     e.garr_castle,
+		BindStorm:      e.bind_storm,
+		StormStr:       e.storm_str,
+		NpcDir:         e.npc_dir,
+		MineDelay:      e.mine_delay,
+	}
+	if e.cmd_allow != 0 {
+		em.CmdAllow = string([]byte{e.cmd_allow})
+	}
+	return em
+}
+
+func (e *EntityMisc) toBox() *entity_misc {
+	if e == nil {
+		return nil
+	}
+	em := &entity_misc{
+		display:         e.Display,
+		npc_created:     e.NpcCreated,
+		npc_home:        e.NpcHome,
+		npc_cookie:      e.NpcCookie,
+		summoned_by:     e.SummonedBy,
+		save_name:       e.SaveName,
+		old_lord:        e.OldLord,
+		npc_memory:      append(sparse(nil), e.NpcMemory...),
+		only_vulnerable: e.OnlyVulnerable,
+		garr_castle:     e.GarrCastle,
+		bind_storm:      e.BindStorm,
+		storm_str:       e.StormStr,
+		npc_dir:         e.NpcDir,
+		mine_delay:      e.MineDelay,
+	}
+	if len(e.CmdAllow) != 0 {
+		em.cmd_allow = e.CmdAllow[0]
+	}
+	return em
+}
@@ -32,20 +32,20 @@ var monster_tbl = EntityItemList{
 		Defense:    1,
 		IsManItem:  TRUE,
 		LandCap:    100,
-		Prominent:  true,
+		Prominent:  TRUE,
 		Weight:     100,
 	},
 	{Id: 55,
 		Kind:       "item",
 		Name:       "nazgul",
-		Animal:     true,
+		Animal:     TRUE,
 		Attack:     80,
-		Capturable: true,
+		Capturable: TRUE,
 		Defense:    80,
 		FlyCap:     150,
 		LandCap:    150,
 		PluralName: "nazgul",
-		Prominent:  true,
+		Prominent:  TRUE,
 		RideCap:    150,
 		Weight:     1500},
 	{Id: 101,
@@ -83,12 +83,12 @@ var monster_tbl = EntityItemList{
 		Name:       "orc",
 		PluralName: "orcs",
 		Attack:     20,
-		Capturable: true,
+		Capturable: TRUE,
 		Defense:    15,
 		FlyCap:     0,
 		IsManItem:  TRUE,
 		LandCap:    100,
-		Prominent:  true,
+		Prominent:  TRUE,
 		RideCap:    1,
 		Weight:     100,
 	},
@@ -78,3 +78,47 @@ func NationDataSave(name string) error {
 	}
 	return nil
 }
+
+// EntityNation is the json version of a nation.
+type EntityNation struct {
+	Name             string `json:"name,omitempty"`              // Name of the nation, e.g., Mandor Empire
+	Citizen          string `json:"citizen,omitempty"`           // Name of a citizen, e.g., Mandorean
+	Win              int    `json:"win,omitempty"`               // Win?
+	ProscribedSkills []int  `json:"proscribed-skills,omitempty"` // Skills you can't have...
+	PlayerLimit      int    `json:"player-limit,omitempty"`      // Limit to # of players
+	Capital          int    `json:"capital,omitempty"`           // Capital city.
+	JumpStart        int    `json:"jump-start,omitempty"`        // Jump start points to start
+	Neutral          bool   `json:"neutral,omitempty"`           // Can't capture/lose NPs.
+}
+
+func (e *entity_nation) ToEntityNation() *EntityNation {
+	if e == nil {
+		return nil
+	}
+	return &EntityNation{
+		Name:             e.name,
+		Citizen:          e.citizen,
+		Win:              e.win,
+		ProscribedSkills: append([]int(nil), e.proscribed_skills...),
+		PlayerLimit:      e.player_limit,
+		Capital:          e.capital,
+		JumpStart:        e.jump_start,
+		Neutral:          e.neutral,
+	}
+}
+
+func (e *EntityNation) toBox() *entity_nation {
+	if e == nil {
+		return nil
+	}
+	return &entity_nation{
+		name:              e.Name,
+		citizen:           e.Citizen,
+		win:               e.Win,
+		proscribed_skills: append([]int(nil), e.ProscribedSkills...),
+		player_limit:      e.PlayerLimit,
+		capital:           e.Capital,
+		jump_start:        e.JumpStart,
+		neutral:           e.Neutral,
+	}
+}
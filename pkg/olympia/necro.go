@@ -347,7 +347,7 @@ func d_eat_dead(c *command) int {
 	 *  If the body was a priest, well, that's good for your mana!
 	 *
 	 */
-	if options.mp_antipathy && is_priest(body) != FALSE && rp_magic(c.who) != nil {
+	if game_rules.MPAntipathy && is_priest(body) != FALSE && rp_magic(c.who) != nil {
 		//p := p_magic(c.who);
 		wout(c.who, "Your mana grows stronger on the soul of a priest!")
 		add_aura(c.who, 15)
@@ -384,95 +384,68 @@ func random_body_here(where int) int {
 	return l[0]
 }
 
-////#if 0
-//int
-//v_exhume(c *command)
-//{
-//    where := subloc(c.who);
-//    int targ = c.a;
-//    int n;
-//
-//    if (subkind(where) != sub_graveyard)
-//    {
-//        wout(c.who, "Bodies may only be exhumed in graveyards.");
-//        return FALSE;
-//    }
-//
-//    if (targ &&
-//        (!valid_box(targ) ||
-//        subkind(targ) != sub_dead_body ||
-//        has_item(where, targ) == 0))
-//    {
-//        wout(c.who, "No body %s is buried here.", box_code(targ));
-//        return FALSE;
-//    }
-//
-//    if (!targ && (n = random_body_here(where)) == 0)
-//    {
-//        wout(c.who, "There are no fresh graves here to dig up.");
-//        return FALSE;
-//    }
-//
-//    if (!targ)
-//        targ = n;
-//
-//    if (sysclock.turn == p_char(targ).death_time.turn)
-//    {
-//        wout(c.who, "%s may not be exhumed until next month.",
-//                        cap_(box_name(targ)));
-//        return FALSE;
-//    }
-//
-//    return TRUE;
-//}
-//
-//
-//int
-//d_exhume(c *command)
-//{
-//    where := subloc(c.who);
-//    int targ = c.a;
-//    int n;
-//
-//    if (subkind(where) != sub_graveyard)
-//    {
-//        wout(c.who, "Bodies may only be exhumed in graveyards.");
-//        return FALSE;
-//    }
-//
-//    if (targ &&
-//        (!valid_box(targ) ||
-//        subkind(targ) != sub_dead_body ||
-//        has_item(where, targ) == 0))
-//    {
-//        wout(c.who, "No body %s is buried here.", box_code(targ));
-//        return FALSE;
-//    }
-//
-//    if (!targ && (n = random_body_here(where)) == 0)
-//    {
-//        wout(c.who, "There are no fresh graves here to dig up.");
-//        return FALSE;
-//    }
-//
-//    if (!targ)
-//        targ = n;
-//
-//    if (sysclock.turn == p_char(targ).death_time.turn)
-//    {
-//        wout(c.who, "%s may not be exhumed until next month.",
-//                        cap_(box_name(targ)));
-//        return FALSE;
-//    }
-//
-//    move_item(where, c.who, targ, 1);
-//
-//    wout(c.who, "Exhumed %s.", box_name(targ));
-//    wout(where, "%s exhumed %s.", box_name(c.who), box_name(targ));
-//
-//    return TRUE;
-//}
-////#endif
+/*
+ *  Exhuming was compiled out of the C engine, so the default command
+ *  definitions don't use it.  A game may enable it with an "exhume"
+ *  entry in its commands.json.
+ */
+
+// exhume_target returns the body to dig up, or 0 after telling the unit why it can't.
+func exhume_target(c *command) int {
+	where := subloc(c.who)
+	targ := c.a
+
+	if subkind(where) != sub_graveyard {
+		wout(c.who, "Bodies may only be exhumed in graveyards.")
+		return 0
+	}
+
+	if targ != 0 &&
+		(!valid_box(targ) ||
+			subkind(targ) != sub_dead_body ||
+			has_item(where, targ) == 0) {
+		wout(c.who, "No body %s is buried here.", box_code(targ))
+		return 0
+	}
+
+	if targ == 0 {
+		if targ = random_body_here(where); targ == 0 {
+			wout(c.who, "There are no fresh graves here to dig up.")
+			return 0
+		}
+	}
+
+	if sysclock.turn == p_char(targ).death_time.turn {
+		wout(c.who, "%s may not be exhumed until next month.",
+			cap_(box_name(targ)))
+		return 0
+	}
+
+	return targ
+}
+
+func v_exhume(c *command) int {
+	if exhume_target(c) == 0 {
+		return FALSE
+	}
+	return TRUE
+}
+
+func d_exhume(c *command) int {
+	where := subloc(c.who)
+
+	targ := exhume_target(c)
+	if targ == 0 {
+		return FALSE
+	}
+
+	move_item(where, c.who, targ, 1)
+
+	wout(c.who, "Exhumed %s.", box_name(targ))
+	wout(where, "%s exhumed %s.", box_name(c.who), box_name(targ))
+
+	return TRUE
+}
 
 func auto_undead(who int) {
 	var master int
@@ -20,7 +20,6 @@
 package olympia
 
 import (
-	"fmt"
 	"time"
 )
 
@@ -290,10 +289,10 @@ type accept_ent struct {
 }
 
 type Admit struct {
-	Flag  int    // first time set this turn -- not saved
-	List  ints_l //
-	Sense int    // 0=default no, 1=all but..
-	Targ  int    // char or loc Admit is declared for
+	Flag  int    `json:"-"`               // first time set this turn -- not saved
+	List  ints_l `json:"list,omitempty"`  //
+	Sense int    `json:"sense,omitempty"` // 0=default no, 1=all but..
+	Targ  int    `json:"targ"`            // char or loc Admit is declared for
 }
 
 type box struct {
@@ -383,6 +382,8 @@ type cmd_tbl_ent struct {
 	arg_types         [5]int
 	cmd_comment       func(c *command) string
 	cmd_check         func(c *command)
+
+	help string /* one-line usage, from the command definitions */
 }
 
 type command struct {
@@ -517,27 +518,26 @@ func (ei *entity_item) IsZero() bool {
 
 // todo: must move id into EntityArtifact
 func (ei *entity_item) ToEntityArtifact(id int) *EntityArtifact {
-	if ei == nil || ei.x_item_artifact == nil || ei.x_item_artifact.IsZero() {
+	if ei == nil || ei.x_item_artifact == nil {
 		return nil
 	}
-	if ei.x_item_artifact.Id != id {
-		panic(fmt.Sprintf("assert(%d == %d)", ei.x_item_artifact.Id, id))
-	}
-	return ei.x_item_artifact
+	ea := *ei.x_item_artifact
+	ea.Id = id
+	return &ea
 }
 
 // todo: must move id into entity_item
 func (ei *entity_item) ToEntityItem(id int) *EntityItem {
-	if ei == nil || ei.IsZero() {
+	if ei == nil {
 		return nil
 	}
 	return &EntityItem{
 		Id:          id,
-		Animal:      ei.animal == TRUE,
+		Animal:      ei.animal,
 		AnimalPart:  ei.animal_part,
 		Attack:      ei.attack,
 		BasePrice:   ei.base_price,
-		Capturable:  ei.capturable == TRUE,
+		Capturable:  ei.capturable,
 		Defense:     ei.defense,
 		FlyCap:      ei.fly_cap,
 		IsManItem:   ei.is_man_item,
@@ -546,24 +546,23 @@ func (ei *entity_item) ToEntityItem(id int) *EntityItem {
 		Missile:     ei.missile,
 		NpcSplit:    ei.npc_split,
 		PluralName:  ei.plural_name,
-		Prominent:   ei.prominent == TRUE,
+		Prominent:   ei.prominent,
 		RideCap:     ei.ride_cap,
-		TradeGood:   ei.trade_good == TRUE,
-		Ungiveable:  ei.ungiveable == TRUE,
+		TradeGood:   ei.trade_good,
+		Ungiveable:  ei.ungiveable,
 		Weight:      ei.weight,
 		WhoHas:      ei.who_has,
-		Wild:        ei.wild == TRUE,
+		Wild:        ei.wild,
 	}
 }
 
 func (ei *entity_item) ToItemMagic(id int) *ItemMagic {
-	if ei == nil || ei.x_item_magic == nil || ei.x_item_magic.IsZero() {
+	if ei == nil || ei.x_item_magic == nil {
 		return nil
 	}
-	if ei.x_item_magic.Id != id {
-		panic(fmt.Sprintf("assert(%d == %d)", ei.x_item_magic.Id, id))
-	}
-	return ei.x_item_magic
+	im := *ei.x_item_magic
+	im.Id = id
+	return &im
 }
 
 type entity_loc struct {
@@ -601,7 +600,6 @@ type entity_misc struct {
 	npc_memory      sparse
 	only_vulnerable int  /* only defeatable with this rare artifact */
 	garr_castle     int  /* castle which owns this garrison */
-	border_open     int  /* Is the garrison's border open? */
 	bind_storm      int  /* storm bound to this ship */
 	storm_str       int  /* storm strength */
 	npc_dir         int  /* last direction npc moved */
@@ -609,6 +607,7 @@ type entity_misc struct {
 	cmd_allow       byte /* unit under restricted control */
 
 	// not saved:
+	border_open  int      // Is the garrison's border open?
 	opium_double schar    // improved opium production
 	post_txt     []string // text of posted sign
 	storm_move   int      // next loc storm will move to
@@ -653,7 +652,7 @@ type EntityPlayer struct {
 	NationList    int            `json:"nation-list,omitempty"`     // Receive the Nation mailing list?
 	NoblePoints   int            `json:"noble-points,omitempty"`    // how many NP's the player has
 	NoTab         bool           `json:"no-tab,omitempty"`          // player can't tolerate tabs
-	Orders        []*orders_list `json:"orders,omitempty"`          // list of Orders for units in this faction
+	Orders        []*orders_list `json:"-"`                         // list of Orders for units in this faction, saved by save_orders
 	Password      string         `json:"password,omitempty"`        //
 	RulesPath     string         `json:"rules-path,omitempty"`      // external path for HTML
 	SentOrders    int            `json:"sent-orders,omitempty"`     // sent in Orders this turn?
@@ -662,6 +661,7 @@ type EntityPlayer struct {
 	Unformed      ints_l         `json:"unformed,omitempty"`        // nobles as yet Unformed
 	Units         ints_l         `json:"units,omitempty"`           // what Units are in our faction?
 	VisEMail      string         `json:"vis-e-mail,omitempty"`      // address to put in player list
+	Visited       map[int]int    `json:"visited,omitempty"`         // turn each location was last visited
 
 	// not saved:
 	cmdCount      int    // count of cmds started this turn
@@ -808,6 +808,7 @@ type ItemMagic struct {
 	OrbUseCount  int    `json:"orb-use-count,omitempty"` // how many uses left in the orb
 	ProjectCast  int    `json:"project-cast,omitempty"`  // stored projected cast
 	QuickCast    int    `json:"quick-cast,omitempty"`    // stored quick cast
+	RegionCreat  int    `json:"region-creat,omitempty"`  // province the item was made in
 	Religion     int    `json:"religion,omitempty"`      // Might be a religious artifact
 	TokenNI      int    `json:"token-ni,omitempty"`      // ni for controlled npc units
 	TokenNum     int    `json:"token-num,omitempty"`     // how many token controlled units
@@ -868,6 +869,17 @@ func (ot olytime) IsZero() bool {
 	return ot.day == 0 && ot.turn == 0 && ot.days_since_epoch == 0
 }
 
+func (ot *OlyTime) toBox() olytime {
+	if ot == nil {
+		return olytime{}
+	}
+	return olytime{
+		day:              ot.Day,
+		turn:             ot.Turn,
+		days_since_epoch: ot.DaysSinceEpoch,
+	}
+}
+
 func (ot olytime) ToOlyTime() *OlyTime {
 	if ot.IsZero() {
 		return nil
@@ -884,7 +896,6 @@ type options_struct struct {
 	created_at              time.Time // moment the system data file was first created
 	updated_at              time.Time // moment the system data file was last updated
 	accounting_dir          string    /* Directory to "join" from. */
-	accounting_prog         string    /* Path of the accounting program. */
 	auto_drop               bool      /* Drop non-responsive players. */
 	bottom_piety            int       /* Monthly +piety for everyone else */
 	check_balance           int       /* No orders w/o positive balance. */
@@ -898,6 +909,7 @@ type options_struct struct {
 	head_priest_piety_limit int       /* Head priest limited to head_priest_piety_limit * num_followers */
 	html_passwords          string    /* Path to html passwords */
 	html_path               string    /* Path to html directories */
+	mail_dir                string    /* Write mail to .eml files here instead of sending it. */
 	market_age              int       /* Months untouched in market before removal. */
 	middle_piety            int       /* Monthly +piety for junior priests */
 	min_piety               int       /* Any priest can have this much piety. */
@@ -906,6 +918,11 @@ type options_struct struct {
 	open_ended              bool      /* No end to game. */
 	output_tags             int       /* include <tag> in output */
 	piety_limit             int       /* Normal priest limited to piety_limit * num_followers */
+	smtp_host               string    /* Mail server for outgoing mail. */
+	smtp_password           string    /* Password for the mail server. */
+	smtp_port               int       /* Port on the mail server, default 25. */
+	smtp_starttls           bool      /* Require STARTTLS from the mail server. */
+	smtp_user               string    /* User name for the mail server. */
 	survive_np              bool      /* Does SFW return NPs when forgotten? */
 	times_pay               int       /* What the Times pays for an article. */
 	top_piety               int       /* Monthly +piety for head priest */
@@ -1184,7 +1201,6 @@ func rp_subloc(n int) *entity_subloc {
 	return nil
 }
 
-// func	item_creat_loc(n int) int {if rp_item_magic(n) != nil {return rp_item_magic(n).region_created} else {return 0}}
 // func	loc_link_open(n int) int {if rp_subloc(n) != nil {return rp_subloc(n).link_open} else {return 0}}
 // func	mine_depth(n int) int {if rp_subloc(n) != nil {return rp_subloc(n).shaft_depth / 3} else {return 0}}
 // func char_pledge(n int) int {if rp_magic(n) != nil {return rp_magic(n).pledge} else {return 0}}
@@ -1444,6 +1460,12 @@ func item_creat_cloak(n int) int {
 	}
 	return 0
 }
+func item_creat_loc(n int) int {
+	if rp_item_magic(n) != nil {
+		return rp_item_magic(n).RegionCreat
+	}
+	return 0
+}
 func item_creator(n int) int {
 	if rp_item_magic(n) != nil {
 		return rp_item_magic(n).Creator
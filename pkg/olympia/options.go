@@ -38,6 +38,14 @@ var (
 	seedDataFilename      string
 )
 
+// turn processing flags, set by the With* options below.
+var (
+	eat_once           = false // process the spool once instead of polling it
+	inhibit_add_flag   = false // don't add new players during the turn
+	mail_now           = false // mail reports and acknowledgements
+	unspool_first_flag = false // eat waiting orders before running the turn
+)
+
 func WithCityData(name string) func() error {
 	return func() error {
 		if name == "" {
@@ -103,7 +111,7 @@ func WithLibPath(name string) func() error {
 		} else if !fi.Mode().IsDir() {
 			return fmt.Errorf("lib path: %w", fmt.Errorf("not a directory"))
 		}
-		locationDataFilename = name
+		libdir = name
 		return nil
 	}
 }
@@ -177,3 +185,56 @@ func WithSeedData(name string) func() error {
 		return nil
 	}
 }
+
+func WithEatOnce(flag bool) func() error {
+	return func() error {
+		eat_once = flag
+		return nil
+	}
+}
+
+func WithImmediateAfter(flag bool) func() error {
+	return func() error {
+		if flag {
+			immed_after = TRUE
+		} else {
+			immed_after = FALSE
+		}
+		return nil
+	}
+}
+
+func WithInhibitAdd(flag bool) func() error {
+	return func() error {
+		inhibit_add_flag = flag
+		return nil
+	}
+}
+
+func WithMailNow(flag bool) func() error {
+	return func() error {
+		mail_now = flag
+		return nil
+	}
+}
+
+func WithSaveDB(flag bool) func() error {
+	return func() error {
+		save_flag = flag
+		return nil
+	}
+}
+
+func WithTimeSelf(flag bool) func() error {
+	return func() error {
+		time_self = flag
+		return nil
+	}
+}
+
+func WithUnspoolFirst(flag bool) func() error {
+	return func() error {
+		unspool_first_flag = flag
+		return nil
+	}
+}
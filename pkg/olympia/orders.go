@@ -0,0 +1,478 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/mailer"
+	"github.com/mdhender/golympia/pkg/report"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OrderMessage is one set of orders waiting to be scanned.
+type OrderMessage struct {
+	Source string // name of the source, used when archiving
+	ID     string // identifies the message within the source
+	Data   []byte // the raw message, headers and all
+	Player int    // set when the source has already authenticated the player
+}
+
+// OrderSource supplies messages to the order scanner.
+type OrderSource interface {
+	Name() string
+	// Fetch returns the messages waiting to be scanned.
+	Fetch() ([]*OrderMessage, error)
+	// Remove is called after the message has been scanned and archived.
+	// A source that removes messages in a batch also implements io.Closer;
+	// Close is called once all the messages from Fetch have been handled.
+	Remove(m *OrderMessage) error
+}
+
+var (
+	ack_delay          = 5 * time.Second  // pause between acknowledgements
+	mbox_lock_wait     = 30 * time.Second // how long to wait for a mail delivery agent to release an mbox
+	mbox_lock_stale    = 5 * time.Minute  // age at which an mbox dot lock is assumed to be abandoned
+	archive_seq        = 0
+	extra_order_source []OrderSource // sources in addition to the spool
+)
+
+// WithMaildir adds a Maildir to the order sources.
+func WithMaildir(path string) func() error {
+	return func() error {
+		if path == "" {
+			return fmt.Errorf("maildir: missing path name")
+		}
+		extra_order_source = append(extra_order_source, &maildir_source{path: path})
+		return nil
+	}
+}
+
+// WithMbox adds an mbox file to the order sources.
+func WithMbox(path string) func() error {
+	return func() error {
+		if path == "" {
+			return fmt.Errorf("mbox: missing path name")
+		}
+		extra_order_source = append(extra_order_source, &mbox_source{path: path})
+		return nil
+	}
+}
+
+// WithOrderSource adds a caller supplied source to the order sources.
+func WithOrderSource(src OrderSource) func() error {
+	return func() error {
+		if src == nil {
+			return fmt.Errorf("order source: missing source")
+		}
+		extra_order_source = append(extra_order_source, src)
+		return nil
+	}
+}
+
+// order_sources returns the spool followed by any other configured sources.
+func order_sources() []OrderSource {
+	return append([]OrderSource{&spool_source{path: filepath.Join(libdir, "spool")}}, extra_order_source...)
+}
+
+// spool_source reads the files that the mail pipeline drops in libdir/spool.
+// Only files whose names start with "m" are messages.
+// Files whose names start with "w" are orders from the portal, which has
+// already signed the player in; the faction code follows the last dot.
+type spool_source struct {
+	path string
+}
+
+func (s *spool_source) Name() string {
+	return "spool"
+}
+
+func (s *spool_source) Fetch() ([]*OrderMessage, error) {
+	files, err := os.ReadDir(s.path)
+	if err != nil {
+		return nil, err
+	}
+	var msgs []*OrderMessage
+	for _, f := range files {
+		// ignore hidden and editor temp files
+		fname := f.Name()
+		web := strings.HasPrefix(fname, "w")
+		if f.IsDir() || !(web || strings.HasPrefix(fname, "m")) || strings.HasSuffix(fname, "~") || strings.HasSuffix(fname, ".swp") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.path, fname))
+		if err != nil {
+			return nil, err
+		}
+		m := &OrderMessage{Source: s.Name(), ID: filepath.Join(s.path, fname), Data: data}
+		if web {
+			if who := code_to_int([]byte(fname[strings.LastIndexByte(fname, '.')+1:])); who > 0 && kind(who) == T_player {
+				m.Player = who
+			}
+		}
+		msgs = append(msgs, m)
+	}
+	return msgs, nil
+}
+
+func (s *spool_source) Remove(m *OrderMessage) error {
+	return os.Remove(m.ID)
+}
+
+// maildir_source reads new messages from a Maildir.
+// Scanned messages are moved to cur and flagged as seen.
+type maildir_source struct {
+	path string
+}
+
+func (s *maildir_source) Name() string {
+	return "maildir"
+}
+
+func (s *maildir_source) Fetch() ([]*OrderMessage, error) {
+	files, err := os.ReadDir(filepath.Join(s.path, "new"))
+	if err != nil {
+		return nil, err
+	}
+	var msgs []*OrderMessage
+	for _, f := range files {
+		if f.IsDir() || strings.HasPrefix(f.Name(), ".") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.path, "new", f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, &OrderMessage{Source: s.Name(), ID: f.Name(), Data: data})
+	}
+	return msgs, nil
+}
+
+func (s *maildir_source) Remove(m *OrderMessage) error {
+	name := m.ID
+	if !strings.Contains(name, ":2,") {
+		name += ":2,"
+	}
+	if !strings.HasSuffix(name, "S") {
+		name += "S"
+	}
+	return os.Rename(filepath.Join(s.path, "new", m.ID), filepath.Join(s.path, "cur", name))
+}
+
+// mbox_source reads messages from an mbox file.
+// Scanned messages are cut from the file by Close, in one pass.
+// The file is read and rewritten under a dot lock and an flock, the
+// locks that mail delivery agents take before appending to an mbox.
+type mbox_source struct {
+	path    string
+	offsets map[*OrderMessage]int // where the message started when we read the file
+	raw     map[*OrderMessage][]byte
+	removed []*OrderMessage // scanned messages, in the order Remove saw them
+}
+
+func (s *mbox_source) Name() string {
+	return "mbox"
+}
+
+func (s *mbox_source) Fetch() ([]*OrderMessage, error) {
+	fp, unlock, err := mbox_lock(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(fp)
+	unlock()
+	if err != nil {
+		return nil, err
+	}
+	s.offsets, s.raw, s.removed = make(map[*OrderMessage]int), make(map[*OrderMessage][]byte), nil
+
+	// a message starts with a "From " line at the start of the file or after a blank line
+	var starts []int
+	for i := 0; i < len(data); {
+		if bytes.HasPrefix(data[i:], []byte("From ")) && (i == 0 || bytes.HasSuffix(data[:i], []byte("\n\n"))) {
+			starts = append(starts, i)
+		}
+		nl := bytes.IndexByte(data[i:], '\n')
+		if nl == -1 {
+			break
+		}
+		i += nl + 1
+	}
+
+	var msgs []*OrderMessage
+	for n, start := range starts {
+		end := len(data)
+		if n+1 < len(starts) {
+			end = starts[n+1]
+		}
+		m := &OrderMessage{Source: s.Name(), ID: fmt.Sprintf("%s:%d", s.path, start), Data: mbox_unquote(data[start:end])}
+		s.offsets[m], s.raw[m] = start, data[start:end]
+		msgs = append(msgs, m)
+	}
+	return msgs, nil
+}
+
+func (s *mbox_source) Remove(m *OrderMessage) error {
+	if _, ok := s.offsets[m]; !ok {
+		return fmt.Errorf("%s: unknown message", m.ID)
+	}
+	s.removed = append(s.removed, m)
+	return nil
+}
+
+// Close cuts the removed messages from the file.
+// Messages delivered since Fetch are after the ones we read, so they are kept.
+func (s *mbox_source) Close() error {
+	if len(s.removed) == 0 {
+		return nil
+	}
+	removed := s.removed
+	s.removed = nil
+	sort.Slice(removed, func(i, j int) bool {
+		return s.offsets[removed[i]] < s.offsets[removed[j]]
+	})
+
+	fp, unlock, err := mbox_lock(s.path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	data, err := io.ReadAll(fp)
+	if err != nil {
+		return err
+	}
+
+	var keep []byte
+	pos := 0
+	for _, m := range removed {
+		start, raw := s.offsets[m], s.raw[m]
+		if start < pos || start+len(raw) > len(data) || !bytes.Equal(data[start:start+len(raw)], raw) {
+			return fmt.Errorf("%s: mailbox changed while scanning", m.ID)
+		}
+		keep = append(keep, data[pos:start]...)
+		pos = start + len(raw)
+		delete(s.offsets, m)
+		delete(s.raw, m)
+	}
+	keep = append(keep, data[pos:]...)
+
+	// rewrite in place; renaming a new file over the mbox would leave
+	// a delivery agent waiting on the flock appending to the old one.
+	if err := fp.Truncate(0); err != nil {
+		return err
+	} else if _, err := fp.WriteAt(keep, 0); err != nil {
+		return err
+	}
+	return fp.Sync()
+}
+
+// mbox_lock opens the mbox for update and locks it, first with a dot lock
+// (path.lock) and then with an flock. If the directory isn't writable, we
+// make do with the flock. A dot lock older than mbox_lock_stale is assumed
+// to have been left behind and is removed. The returned function closes
+// the file and releases the locks.
+func mbox_lock(path string) (*os.File, func(), error) {
+	fp, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dotlock := path + ".lock"
+	have_dotlock := false
+	for deadline := time.Now().Add(mbox_lock_wait); ; {
+		lf, err := os.OpenFile(dotlock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			_ = lf.Close()
+			have_dotlock = true
+			break
+		} else if errors.Is(err, os.ErrPermission) {
+			log.Printf("mbox: %s: %v: using flock only\n", path, err)
+			break
+		} else if !errors.Is(err, os.ErrExist) {
+			_ = fp.Close()
+			return nil, nil, err
+		}
+		if fi, err := os.Stat(dotlock); err == nil && time.Since(fi.ModTime()) > mbox_lock_stale {
+			log.Printf("mbox: %s: removing stale dot lock\n", path)
+			_ = os.Remove(dotlock)
+			continue
+		}
+		if !time.Now().Before(deadline) {
+			_ = fp.Close()
+			return nil, nil, fmt.Errorf("%s: %w", dotlock, ErrLocked)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	unlock := func() {
+		_ = funlock_file(fp)
+		_ = fp.Close()
+		if have_dotlock {
+			_ = os.Remove(dotlock)
+		}
+	}
+	if err := flock_file(fp); err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return fp, unlock, nil
+}
+
+// mbox_unquote removes the ">" that mbox adds to body lines starting with "From ".
+func mbox_unquote(data []byte) []byte {
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	for i := 1; i < len(lines); i++ {
+		if line := bytes.TrimLeft(lines[i], ">"); len(line) < len(lines[i]) && bytes.HasPrefix(line, []byte("From ")) {
+			lines[i] = lines[i][1:]
+		}
+	}
+	return bytes.Join(lines, nil)
+}
+
+// order_body returns the text of the orders from a message.
+// Orders may arrive quoted-printable or base64 encoded, or as the
+// text/plain part of a multipart message.
+func order_body(msg *mail.Message) ([]byte, error) {
+	return order_body_part(textproto.MIMEHeader(msg.Header), msg.Body)
+}
+
+func order_body_part(h textproto.MIMEHeader, r io.Reader) ([]byte, error) {
+	mediatype, params := "text/plain", map[string]string{}
+	if ctype := h.Get("Content-Type"); ctype != "" {
+		if mt, p, err := mime.ParseMediaType(ctype); err == nil {
+			mediatype, params = mt, p
+		}
+	}
+
+	if strings.HasPrefix(mediatype, "multipart/") {
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextRawPart()
+			if err == io.EOF {
+				return nil, fmt.Errorf("no text/plain part")
+			} else if err != nil {
+				return nil, err
+			}
+			if body, err := order_body_part(part.Header, part); err == nil {
+				return body, nil
+			}
+		}
+	} else if mediatype != "text/plain" {
+		return nil, fmt.Errorf("unsupported content type %q", mediatype)
+	}
+
+	switch strings.ToLower(h.Get("Content-Transfer-Encoding")) {
+	case "quoted-printable":
+		r = quotedprintable.NewReader(r)
+	case "base64":
+		r = base64.NewDecoder(base64.StdEncoding, r)
+	}
+	return io.ReadAll(r)
+}
+
+// archive_order_message saves a copy of the message in libdir/archive/<turn>.
+func archive_order_message(m *OrderMessage) error {
+	path := filepath.Join(libdir, "archive", fmt.Sprintf("%d", sysclock.turn))
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return fmt.Errorf("archive_order_message: %w", err)
+	}
+	archive_seq++
+	name := fmt.Sprintf("%s-%04d-%s.eml", time.Now().UTC().Format("20060102T150405"), archive_seq, m.Source)
+	if err := os.WriteFile(filepath.Join(path, name), m.Data, 0644); err != nil {
+		return fmt.Errorf("archive_order_message: %w", err)
+	}
+	return nil
+}
+
+// acknowledge renders the order scanner's log and, if mail_now is set, mails it.
+// The log starts with the mail headers written by eat_banner.
+// It returns the body of the acknowledgement.
+func acknowledge(fnam string, mail_now bool) ([]byte, error) {
+	text, err := report.RenderFile(fnam, report.Options{Format: report.TEXT, NoTab: pl != 0 && player_notab(pl)})
+	if err != nil {
+		return nil, fmt.Errorf("acknowledge: %w", err)
+	}
+	ack, err := mail.ReadMessage(bytes.NewReader(text))
+	if err != nil {
+		return nil, fmt.Errorf("acknowledge: %w", err)
+	}
+	body, err := io.ReadAll(ack.Body)
+	if err != nil {
+		return nil, fmt.Errorf("acknowledge: %w", err)
+	}
+	if !mail_now {
+		return body, nil
+	}
+
+	addresses := func(key string) []string {
+		var list []string
+		if al, err := ack.Header.AddressList(key); err == nil {
+			for _, a := range al {
+				list = append(list, a.String())
+			}
+		}
+		return list
+	}
+
+	msg := &mailer.Message{
+		From:    ack.Header.Get("From"),
+		ReplyTo: ack.Header.Get("Reply-To"),
+		To:      addresses("To"),
+		Cc:      addresses("Cc"),
+		Bcc:     addresses("Bcc"),
+		Subject: ack.Header.Get("Subject"),
+		Headers: map[string]string{},
+		Body:    body,
+	}
+	for key := range ack.Header {
+		switch key {
+		case "From", "Reply-To", "To", "Cc", "Bcc", "Subject":
+		default:
+			msg.Headers[key] = ack.Header.Get(key)
+		}
+	}
+	if len(msg.To) == 0 {
+		// nobody to send it to; the caller still gets the body
+		return body, nil
+	}
+
+	transport, err := mail_transport()
+	if err != nil {
+		return body, fmt.Errorf("acknowledge: %w", err)
+	} else if err := transport.Send(msg); err != nil {
+		return body, fmt.Errorf("acknowledge: %w", err)
+	}
+	return body, nil
+}
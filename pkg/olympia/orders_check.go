@@ -0,0 +1,261 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"fmt"
+	"os"
+)
+
+// OrderCheck is the machine-readable result of scanning a set of orders.
+// It carries the same information as the acknowledgement from eat_banner.
+type OrderCheck struct {
+	Player   string               `json:"player,omitempty"` // faction code from the BEGIN line
+	Queued   int                  `json:"queued"`
+	Errors   int                  `json:"errors"`
+	Warnings int                  `json:"warnings"`
+	Commands []*OrderCheckCommand `json:"commands,omitempty"` // commands that aren't for a unit, e.g. BEGIN
+	Units    []*OrderCheckUnit    `json:"units,omitempty"`
+}
+
+// OrderCheckUnit holds the commands for one unit.
+type OrderCheckUnit struct {
+	Unit     string               `json:"unit"`
+	Name     string               `json:"name,omitempty"`
+	Commands []*OrderCheckCommand `json:"commands,omitempty"`
+}
+
+// OrderCheckCommand is a single command and the problems found with it.
+// Command is empty if the line wasn't recognized.
+type OrderCheckCommand struct {
+	Line     int                 `json:"line"`
+	Text     string              `json:"text"`
+	Command  string              `json:"command,omitempty"`
+	Fuzzy    bool                `json:"fuzzy,omitempty"` // command name was a close match
+	Args     []OrderCheckArg     `json:"args,omitempty"`
+	Messages []OrderCheckMessage `json:"messages,omitempty"`
+}
+
+// OrderCheckArg is an argument and what the parser resolved it to.
+type OrderCheckArg struct {
+	Text  string `json:"text"`
+	Value int    `json:"value"`
+	Code  string `json:"code,omitempty"` // set if the value is an entity
+	Kind  string `json:"kind,omitempty"`
+	Name  string `json:"name,omitempty"` // only for entities the player may know about
+}
+
+// OrderCheckMessage is an error or warning from the checker.
+type OrderCheckMessage struct {
+	Line     int    `json:"line"`
+	Severity string `json:"severity"` // "error" or "warning"
+	Text     string `json:"text"`
+}
+
+var (
+	check_command    *OrderCheckCommand // command being scanned, nil between commands
+	eat_dry_run      = false            // check the orders without queueing them
+	last_order_check *OrderCheck        // results from the last call to eat
+	order_check      *OrderCheck        // results for the orders being scanned
+)
+
+// check_begin starts collecting results for a new set of orders.
+func check_begin() {
+	order_check, check_command = &OrderCheck{}, nil
+}
+
+// check_start_command records a command that was parsed by next_cmd.
+func check_start_command(c *command) {
+	if order_check == nil {
+		return
+	}
+	check_command = &OrderCheckCommand{
+		Line:    line_count,
+		Text:    c.line,
+		Command: cmd_tbl[c.cmd].name,
+		Fuzzy:   c.fuzzy,
+	}
+	args := []int{0, c.a, c.b, c.c, c.d, c.e, c.f, c.g, c.h}
+	for i := 1; i <= numargs(c); i++ {
+		arg := OrderCheckArg{Text: string(c.parse[i])}
+		if i < len(args) {
+			arg.Value = args[i]
+		}
+		if check_arg_is_entity(c, i, arg.Value) {
+			arg.Code, arg.Kind = box_code_less(arg.Value), kind_s[kind(arg.Value)]
+			if is_safe(pl, arg.Value) {
+				arg.Name = just_name(arg.Value)
+			}
+		}
+		check_command.Args = append(check_command.Args, arg)
+	}
+}
+
+// check_arg_is_entity returns true if the argument refers to an entity.
+// Numbers are quantities unless the command table says otherwise.
+func check_arg_is_entity(c *command, i, n int) bool {
+	if n <= 0 || !valid_box(n) {
+		return false
+	} else if i <= len(cmd_tbl[c.cmd].arg_types) {
+		switch cmd_tbl[c.cmd].arg_types[i-1] {
+		case CMD_unit, CMD_item, CMD_skill, CMD_use, CMD_practice:
+			return true
+		case CMD_days, CMD_qty, CMD_gold:
+			return false
+		}
+	}
+	return len(c.parse[i]) != 0 && !isdigit(c.parse[i][0])
+}
+
+// check_end_command files the current command under the current unit.
+// It is called after do_eat_command, so a UNIT command is filed under the unit it names.
+func check_end_command() {
+	if order_check == nil || check_command == nil {
+		return
+	}
+	check_file_command(check_command)
+	check_command = nil
+}
+
+// check_file_command adds the command to the current unit, or to the
+// top level if there isn't a valid unit.
+func check_file_command(cmd *OrderCheckCommand) {
+	if unit <= 0 {
+		order_check.Commands = append(order_check.Commands, cmd)
+		return
+	}
+	code := box_code_less(unit)
+	var u *OrderCheckUnit
+	if n := len(order_check.Units); n != 0 && order_check.Units[n-1].Unit == code {
+		u = order_check.Units[n-1]
+	} else {
+		u = &OrderCheckUnit{Unit: code}
+		if is_safe(pl, unit) {
+			u.Name = just_name(unit)
+		}
+		order_check.Units = append(order_check.Units, u)
+	}
+	u.Commands = append(u.Commands, cmd)
+}
+
+// check_message records an error or warning from err.
+// Problems found outside a command (e.g. an unrecognized line) are
+// recorded as a command with no name.
+func check_message(k int, s string) {
+	if order_check == nil {
+		return
+	}
+	msg := OrderCheckMessage{Line: line_count, Severity: "warning", Text: s}
+	if k == EAT_ERR {
+		msg.Severity = "error"
+		order_check.Errors++
+	} else {
+		order_check.Warnings++
+	}
+	if check_command != nil {
+		check_command.Messages = append(check_command.Messages, msg)
+		return
+	}
+	check_file_command(&OrderCheckCommand{Line: line_count, Text: string(save_line), Messages: []OrderCheckMessage{msg}})
+}
+
+// check_finish fills in the totals and returns the results.
+func check_finish() *OrderCheck {
+	oc := order_check
+	if oc == nil {
+		return nil
+	}
+	if pl != 0 {
+		oc.Player = box_code_less(pl)
+	}
+	oc.Queued = n_queued
+	order_check, check_command = nil, nil
+	return oc
+}
+
+// CheckOrders runs the order scanner against a file without queueing
+// anything or mailing an acknowledgement.
+// The file may be a mail message or just the text of the orders.
+// It returns the results and the text of the acknowledgement.
+//
+// Nothing in libdir is changed, so the checker doesn't hold the lock and
+// can run while a turn is in progress. The one exception is loading a text
+// database, which has no guard against reading it while a turn is saving
+// it; the lock is taken while that database is read. The acknowledgement
+// is written to a temporary directory instead of libdir/log.
+func CheckOrders(fnam string, options ...Option) (oc *OrderCheck, ack []byte, err error) {
+	defer recover_turn("CheckOrders", &err)
+
+	data, err := os.ReadFile(fnam)
+	if err != nil {
+		return nil, nil, fmt.Errorf("CheckOrders: %w", err)
+	}
+	for _, option := range options {
+		if err := option(); err != nil {
+			return nil, nil, fmt.Errorf("CheckOrders: %w", err)
+		}
+	}
+	if err := call_init_routines(); err != nil {
+		return nil, nil, fmt.Errorf("CheckOrders: %w", err)
+	} else if err := load_system(); err != nil {
+		return nil, nil, fmt.Errorf("CheckOrders: %w", err)
+	} else if err := check_load_db(); err != nil {
+		return nil, nil, fmt.Errorf("CheckOrders: %w", err)
+	}
+	immediate = FALSE
+
+	if log_dir, err = os.MkdirTemp("", "goly-check-"); err != nil {
+		return nil, nil, fmt.Errorf("CheckOrders: %w", err)
+	}
+	defer func(dir string) {
+		log_dir = ""
+		_ = os.RemoveAll(dir)
+	}(log_dir)
+
+	if _, _, _, err := parse_reply(data); err != nil {
+		// not a mail message, so give it an empty header
+		data = append([]byte("Subject: orders\r\n\r\n"), data...)
+	}
+
+	eat_dry_run = true
+	defer func() {
+		eat_dry_run = false
+	}()
+	m := &OrderMessage{Source: "file", ID: fnam, Data: data}
+	if ack, err = eat(m, false); err != nil {
+		return nil, nil, fmt.Errorf("CheckOrders: %w", err)
+	}
+
+	return last_order_check, ack, nil
+}
+
+// check_load_db loads the database for the checker.
+// A text database is read under the lock so that we never see a turn's
+// half-written save. The json loader refuses a half-swapped store on its own.
+func check_load_db() error {
+	if load_format() != DB_TEXT || lock_held {
+		return load_db()
+	}
+	if err := lock_tag(); err != nil {
+		return fmt.Errorf("can't read the text database while another process holds the lock (see --wait): %w", err)
+	}
+	defer unlock_tag()
+	return load_db()
+}
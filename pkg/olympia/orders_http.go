@@ -0,0 +1,252 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MAX_ORDER_POST is the largest set of orders we accept over HTTP.
+const MAX_ORDER_POST = 1 << 20
+
+var (
+	orders_http_addr string // address for the order endpoint, empty to disable
+	tls_cert_file    string // certificate and key for the web endpoints
+	tls_key_file     string
+	plain_http       bool // serve without TLS, behind a proxy that terminates it
+)
+
+// WithOrdersHTTP accepts orders posted to /orders on addr while eating orders.
+// Players authenticate with their faction code and password.
+func WithOrdersHTTP(addr string) func() error {
+	return func() error {
+		if addr == "" {
+			return fmt.Errorf("orders http: missing address")
+		}
+		orders_http_addr = addr
+		return nil
+	}
+}
+
+// WithTLS serves the web endpoints over HTTPS using the certificate and key files.
+func WithTLS(cert, key string) func() error {
+	return func() error {
+		if cert == "" || key == "" {
+			return fmt.Errorf("tls: need both a certificate and a key")
+		}
+		tls_cert_file, tls_key_file = cert, key
+		return nil
+	}
+}
+
+// WithPlainHTTP lets the web endpoints serve plain HTTP.
+// Passwords are sent with every request, so this is only safe behind
+// a reverse proxy that terminates TLS.
+func WithPlainHTTP(ok bool) func() error {
+	return func() error {
+		plain_http = ok
+		return nil
+	}
+}
+
+// check_http_security refuses to serve passwords in the clear
+// unless the operator said that a proxy takes care of TLS.
+func check_http_security() error {
+	if tls_cert_file == "" && !plain_http {
+		return fmt.Errorf("passwords would be sent in the clear: configure a TLS certificate, or allow plain HTTP behind a TLS proxy")
+	}
+	return nil
+}
+
+// serve_http serves on the listener, over TLS if it is configured.
+func serve_http(srv *http.Server, ln net.Listener) error {
+	if tls_cert_file != "" {
+		return srv.ServeTLS(ln, tls_cert_file, tls_key_file)
+	}
+	return srv.Serve(ln)
+}
+
+// http_scheme returns the scheme the web endpoints are served with.
+func http_scheme() string {
+	if tls_cert_file != "" {
+		return "https"
+	}
+	return "http"
+}
+
+// password_ok compares the password from a request with the faction's.
+// The comparison is exact and takes the same time wherever the two differ.
+func password_ok(want, got string) bool {
+	return want != "" && subtle.ConstantTimeCompare([]byte(want), []byte(got)) == 1
+}
+
+// order_request is a set of orders posted to the endpoint.
+// The engine isn't safe for concurrent use, so the handler passes
+// the request to the eat loop and waits for the reply.
+type order_request struct {
+	code     string
+	password string
+	data     []byte
+	reply    chan order_reply
+}
+
+type order_reply struct {
+	status int
+	ack    []byte
+	check  *OrderCheck
+}
+
+// start_orders_http starts the endpoint.
+// Requests are delivered on the returned channel.
+func start_orders_http(addr string) (*http.Server, chan *order_request, error) {
+	if err := check_http_security(); err != nil {
+		return nil, nil, fmt.Errorf("start_orders_http: %w", err)
+	}
+	requests := make(chan *order_request)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orders", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		code, password, ok := r.BasicAuth()
+		if !ok || code == "" || password == "" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="olympia"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MAX_ORDER_POST))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		req := &order_request{code: code, password: password, data: data, reply: make(chan order_reply, 1)}
+		select {
+		case requests <- req:
+		case <-r.Context().Done():
+			return
+		}
+		rep := <-req.reply
+		if rep.status == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", `Basic realm="olympia"`)
+		}
+		if wants_json(r) {
+			// clients get the checker results instead of the prose
+			var body interface{} = rep.check
+			if rep.check == nil {
+				body = map[string]string{"error": strings.TrimSpace(string(rep.ack))}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(rep.status)
+			_ = json.NewEncoder(w).Encode(body)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(rep.status)
+		_, _ = w.Write(rep.ack)
+	})
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("start_orders_http: %w", err)
+	}
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := serve_http(srv, ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("orders http: %v\n", err)
+		}
+	}()
+	log.Printf("orders http: accepting orders at %s://%s/orders\n", http_scheme(), ln.Addr())
+
+	return srv, requests, nil
+}
+
+// wants_json returns true if the client asked for the results as JSON.
+func wants_json(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+func stop_orders_http(srv *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("orders http: %v\n", err)
+	}
+}
+
+// eat_order_request authenticates the player and scans the orders.
+// The orders don't need a BEGIN line; we add one from the credentials.
+// The submission is always archived since there is no other copy of it.
+func eat_order_request(r *order_request, mail_now bool) order_reply {
+	who := code_to_int([]byte(r.code))
+	if who <= 0 || kind(who) != T_player {
+		return order_reply{status: http.StatusUnauthorized, ack: []byte("unknown faction or password\n")}
+	}
+	p := p_player(who)
+	if !password_ok(p.Password, r.password) {
+		return order_reply{status: http.StatusUnauthorized, ack: []byte("unknown faction or password\n")}
+	}
+
+	b := &bytes.Buffer{}
+	write_order_header(b, "http", box_code_less(who), p.EMail)
+	b.Write(r.data)
+
+	m := &OrderMessage{Source: "http", ID: fmt.Sprintf("http:%s", box_code_less(who)), Data: b.Bytes(), Player: who}
+	if err := archive_order_message(m); err != nil {
+		log.Printf("orders http: %v\n", err)
+		return order_reply{status: http.StatusInternalServerError, ack: []byte("unable to save orders\n")}
+	}
+	ack, err := eat(m, mail_now)
+	if err != nil {
+		log.Printf("orders http: %v\n", err)
+		return order_reply{status: http.StatusBadRequest, ack: []byte(err.Error() + "\n")}
+	}
+	return order_reply{status: http.StatusOK, ack: ack, check: last_order_check}
+}
+
+// write_order_header starts a mail message carrying orders that arrived
+// by some other route, so that eat can treat it like any other message.
+// The BEGIN line is added for the faction without a password; the caller
+// has already checked it and sets OrderMessage.Player so that eat accepts
+// the line. This keeps the password out of the archived orders.
+func write_order_header(b *bytes.Buffer, source, code, email string) {
+	if addresses := mail_addresses(email); len(addresses) != 0 {
+		_, _ = fmt.Fprintf(b, "From: %s\r\n", addresses[0])
+	}
+	_, _ = fmt.Fprintf(b, "Subject: orders for %s\r\n", code)
+	_, _ = fmt.Fprintf(b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	_, _ = fmt.Fprintf(b, "X-Olympia-Source: %s\r\n", source)
+	_, _ = fmt.Fprintf(b, "\r\n")
+	_, _ = fmt.Fprintf(b, "begin %s\n", code)
+}
@@ -0,0 +1,686 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/battle"
+	"github.com/mdhender/golympia/pkg/report"
+	"github.com/mdhender/golympia/pkg/times"
+	"github.com/mdhender/golympia/pkg/turnreport"
+	"html/template"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	portal_rules       string             // directory of rules pages served by the portal
+	portal_reload_poll = 10 * time.Second // how often to check for a newly saved database
+
+	// anyone can post a join request, so the portal limits how often an
+	// address may post and how many requests may wait for the next turn
+	portal_join_limit   = 5         // join posts per address per window
+	portal_join_window  = time.Hour // period for portal_join_limit
+	portal_join_pending = 100       // requests waiting in the queue
+)
+
+// MAX_JOIN_POST is the largest join request the portal accepts.
+const MAX_JOIN_POST = 4 << 10
+
+// WithPortalRules serves the files in path as the rules.
+func WithPortalRules(path string) func() error {
+	return func() error {
+		if path == "" {
+			return fmt.Errorf("portal: missing rules path")
+		} else if sb, err := os.Stat(path); err != nil {
+			return fmt.Errorf("portal: %w", err)
+		} else if !sb.IsDir() {
+			return fmt.Errorf("portal: %s: not a directory", path)
+		}
+		portal_rules = path
+		return nil
+	}
+}
+
+// portal_account is what the portal knows about a faction.
+// It is copied out of the database so that the handlers never
+// touch the engine's state.
+type portal_account struct {
+	pl       int
+	code     string
+	name     string
+	email    string
+	password string
+	notab    bool
+}
+
+// portal serves the reports, the Times, and the rules, and accepts orders.
+// The embedded lock guards the copies of the accounts. engine guards the
+// engine's state, which is only used to check join requests; engine_ok
+// is false if the last reload was abandoned part way through.
+type portal struct {
+	sync.RWMutex
+	accounts  map[string]*portal_account // keyed by faction code
+	game      int
+	turn      int
+	loaded    time.Time // when sysdata.json was saved
+	engine    sync.Mutex
+	engine_ok bool
+
+	join_mu    sync.Mutex
+	join_posts map[string][]time.Time // recent join posts by remote address
+}
+
+// Serve runs the web portal on addr.
+// Players sign in with their faction code and password to read their
+// turn reports and to upload orders. The Times, the rules, and the
+// schema for the JSON turn reports are public.
+// Uploaded orders are left in the spool for the next eat run.
+// New players post join requests, which are queued for the next turn.
+func Serve(addr string, options ...Option) error {
+	for _, option := range options {
+		if err := option(); err != nil {
+			return fmt.Errorf("Serve: %w", err)
+		}
+	}
+	if err := check_http_security(); err != nil {
+		return fmt.Errorf("Serve: %w", err)
+	} else if err := call_init_routines(); err != nil {
+		return fmt.Errorf("Serve: %w", err)
+	} else if err := mkdir(filepath.Join(libdir, "spool")); err != nil {
+		return fmt.Errorf("Serve: %w", err)
+	}
+
+	// wait for any turn that is running to finish before the first load
+	pt := &portal{}
+	for deadline := time.Now().Add(lock_wait); ; {
+		err := pt.reload()
+		if err == nil {
+			break
+		} else if !errors.Is(err, ErrLocked) || !time.Now().Before(deadline) {
+			return fmt.Errorf("Serve: %w", err)
+		}
+		time.Sleep(lock_poll)
+	}
+	go pt.reload_loop()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", pt.handle_index)
+	mux.HandleFunc("/reports/", pt.handle_report)
+	mux.HandleFunc("/schema/turn-report.json", pt.handle_schema)
+	mux.HandleFunc("/orders", pt.handle_orders)
+	mux.HandleFunc("/join", pt.handle_join)
+	mux.HandleFunc("/times", pt.handle_times)
+	mux.HandleFunc("/times/search", pt.handle_times_search)
+	mux.HandleFunc("/times/", pt.handle_times)
+	if portal_rules != "" {
+		mux.Handle("/rules/", http.StripPrefix("/rules/", http.FileServer(http.Dir(portal_rules))))
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("Serve: %w", err)
+	}
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	log.Printf("serve: game %d turn %d at %s://%s/\n", pt.game, pt.turn, http_scheme(), ln.Addr())
+	if err := serve_http(srv, ln); err != nil {
+		return fmt.Errorf("Serve: %w", err)
+	}
+	return nil
+}
+
+// reload_loop reloads the database in the background after a turn saves it,
+// so that requests never wait on a load.
+func (pt *portal) reload_loop() {
+	for range time.Tick(portal_reload_poll) {
+		if err := pt.reload(); err != nil && !errors.Is(err, ErrLocked) {
+			log.Printf("serve: %v\n", err)
+		}
+	}
+}
+
+// reload copies the accounts out of the database when it has been saved
+// since the last load. The portal never takes the lib-dir lock, so that
+// it can't get in the way of a turn. Instead, it doesn't load while
+// another command holds the lock, and it throws away a load that
+// overlapped a turn; the old accounts stay in use until the next try.
+func (pt *portal) reload() (err error) {
+	defer recover_turn("reload", &err)
+
+	if li, err := read_lock(); err != nil {
+		return fmt.Errorf("reload: %w", err)
+	} else if li != nil {
+		return fmt.Errorf("reload: %w: %s", ErrLocked, li)
+	}
+	sb, err := os.Stat(filepath.Join(libdir, "sysdata.json"))
+	if err != nil {
+		return fmt.Errorf("reload: %w", err)
+	}
+	pt.RLock()
+	current := pt.accounts != nil && sb.ModTime().Equal(pt.loaded)
+	pt.RUnlock()
+	if current {
+		return nil
+	}
+
+	pt.engine.Lock()
+	defer pt.engine.Unlock()
+	pt.engine_ok = false
+	clear_boxes()
+	if err := load_db(); err != nil {
+		return fmt.Errorf("reload: %w", err)
+	}
+	if li, _ := read_lock(); li != nil {
+		return fmt.Errorf("reload: %w: %s", ErrLocked, li)
+	} else if sa, err := os.Stat(filepath.Join(libdir, "sysdata.json")); err != nil || !sa.ModTime().Equal(sb.ModTime()) {
+		return fmt.Errorf("reload: %w: the database was saved while loading", ErrLocked)
+	}
+	pt.engine_ok = true
+
+	accounts := make(map[string]*portal_account)
+	for _, pl := range loop_player() {
+		p := rp_player(pl)
+		if p == nil {
+			continue
+		}
+		accounts[box_code_less(pl)] = &portal_account{
+			pl:       pl,
+			code:     box_code_less(pl),
+			name:     just_name(pl),
+			email:    p.EMail,
+			password: p.Password,
+			notab:    p.NoTab,
+		}
+	}
+	pt.Lock()
+	pt.accounts, pt.game, pt.turn, pt.loaded = accounts, game_number, sysclock.turn, sb.ModTime()
+	pt.Unlock()
+	log.Printf("serve: loaded %d factions for turn %d\n", len(accounts), sysclock.turn)
+
+	return nil
+}
+
+// authenticate checks the faction code and password from the request.
+// It writes the challenge and returns nil if they don't match.
+func (pt *portal) authenticate(w http.ResponseWriter, r *http.Request) *portal_account {
+	code, password, ok := r.BasicAuth()
+	pt.RLock()
+	a := pt.accounts[strings.ToLower(strings.TrimSpace(code))]
+	pt.RUnlock()
+	if !ok || a == nil || !password_ok(a.password, password) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="olympia"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return nil
+	}
+	return a
+}
+
+// same_origin reports whether the request came from one of the portal's
+// own pages or from a client that isn't a browser. A browser sends the
+// player's cached credentials with a form posted from any site, so a
+// post from another site must be refused.
+func same_origin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "same-origin", "none":
+		return true
+	case "":
+		// an older browser, or not a browser at all
+	default:
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
+
+// report_turns returns the turns with a saved report for the faction, newest first.
+func (pt *portal) report_turns(a *portal_account) []int {
+	files, err := os.ReadDir(filepath.Join(libdir, "save"))
+	if err != nil {
+		return nil
+	}
+	var turns []int
+	for _, f := range files {
+		turn, err := strconv.Atoi(f.Name())
+		if err != nil || !f.IsDir() {
+			continue
+		}
+		name := filepath.Join(libdir, "save", f.Name(), fmt.Sprintf("%d", a.pl))
+		if _, err := os.Stat(name); err == nil {
+			turns = append(turns, turn)
+		} else if _, err := os.Stat(name + ".gz"); err == nil {
+			turns = append(turns, turn)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(turns)))
+	return turns
+}
+
+// times_turns returns the turns in the archive of the Times, newest first.
+func (pt *portal) times_turns() []int {
+	turns, err := times_archive().Turns()
+	if err != nil {
+		log.Printf("serve: times: %v\n", err)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(turns)))
+	return turns
+}
+
+var portal_index = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><title>Olympia game {{.Game}}: {{.Name}} [{{.Code}}]</title></head>
+<body>
+<h1>{{.Name}} [{{.Code}}]</h1>
+<p>Olympia game {{.Game}}, turn {{.Turn}}.</p>
+<h2>Turn reports</h2>
+<ul>
+{{range .Reports}}<li><a href="/reports/{{.}}">Turn {{.}}</a> (<a href="/reports/{{.}}?format=text">text</a>, <a href="/reports/{{.}}?format=json">json</a>)</li>
+{{else}}<li>No reports yet.</li>
+{{end}}</ul>
+<h2>The Times</h2>
+<ul>
+<li><a href="/times">Latest issue</a></li>
+{{range .Times}}<li><a href="/times/{{.}}">Turn {{.}}</a> (<a href="/times/{{.}}?format=text">text</a>, <a href="/times/{{.}}?format=markdown">Markdown</a>)</li>
+{{end}}</ul>
+<form method="get" action="/times/search">
+<input type="text" name="q"> <input type="submit" value="Search back issues">
+</form>
+{{if .Rules}}<h2>Rules</h2>
+<p><a href="/rules/">Read the rules</a></p>
+{{end}}<h2>Orders</h2>
+<form method="post" action="/orders">
+<p>Orders are checked when they are eaten, and the acknowledgement is mailed to you.
+You don't need a BEGIN line.</p>
+<textarea name="orders" rows="24" cols="80"></textarea><br>
+<input type="submit" value="Send orders">
+</form>
+</body>
+</html>
+`))
+
+func (pt *portal) handle_index(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	a := pt.authenticate(w, r)
+	if a == nil {
+		return
+	}
+
+	pt.RLock()
+	game, turn := pt.game, pt.turn
+	pt.RUnlock()
+
+	data := struct {
+		Code, Name string
+		Game, Turn int
+		Reports    []int
+		Times      []int
+		Rules      bool
+	}{
+		Code:    a.code,
+		Name:    a.name,
+		Game:    game,
+		Turn:    turn,
+		Reports: pt.report_turns(a),
+		Times:   pt.times_turns(),
+		Rules:   portal_rules != "",
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := portal_index.Execute(w, data); err != nil {
+		log.Printf("serve: index: %v\n", err)
+	}
+}
+
+// handle_report renders /reports/<turn> as HTML, or as text with ?format=text.
+// ?format=json serves the JSON report instead; see /schema/turn-report.json.
+func (pt *portal) handle_report(w http.ResponseWriter, r *http.Request) {
+	a := pt.authenticate(w, r)
+	if a == nil {
+		return
+	}
+	turn, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/reports/"))
+	if err != nil || turn < 0 {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.URL.Query().Get("format") == "json" {
+		data, err := os.ReadFile(filepath.Join(libdir, "save", fmt.Sprintf("%d", turn), fmt.Sprintf("%d.json", a.pl)))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+		return
+	}
+
+	data, err := read_report_file(filepath.Join(libdir, "save", fmt.Sprintf("%d", turn), fmt.Sprintf("%d", a.pl)))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	rl, err := report.Parse(bytes.NewReader(data))
+	if err != nil {
+		log.Printf("serve: %s turn %d: %v\n", a.code, turn, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	pt.RLock()
+	game := pt.game
+	pt.RUnlock()
+
+	opts := report.Options{
+		Format: report.HTML,
+		NoTab:  a.notab,
+		Title:  fmt.Sprintf("Olympia:TAG game %d turn %d report for %s", game, turn, a.code),
+	}
+	contentType := "text/html; charset=utf-8"
+	if r.URL.Query().Get("format") == "text" {
+		opts.Format, contentType = report.TEXT, "text/plain; charset=utf-8"
+	}
+	if opts.Format == report.HTML {
+		if opts.Battles, err = battle.Read(filepath.Join(libdir, "save", fmt.Sprintf("%d", turn), fmt.Sprintf("%d.battles.json", a.pl))); err != nil {
+			log.Printf("serve: %s turn %d: %v\n", a.code, turn, err)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := report.Render(&b, rl, opts); err != nil {
+		log.Printf("serve: %s turn %d: %v\n", a.code, turn, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(b.Bytes())
+}
+
+// handle_schema serves the JSON Schema for the JSON turn reports.
+func (pt *portal) handle_schema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	_, _ = w.Write(turnreport.Schema)
+}
+
+// handle_times serves the latest issue from /times and older issues from /times/<turn>.
+// Issues are HTML unless ?format=text or ?format=markdown asks otherwise.
+func (pt *portal) handle_times(w http.ResponseWriter, r *http.Request) {
+	var is *times.Issue
+	var err error
+	if s := strings.TrimPrefix(r.URL.Path, "/times"); s != "" && s != "/" {
+		turn, err := strconv.Atoi(strings.TrimPrefix(s, "/"))
+		if err != nil || turn < 0 {
+			http.NotFound(w, r)
+			return
+		}
+		is, err = times_archive().Load(turn)
+	} else {
+		is, err = times_archive().Latest()
+	}
+	if err != nil || is == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	var b bytes.Buffer
+	contentType := "text/html; charset=utf-8"
+	switch r.URL.Query().Get("format") {
+	case "text":
+		contentType, err = "text/plain; charset=utf-8", is.Text(&b)
+	case "markdown":
+		contentType, err = "text/markdown; charset=utf-8", is.Markdown(&b)
+	default:
+		err = is.HTML(&b)
+	}
+	if err != nil {
+		log.Printf("serve: times %d: %v\n", is.Turn, err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(b.Bytes())
+}
+
+var portal_times_search = template.Must(template.New("search").Parse(`<!DOCTYPE html>
+<html>
+<head><title>The Gods Speak: {{.Query}}</title></head>
+<body>
+<h1>Searching the Times for &ldquo;{{.Query}}&rdquo;</h1>
+{{range .Matches}}<article>
+<h3><a href="/times/{{.Turn}}">Turn {{.Turn}}</a>{{if .Article.Title}}: {{.Article.Title}}{{end}}</h3>
+<pre>{{.Article.Body}}</pre>
+{{if .Article.Author}}<p>-- {{.Article.Author}}</p>
+{{end}}</article>
+{{else}}<p>Nothing found.</p>
+{{end}}</body>
+</html>
+`))
+
+// handle_times_search lists the articles in the back issues that match ?q=.
+func (pt *portal) handle_times_search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	matches, err := times_archive().Search(query)
+	if err != nil {
+		log.Printf("serve: times search: %v\n", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Query   string
+		Matches []times.Match
+	}{Query: query, Matches: matches}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := portal_times_search.Execute(w, data); err != nil {
+		log.Printf("serve: times search: %v\n", err)
+	}
+}
+
+// handle_orders accepts orders posted from the form or as a plain text body,
+// and leaves them in the spool as a mail message.
+// Posts from other sites are refused; see same_origin.
+func (pt *portal) handle_orders(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	} else if !same_origin(r) {
+		log.Printf("serve: orders: refused a cross-site post from %q\n", r.Header.Get("Origin"))
+		http.Error(w, "orders must be sent from the portal's own page", http.StatusForbidden)
+		return
+	}
+	a := pt.authenticate(w, r)
+	if a == nil {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, MAX_ORDER_POST)
+	var data []byte
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		data = []byte(strings.ReplaceAll(r.PostForm.Get("orders"), "\r\n", "\n"))
+	} else {
+		var err error
+		if data, err = io.ReadAll(r.Body); err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		http.Error(w, "no orders", http.StatusBadRequest)
+		return
+	}
+
+	b := &bytes.Buffer{}
+	write_order_header(b, "web", a.code, a.email)
+	b.Write(data)
+	if len(data) != 0 && data[len(data)-1] != '\n' {
+		b.WriteByte('\n')
+	}
+
+	if err := spool_message(fmt.Sprintf("w%d.%s", time.Now().UnixNano(), a.code), b.Bytes()); err != nil {
+		log.Printf("serve: %s: %v\n", a.code, err)
+		http.Error(w, "unable to save orders", http.StatusInternalServerError)
+		return
+	}
+	log.Printf("serve: spooled orders for %s\n", a.code)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = fmt.Fprintf(w, "Orders for %s were received.\nThe acknowledgement will be mailed to you when they are eaten.\n", a.code)
+}
+
+// spool_message writes the message into the spool.
+// It is written under a name that eat ignores and then renamed,
+// so that eat never reads a partial message.
+func spool_message(name string, data []byte) error {
+	dir := filepath.Join(libdir, "spool")
+	tmp := filepath.Join(dir, "."+name)
+	if err := os.WriteFile(tmp, data, 0666); err != nil {
+		return fmt.Errorf("spool_message: %w", err)
+	} else if err := os.Rename(tmp, filepath.Join(dir, name)); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("spool_message: %w", err)
+	}
+	return nil
+}
+
+// allow_join returns true if the address hasn't used up its join posts
+// for the window, and counts this post against it.
+func (pt *portal) allow_join(addr string, now time.Time) bool {
+	pt.join_mu.Lock()
+	defer pt.join_mu.Unlock()
+
+	if pt.join_posts == nil {
+		pt.join_posts = make(map[string][]time.Time)
+	}
+	recent := func(posts []time.Time) []time.Time {
+		for len(posts) != 0 && now.Sub(posts[0]) >= portal_join_window {
+			posts = posts[1:]
+		}
+		return posts
+	}
+	if len(pt.join_posts) > 1000 {
+		for k, posts := range pt.join_posts {
+			if len(recent(posts)) == 0 {
+				delete(pt.join_posts, k)
+			}
+		}
+	}
+
+	posts := recent(pt.join_posts[addr])
+	if len(posts) >= portal_join_limit {
+		pt.join_posts[addr] = posts
+		return false
+	}
+	pt.join_posts[addr] = append(posts, now)
+	return true
+}
+
+// handle_join checks a join request posted as JSON and queues it.
+// The reply is the JoinResult, which explains why a request was refused.
+// Posts are limited per remote address, and the queue is capped, since
+// anyone can post without signing in.
+func (pt *portal) handle_join(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	addr, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		addr = r.RemoteAddr
+	}
+	if !pt.allow_join(addr, time.Now()) {
+		log.Printf("serve: join: too many requests from %s\n", addr)
+		w.Header().Set("Retry-After", strconv.Itoa(int(portal_join_window.Seconds())))
+		http.Error(w, "too many join requests; try again later", http.StatusTooManyRequests)
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MAX_JOIN_POST))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	joins, err := ParseJoinRequests(data)
+	if err != nil || len(joins) != 1 {
+		if err == nil {
+			err = fmt.Errorf("expected one join request")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(JoinResult{Error: err.Error()})
+		return
+	}
+
+	// checking the request reads the engine's state
+	pt.engine.Lock()
+	if !pt.engine_ok {
+		pt.engine.Unlock()
+		http.Error(w, "the game is being updated; try again in a minute", http.StatusServiceUnavailable)
+		return
+	}
+	if names, _, err := pending_joins(); err == nil && len(names) >= portal_join_pending {
+		pt.engine.Unlock()
+		log.Printf("serve: join: %d requests are already queued\n", len(names))
+		http.Error(w, "too many join requests are waiting; try again after the next turn", http.StatusServiceUnavailable)
+		return
+	}
+	results, err := queue_joins(joins, false)
+	pt.engine.Unlock()
+	if err != nil || len(results) != 1 {
+		log.Printf("serve: join: %v\n", err)
+		http.Error(w, "unable to save the join request", http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusCreated
+	if results[0].Error != "" {
+		status = http.StatusBadRequest
+	} else {
+		log.Printf("serve: queued join %s\n", results[0].Name)
+	}
+	results[0].Request.Password = "" // don't echo it back
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(results[0])
+}
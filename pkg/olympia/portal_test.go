@@ -0,0 +1,45 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"testing"
+	"time"
+)
+
+// TestAllowJoin limits each address to portal_join_limit posts a window.
+func TestAllowJoin(t *testing.T) {
+	pt := &portal{}
+	now := time.Now()
+	for i := 0; i < portal_join_limit; i++ {
+		if !pt.allow_join("192.0.2.1", now) {
+			t.Fatalf("post %d: refused", i+1)
+		}
+	}
+	if pt.allow_join("192.0.2.1", now) {
+		t.Errorf("post %d: want refused", portal_join_limit+1)
+	}
+	if !pt.allow_join("192.0.2.2", now) {
+		t.Errorf("other address: refused")
+	}
+	if !pt.allow_join("192.0.2.1", now.Add(portal_join_window)) {
+		t.Errorf("next window: refused")
+	}
+}
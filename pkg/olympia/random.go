@@ -20,13 +20,60 @@
 package olympia
 
 import (
+	"fmt"
 	"github.com/mdhender/golympia/pkg/prng"
+	"log"
 )
 
-func load_seed(name string) {
-	prng.LoadSeed(name)
+var (
+	// rng is the source of all randomness in the engine.
+	rng prng.Source = prng.Default()
+	// rng_injected is true when the caller supplied the source,
+	// in which case the seed file in libdir is not loaded.
+	rng_injected = false
+)
+
+// WithRandomSource makes the engine draw its randomness from the given source.
+func WithRandomSource(src prng.Source) func() error {
+	return func() error {
+		if src == nil {
+			return fmt.Errorf("random source: missing source")
+		}
+		rng, rng_injected = src, true
+		return nil
+	}
+}
+
+// load_seed restores the PRNG from the named file.
+func load_seed(name string) error {
+	if rng_injected {
+		return nil
+	}
+	src, err := prng.Load(name)
+	if err != nil {
+		return fmt.Errorf("load_seed: %w", err)
+	}
+	rng = src
+	return nil
+}
+
+// save_seed saves the state of the PRNG to the named file.
+func save_seed(name string) error {
+	src, ok := rng.(prng.Stateful)
+	if !ok {
+		log.Printf("save_seed: random source can't be saved\n")
+		return nil
+	} else if err := prng.Save(name, src); err != nil {
+		return fmt.Errorf("save_seed: %w", err)
+	}
+	return nil
 }
 
 func rnd(low, high int) int {
-	return prng.Range(low, high)
+	return prng.Range(rng, low, high)
+}
+
+// shuffle pseudo-randomizes the order of n elements.
+func shuffle(n int, swap func(i, j int)) {
+	prng.Shuffle(rng, n, swap)
 }
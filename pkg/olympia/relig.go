@@ -159,17 +159,20 @@ func add_piety(who, amount int, use_limit bool) {
 	 *
 	 */
 	if use_limit &&
-		options.piety_limit != 0 &&
-		options.head_priest_piety_limit != 0 {
+		game_rules.Piety.Limit != 0 &&
+		game_rules.Piety.HeadPriestLimit != 0 {
 		/*
 		 *  Is he the high priest?
 		 *
 		 */
 		var limit int
 		if rp_relig_skill(religion).high_priest == who {
-			limit = options.head_priest_piety_limit * len(rp_char(who).religion.followers)
+			limit = game_rules.Piety.HeadPriestLimit * len(rp_char(who).religion.followers)
 		} else {
-			limit = options.piety_limit * len(rp_char(who).religion.followers)
+			limit = game_rules.Piety.Limit * len(rp_char(who).religion.followers)
+		}
+		if limit < game_rules.Piety.Min {
+			limit = game_rules.Piety.Min
 		}
 
 		if rp_char(who).religion.piety >= limit {
@@ -498,7 +501,7 @@ func d_last_rites(c *command) int {
 	 *  Piety bonus for LRing magicians.
 	 *
 	 */
-	if options.mp_antipathy {
+	if game_rules.MPAntipathy {
 		if is_magician(body) {
 			assert(is_priest(c.who) != FALSE)
 			wout(c.who, "%s rewards you with extra piety for destroying a magician.", god_name(is_priest(c.who)))
@@ -714,8 +717,8 @@ func d_create_holy_symbol(c *command) int {
 	p := p_item(newt)
 	p.weight = rnd(1, 3)
 
+	set_item_creator(newt, c.who)
 	pm := p_item_magic(newt)
-	pm.Creator = c.who
 	pm.Religion = is_priest(c.who)
 
 	wout(c.who, "%s created.", box_name(newt))
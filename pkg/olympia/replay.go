@@ -0,0 +1,212 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// TurnDigest records a checksum for every file the turn wrote to the database
+// and the reports, keyed by the path relative to libdir.
+type TurnDigest struct {
+	Turn  int               `json:"turn"`
+	Files map[string]string `json:"files"`
+}
+
+// ReplayTurn re-runs a turn from its snapshot in a temporary directory and compares
+// the database and reports against the digest recorded when the turn was first run.
+// It returns one line for each file that diverged. The library is not changed.
+func ReplayTurn(turn int, options ...Option) (diffs []string, err error) {
+	defer recover_turn("ReplayTurn", &err)
+	defer unlock_tag()
+
+	for _, option := range options {
+		if err := option(); err != nil {
+			return nil, fmt.Errorf("ReplayTurn: %w", err)
+		}
+	}
+	if err := lock_tag(); err != nil {
+		return nil, fmt.Errorf("ReplayTurn: %w", err)
+	}
+
+	data, err := read_snapshot(turn)
+	if err != nil {
+		return nil, fmt.Errorf("ReplayTurn: %w", err)
+	}
+	want, err := read_turn_digest(turn)
+	if err != nil {
+		return nil, fmt.Errorf("ReplayTurn: %w", err)
+	}
+
+	tmpdir, err := os.MkdirTemp("", "goly-replay-")
+	if err != nil {
+		return nil, fmt.Errorf("ReplayTurn: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpdir)
+	}()
+	savedLibdir := libdir
+	libdir = tmpdir
+	defer func() {
+		libdir = savedLibdir
+	}()
+
+	if err := restore_snapshot(data); err != nil {
+		return nil, fmt.Errorf("ReplayTurn: %w", err)
+	} else if err := start_turn(); err != nil {
+		return nil, fmt.Errorf("ReplayTurn: %w", err)
+	} else if sysclock.turn+1 != turn {
+		return nil, fmt.Errorf("ReplayTurn: snapshot %d holds turn %d", turn, sysclock.turn)
+	}
+	immediate = FALSE
+	save_flag, mail_now = true, false
+
+	run_turn()
+
+	if err := finish_turn(true); err != nil {
+		return nil, fmt.Errorf("ReplayTurn: %w", err)
+	}
+
+	got, err := turn_digest(turn)
+	if err != nil {
+		return nil, fmt.Errorf("ReplayTurn: %w", err)
+	}
+
+	return diff_turn_digests(want, got), nil
+}
+
+func turn_digest_name(turn int) string {
+	return filepath.Join(libdir, SNAPSHOT_DIR, fmt.Sprintf("%d.digest.json", turn))
+}
+
+// turn_digest returns the checksums for the database and the reports
+// after the turn has been saved.
+func turn_digest(turn int) (*TurnDigest, error) {
+	td := &TurnDigest{Turn: turn, Files: make(map[string]string)}
+	paths := append([]string{}, snapshot_paths...)
+	paths = append(paths, filepath.Join("save", fmt.Sprintf("%d", turn)))
+	for _, path := range paths {
+		root := filepath.Join(libdir, path)
+		if _, err := os.Stat(root); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		err := filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			} else if d.IsDir() {
+				return nil
+			}
+			rel, err := filepath.Rel(libdir, name)
+			if err != nil {
+				return err
+			}
+			data, err := os.ReadFile(name)
+			if err != nil {
+				return err
+			}
+			if rel == "sysdata.json" {
+				// the time stamp changes every time the file is saved
+				if data, err = without_updated_at(data); err != nil {
+					return fmt.Errorf("%s: %w", rel, err)
+				}
+			}
+			sum := sha256.Sum256(data)
+			td.Files[filepath.ToSlash(rel)] = hex.EncodeToString(sum[:])
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("turn_digest: %w", err)
+		}
+	}
+	return td, nil
+}
+
+func without_updated_at(data []byte) ([]byte, error) {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	delete(m, "updated-at")
+	return json.Marshal(m)
+}
+
+// record_turn_digest saves the digest for a turn next to its snapshot.
+func record_turn_digest(turn int) error {
+	td, err := turn_digest(turn)
+	if err != nil {
+		return fmt.Errorf("record_turn_digest: %w", err)
+	}
+	data, err := json.MarshalIndent(td, "", "  ")
+	if err != nil {
+		return fmt.Errorf("record_turn_digest: %w", err)
+	} else if err := os.WriteFile(turn_digest_name(turn), data, 0666); err != nil {
+		return fmt.Errorf("record_turn_digest: %w", err)
+	}
+	log.Printf("record_turn_digest: %s\n", turn_digest_name(turn))
+	return nil
+}
+
+func read_turn_digest(turn int) (*TurnDigest, error) {
+	data, err := os.ReadFile(turn_digest_name(turn))
+	if err != nil {
+		return nil, fmt.Errorf("read_turn_digest: %w", err)
+	}
+	td := &TurnDigest{}
+	if err := json.Unmarshal(data, td); err != nil {
+		return nil, fmt.Errorf("read_turn_digest: %s: %w", turn_digest_name(turn), err)
+	}
+	return td, nil
+}
+
+func diff_turn_digests(want, got *TurnDigest) []string {
+	var names []string
+	for name := range want.Files {
+		names = append(names, name)
+	}
+	for name := range got.Files {
+		if _, ok := want.Files[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var diffs []string
+	for _, name := range names {
+		w, wok := want.Files[name]
+		g, gok := got.Files[name]
+		if !gok {
+			diffs = append(diffs, fmt.Sprintf("%s: missing from replay", name))
+		} else if !wok {
+			diffs = append(diffs, fmt.Sprintf("%s: only in replay", name))
+		} else if w != g {
+			diffs = append(diffs, fmt.Sprintf("%s: diverged", name))
+		}
+	}
+	return diffs
+}
@@ -22,7 +22,6 @@ package olympia
 import (
 	"fmt"
 	"log"
-	"path/filepath"
 	"sort"
 	"strings"
 )
@@ -1827,37 +1826,30 @@ func player_banner() {
 	out_alt_who = 0
 }
 
+// report_account_out shows the balance and the most recent entries
+// from the player's ledger.
 func report_account_out(pl, who int) int {
-	var fnam string
-	var cmd string
-
-	fnam = filepath.Join("/", "tmp", "oly-acct.tmp")
-
-	cmd = fmt.Sprintf("%s -a %s -A %s-old -g tag%d -p %s -s 4 > %s",
-		options.accounting_prog,
-		options.accounting_dir,
-		options.accounting_dir,
-		game_number,
-		box_code_less(pl), fnam)
-	system(cmd)
-
-	fp, err := fopen(fnam, "r")
-	if err != nil {
-		out(who, "<account summary not available>")
+	a := player_account(pl)
+
+	first := 0
+	if len(a.Entries) > 5 {
+		first = len(a.Entries) - 5
+	}
+	for _, e := range a.Entries[first:] {
+		out(who, "Turn %3d  %9s  %s", e.Turn, money(e.Amount), e.Memo)
+	}
+	if len(a.Entries) != 0 {
 		out(who, "")
-		log.Printf("can't open %s: ", fnam)
-		unlink(fnam)
-		return 0
 	}
+	out(who, "Balance:  %s", money(a.Balance))
+	out(who, "Charge for next turn:  %s", money(turn_charge(pl)))
 
-	for line := getlin(fp); line != nil; line = getlin(fp) {
-		out(who, "%s", string(line))
+	if a.Balance < 0 && options.auto_drop {
+		out(who, "")
+		wout(who, "Your balance is negative.  You will be dropped from the game next turn unless your account is credited.")
 	}
 
-	fclose(fp)
-	unlink(fnam)
 	return 1
-
 }
 
 func report_account_sup(pl int) {
@@ -1881,7 +1873,6 @@ func report_account() {
 	var pl int
 
 	stage("report_account()")
-	close_logfile()
 
 	out_path = MASTER
 	out_alt_who = OUT_BANNER
@@ -1898,39 +1889,27 @@ func report_account() {
 	out_alt_who = 0
 }
 
+// charge_account posts the turn charge to each player's account.
 func charge_account() {
-	var pl int
-	var p *EntityPlayer
-	var cmd string
-	//var val_s string
-
 	stage("charge_account()")
 
 	out_path = MASTER
 	out_alt_who = OUT_BANNER
 
-	for _, pl = range loop_player() {
+	for _, pl := range loop_player() {
 		if subkind(pl) != sub_pl_regular {
 			continue
 		}
 
-		p = rp_player(pl)
-		if p == nil {
+		charge := turn_charge(pl)
+		if charge == 0 {
 			continue
 		}
 
-		cmd = fmt.Sprintf("%s -g tag%d -a %s -A %s-old -p %s -t%s -y \"Olympia TAG turn %d\"",
-			options.accounting_prog,
-			game_number,
-			options.accounting_dir,
-			options.accounting_dir,
-			box_code_less(pl),
-			turn_charge(pl),
-			sysclock.turn)
-
-		if system(cmd) != FALSE {
-			out(gm_player, "Could not charge %s.",
-				box_code(pl))
+		a := player_account(pl)
+		a.post(-charge, fmt.Sprintf("Olympia TAG turn %d", sysclock.turn))
+		if a.Balance < 0 {
+			out(gm_player, "%s has a balance of %s.", box_code(pl), money(a.Balance))
 		}
 	}
 
@@ -0,0 +1,318 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+/*
+ *  A rules profile collects the balance settings for a game: the
+ *  switches that used to live only in sysdata.json and values that
+ *  were compiled into the engine (maintenance, taxes, starting gold
+ *  and noble points, market prices).
+ *
+ *  The profile starts from sysdata.json and the engine defaults.  A
+ *  rules.json in the lib directory overrides any of the values it
+ *  names, so a variant game only needs to list what it changes.  The
+ *  profile is never written back; sysdata.json keeps its own values.
+ */
+
+const RULES_FILE = "rules.json"
+
+// game_rules is the profile the engine plays by.
+var game_rules = default_rules()
+
+// Rules is the json version of a rules profile.
+// FullMarkets makes city markets buy the common goods: wood, stone, wild
+// horses, and the rare herbs and woods in the big cities.  It is on unless
+// sysdata.json or the profile turns it off.
+type Rules struct {
+	Name          string           `json:"name,omitempty"`
+	Description   string           `json:"description,omitempty"`
+	FullMarkets   bool             `json:"full-markets"`
+	GuildTeaching bool             `json:"guild-teaching"`
+	MPAntipathy   bool             `json:"mp-antipathy"`
+	SurviveNP     bool             `json:"survive-np"`
+	FreeNPLimit   int              `json:"free-np-limit"` // nobles with this many NPs or fewer are free to keep
+	TimesPay      int              `json:"times-pay"`     // gold paid for an article in the Times
+	Piety         RulesPiety       `json:"piety"`
+	Maintenance   RulesMaintenance `json:"maintenance"`
+	Taxes         RulesTaxes       `json:"taxes"`
+	Start         RulesStart       `json:"start"`
+	Markets       RulesMarkets     `json:"markets"`
+}
+
+// RulesPiety is the piety granted to priests each month and the caps
+// on how much they may hold. A limit of zero means no cap.
+type RulesPiety struct {
+	Top             int `json:"top"`               // the priest with the most followers
+	Middle          int `json:"middle"`            // the next ones
+	Bottom          int `json:"bottom"`            // everyone else
+	Min             int `json:"min"`               // any priest may hold this much, whatever the limits
+	Limit           int `json:"limit"`             // per follower
+	HeadPriestLimit int `json:"head-priest-limit"` // per follower, for the head priest
+}
+
+// RulesMaintenance scales the upkeep of men and beasts.
+// Percent applies to every item; Items replaces the upkeep of single
+// items, by item number, before scaling.
+type RulesMaintenance struct {
+	Percent int         `json:"percent"`
+	Items   map[int]int `json:"items,omitempty"`
+}
+
+// RulesTaxes controls province taxes.
+type RulesTaxes struct {
+	MaxRate         int `json:"max-rate"`          // highest rate a ruler may set, in percent
+	MinPeasants     int `json:"min-peasants"`      // peasants a province needs before it pays
+	MinPeasantsCity int `json:"min-peasants-city"` // the same, for a province with a city
+	ImprovePercent  int `json:"improve-percent"`   // added by Improve Taxes
+}
+
+// RulesStart is what a new faction begins with. When Balance is set,
+// factions joining weak nations get more and strong nations get less.
+type RulesStart struct {
+	Gold        int  `json:"gold"`
+	NoblePoints int  `json:"noble-points"`
+	Balance     bool `json:"balance"`
+}
+
+// RulesMarkets controls city market prices. Each month a price moves
+// by a random MinChange to MaxChange percent, and is kept between
+// MinPrice and MaxPrice percent of the item's base price. Items with
+// no base price are kept between 1 and PriceCap. Trade goods that
+// don't sell for Age months leave the market.
+type RulesMarkets struct {
+	Age       int `json:"age"`
+	MinChange int `json:"min-change"`
+	MaxChange int `json:"max-change"`
+	MinPrice  int `json:"min-price"`
+	MaxPrice  int `json:"max-price"`
+	PriceCap  int `json:"price-cap"`
+}
+
+// default_rules returns the profile from sysdata.json and the engine defaults.
+func default_rules() Rules {
+	return Rules{
+		Name:          "default",
+		FullMarkets:   options.full_markets,
+		GuildTeaching: options.guild_teaching,
+		MPAntipathy:   options.mp_antipathy,
+		SurviveNP:     options.survive_np,
+		FreeNPLimit:   options.free_np_limit,
+		TimesPay:      options.times_pay,
+		Piety: RulesPiety{
+			Top:             options.top_piety,
+			Middle:          options.middle_piety,
+			Bottom:          options.bottom_piety,
+			Min:             options.min_piety,
+			Limit:           options.piety_limit,
+			HeadPriestLimit: options.head_priest_piety_limit,
+		},
+		Maintenance: RulesMaintenance{Percent: 100},
+		Taxes: RulesTaxes{
+			MaxRate:         10,
+			MinPeasants:     100,
+			MinPeasantsCity: 1000,
+			ImprovePercent:  100,
+		},
+		Start: RulesStart{
+			Gold:        5000,
+			NoblePoints: 12,
+			Balance:     true,
+		},
+		Markets: RulesMarkets{
+			Age:       options.market_age,
+			MinChange: 10,
+			MaxChange: 20,
+			MinPrice:  50,
+			MaxPrice:  200,
+			PriceCap:  50,
+		},
+	}
+}
+
+// parse_rules returns the profile with the values in data laid over it.
+func parse_rules(data []byte, r Rules) (Rules, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&r); err != nil {
+		return r, err
+	}
+	return r, r.check()
+}
+
+// check returns an error if a value is out of range.
+func (r Rules) check() error {
+	if r.FreeNPLimit < 0 {
+		return fmt.Errorf("free-np-limit: must not be negative")
+	} else if r.TimesPay < 0 {
+		return fmt.Errorf("times-pay: must not be negative")
+	}
+
+	p := r.Piety
+	if p.Bottom < 0 || p.Min < 0 || p.Limit < 0 || p.HeadPriestLimit < 0 {
+		return fmt.Errorf("piety: values must not be negative")
+	} else if !(p.Top >= p.Middle && p.Middle >= p.Bottom) {
+		return fmt.Errorf("piety: top %d, middle %d, bottom %d: must not increase", p.Top, p.Middle, p.Bottom)
+	}
+
+	if r.Maintenance.Percent < 0 {
+		return fmt.Errorf("maintenance: percent: must not be negative")
+	}
+	for item, cost := range r.Maintenance.Items {
+		if cost < 0 {
+			return fmt.Errorf("maintenance: item %d: must not be negative", item)
+		}
+	}
+
+	t := r.Taxes
+	if t.MaxRate < 0 || t.MaxRate > 100 {
+		return fmt.Errorf("taxes: max-rate %d: must be from 0 to 100", t.MaxRate)
+	} else if t.MinPeasants < 0 || t.MinPeasantsCity < 0 || t.ImprovePercent < 0 {
+		return fmt.Errorf("taxes: values must not be negative")
+	}
+
+	if r.Start.Gold < 0 || r.Start.NoblePoints < 0 {
+		return fmt.Errorf("start: values must not be negative")
+	}
+
+	m := r.Markets
+	if m.Age < 0 {
+		return fmt.Errorf("markets: age: must not be negative")
+	} else if m.MinChange < 0 || m.MinChange > m.MaxChange {
+		return fmt.Errorf("markets: min-change %d, max-change %d: need 0 <= min <= max", m.MinChange, m.MaxChange)
+	} else if m.MinPrice < 0 || m.MinPrice > 100 {
+		return fmt.Errorf("markets: min-price %d: must be from 0 to 100", m.MinPrice)
+	} else if m.MaxPrice < 100 {
+		return fmt.Errorf("markets: max-price %d: must be at least 100", m.MaxPrice)
+	} else if m.PriceCap < 1 {
+		return fmt.Errorf("markets: price-cap %d: must be at least 1", m.PriceCap)
+	}
+
+	return nil
+}
+
+// read_rules returns the profile for the lib directory.
+func read_rules(dir string) (Rules, error) {
+	r := default_rules()
+	if dir == "" {
+		return r, nil
+	}
+	name := filepath.Join(dir, RULES_FILE)
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	} else if err != nil {
+		return r, err
+	}
+	if r, err = parse_rules(data, r); err != nil {
+		return r, fmt.Errorf("%s: %w", name, err)
+	}
+	return r, nil
+}
+
+// load_rules sets the profile for the game.
+// It runs after the boxes are loaded so that item overrides can be checked.
+func load_rules() error {
+	r, err := read_rules(libdir)
+	if err != nil {
+		return err
+	}
+	for item := range r.Maintenance.Items {
+		if kind(item) != T_item {
+			return fmt.Errorf("%s: maintenance: %d is not an item", RULES_FILE, item)
+		}
+	}
+	game_rules = r
+	return nil
+}
+
+// RulesProfile returns the rules profile for the game: sysdata.json and
+// the engine defaults, with the lib directory's rules.json laid over them.
+// The profile is checked as it would be when the game loads.
+func RulesProfile(options ...Option) (*Rules, error) {
+	for _, option := range options {
+		if err := option(); err != nil {
+			return nil, fmt.Errorf("RulesProfile: %w", err)
+		}
+	}
+	if libdir != "" {
+		if _, err := os.Stat(filepath.Join(libdir, "sysdata.json")); err == nil {
+			if err := load_system(); err != nil {
+				return nil, fmt.Errorf("RulesProfile: %w", err)
+			}
+		}
+	}
+	r, err := read_rules(libdir)
+	if err != nil {
+		return nil, fmt.Errorf("RulesProfile: %w", err)
+	}
+	return &r, nil
+}
+
+// gm_show_rules echoes the profile in the GM report.
+func gm_show_rules(pl int) {
+	out_path = MASTER
+	out_alt_who = OUT_LORE
+
+	r := game_rules
+	out(pl, "")
+	out(pl, "Rules profile: %s", r.Name)
+	out(pl, "--------------")
+	out(pl, "")
+	if r.Description != "" {
+		out(pl, "%s", r.Description)
+		out(pl, "")
+	}
+	out(pl, "Full markets:         %v", r.FullMarkets)
+	out(pl, "Guild teaching:       %v", r.GuildTeaching)
+	out(pl, "MP antipathy:         %v", r.MPAntipathy)
+	out(pl, "Survive NP:           %v", r.SurviveNP)
+	out(pl, "Free NP limit:        %d", r.FreeNPLimit)
+	out(pl, "Times pay:            %s", gold_s(r.TimesPay))
+	out(pl, "Piety:                top %d, middle %d, bottom %d, min %d",
+		r.Piety.Top, r.Piety.Middle, r.Piety.Bottom, r.Piety.Min)
+	out(pl, "Piety limits:         %d per follower, head priest %d",
+		r.Piety.Limit, r.Piety.HeadPriestLimit)
+	out(pl, "Maintenance:          %d%%", r.Maintenance.Percent)
+	var items []int
+	for item := range r.Maintenance.Items {
+		items = append(items, item)
+	}
+	sort.Ints(items)
+	for _, item := range items {
+		out(pl, "    %-40s %s", box_name(item), gold_s(r.Maintenance.Items[item]))
+	}
+	out(pl, "Taxes:                up to %d%%, %s peasants (%s with a city), improve +%d%%",
+		r.Taxes.MaxRate, comma_num(r.Taxes.MinPeasants), comma_num(r.Taxes.MinPeasantsCity), r.Taxes.ImprovePercent)
+	out(pl, "Start:                %s, %d NPs, balanced %v",
+		gold_s(r.Start.Gold), r.Start.NoblePoints, r.Start.Balance)
+	out(pl, "Markets:              change %d-%d%%, price %d-%d%% of base, cap %s, age %d",
+		r.Markets.MinChange, r.Markets.MaxChange, r.Markets.MinPrice, r.Markets.MaxPrice,
+		gold_s(r.Markets.PriceCap), r.Markets.Age)
+}
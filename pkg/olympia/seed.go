@@ -1221,105 +1221,62 @@ func seed_taxes() {
 	}
 }
 
-//#if 0
-//static void
-//init_gate_dests()
-//{
-//    var i int
-//    var where int
-//    var dest int
-//    var l []*exit_view
-//    int j;
-//
-//    loop_gate(i)
-//    {
-//        where = subloc(i);
-//        dest = gate_dest(i);
-//
-//        if (in_faery(where) || in_faery(dest))
-//            continue;
-//
-//        ilist_append(&p_misc(where).gate_dest, dest);
-//        ilist_append(&p_misc(dest).gate_dest, where);
-//    }
-//    next_gate;
-//
-//    for _, i = range loop_loc(i)
-//    {
-//        if (loc_depth(i) == LOC_region)
-//            continue;
-//
-//        l = exits_from_loc(0, i);
-//
-//        for (j = 0; j < len(l); j++)
-//            ilist_append(&p_misc(i).prov_dest, l[j].destination);
-//    }
-//    next_loc(i);
-//}
-//
-//
-//int
-//distance(int orig, int dest, int gate)
-//{
-//    var i, j int
-//    var where int
-//    flag := TRUE;
-//    static int gate_init = FALSE;
-//    struct entity_misc *p;
-//
-//    if (!gate_init)
-//    {
-//        init_gate_dests();
-//        gate_init = TRUE;
-//    }
-//
-//    clear_temps(T_loc);
-//
-//    bx[orig].temp = 1;
-//
-//    while (bx[dest].temp == 0 && flag)
-//    {
-//        flag = FALSE;
-//
-//        for _, where = for _, i = loop_loc()
-//        {
-//        if (bx[where].temp > 0)
-//        {
-//            p = rp_misc(where);
-//
-//            for i = 0; i < len(p.prov_dest); i++
-//            {
-//            j = p.prov_dest[i];
-//            if (bx[j].temp == 0)
-//            {
-//                bx[j].temp = bx[where].temp + 1;
-//                flag = TRUE;
-//            }
-//            }
-//
-//            if (gate)
-//            {
-//            for i = 0; i < len(p.gate_dest); i++
-//            {
-//                j = p.gate_dest[i];
-//                if (bx[j].temp == 0)
-//                {
-//                bx[j].temp = bx[where].temp + 1;
-//                flag = TRUE;
-//                }
-//            }
-//            }
-//
-//            if (where != dest)
-//            bx[where].temp = -1;
-//        }
-//        }
-//
-//    }
-//
-//    return bx[dest].temp;
-//}
-//#endif
+/*
+ *  distance returns the number of moves from orig to dest, or 0 if
+ *  dest can't be reached.  If gate is set, a gate between two
+ *  locations outside of Faery counts as a single move.
+ */
+func distance(orig, dest int, gate bool) int {
+	gate_dests := map[int][]int{}
+	if gate {
+		for _, i := range loop_gate() {
+			where, to := subloc(i), gate_dest(i)
+			if in_faery(where) || in_faery(to) {
+				continue
+			}
+			gate_dests[where] = append(gate_dests[where], to)
+			gate_dests[to] = append(gate_dests[to], where)
+		}
+	}
+
+	clear_temps(T_loc)
+
+	bx[orig].temp = 1
+
+	for flag := true; bx[dest].temp == 0 && flag; {
+		flag = false
+
+		for _, where := range loop_loc() {
+			if bx[where].temp <= 0 {
+				continue
+			}
+
+			var dests []int
+			if loc_depth(where) != LOC_region {
+				for _, v := range exits_from_loc(0, where) {
+					dests = append(dests, v.destination)
+				}
+			}
+			dests = append(dests, gate_dests[where]...)
+
+			for _, j := range dests {
+				if valid_box(j) && bx[j].temp == 0 {
+					bx[j].temp = bx[where].temp + 1
+					flag = true
+				}
+			}
+
+			if where != dest {
+				bx[where].temp = -1
+			}
+		}
+	}
+
+	if bx[dest].temp <= 0 {
+		return 0
+	}
+	return bx[dest].temp - 1
+}
 
 /*
  *  Fri Apr 10 08:27:16 1998 -- Scott Turner
@@ -93,7 +93,7 @@ func (e *entity_ship) IsZero() bool {
 }
 
 func (e *entity_ship) ToEntityShip() *EntityShip {
-	if e.IsZero() {
+	if e == nil {
 		return nil
 	}
 	return &EntityShip{
@@ -105,3 +105,17 @@ func (e *entity_ship) ToEntityShip() *EntityShip {
 		Sails:     e.sails,
 	}
 }
+
+func (e *EntityShip) toBox() *entity_ship {
+	if e == nil {
+		return nil
+	}
+	return &entity_ship{
+		hulls:      e.Hulls,
+		forts:      e.Forts,
+		sails:      e.Sails,
+		ports:      e.Ports,
+		keels:      e.Keels,
+		galley_ram: e.GalleyRam,
+	}
+}
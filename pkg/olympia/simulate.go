@@ -0,0 +1,565 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/prng"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CombatScenario describes a battle for the simulator.
+// Terrain is the province the battle is fought in (plain, forest, mountain,
+// desert, or swamp; plain if empty). Weather lists the storms over the
+// province (rain or wind). When Seed is not zero, the simulator uses it
+// instead of the library's random seed, so runs can be repeated.
+type CombatScenario struct {
+	Title         string         `json:"title,omitempty"`
+	Battles       int            `json:"battles,omitempty"`
+	Seed          uint32         `json:"seed,omitempty"`
+	Terrain       string         `json:"terrain,omitempty"`
+	Weather       []string       `json:"weather,omitempty"`
+	Fortification *CombatFort    `json:"fortification,omitempty"`
+	Stacks        []*CombatStack `json:"stacks"`
+}
+
+// CombatFort is the structure the first defending stack fights from.
+// Defense defaults to the structure's normal rating and HitPoints to 100.
+type CombatFort struct {
+	Kind      string `json:"kind"` // castle, tower, temple, inn, mine, or guild
+	Defense   *int   `json:"defense,omitempty"`
+	HitPoints int    `json:"hit-points,omitempty"`
+	Damage    int    `json:"damage,omitempty"`
+}
+
+// CombatStack is a stack of nobles. The first noble leads the stack.
+// The first attacking stack is the attacker; any other attacking stacks
+// are stacked beneath its leader. The first defending stack is the target;
+// any other defending stacks stand in the province and join the battle as
+// allies in the second round.
+type CombatStack struct {
+	Name   string         `json:"name,omitempty"`
+	Side   string         `json:"side"` // attacker or defender
+	Nobles []*CombatNoble `json:"nobles"`
+}
+
+// CombatNoble is a noble and the men, beasts, and siege engines it brings
+// to the battle. Skills and Items are keyed by name or entity number;
+// skills give the experience the noble has in the skill.
+// Attack and defense default to 60, health to 100, and the break point to 50.
+type CombatNoble struct {
+	Name       string         `json:"name,omitempty"`
+	Attack     *int           `json:"attack,omitempty"`
+	Defense    *int           `json:"defense,omitempty"`
+	Missile    int            `json:"missile,omitempty"`
+	Health     int            `json:"health,omitempty"`
+	Behind     int            `json:"behind,omitempty"`
+	BreakPoint *int           `json:"break-point,omitempty"`
+	Skills     map[string]int `json:"skills,omitempty"`
+	Items      map[string]int `json:"items,omitempty"`
+}
+
+// CombatStats summarizes the battles run by the simulator.
+type CombatStats struct {
+	Title           string              `json:"title,omitempty"`
+	Battles         int                 `json:"battles"`
+	AttackerWins    int                 `json:"attacker-wins"`
+	DefenderWins    int                 `json:"defender-wins"`
+	Ties            int                 `json:"ties"`
+	NoCombat        int                 `json:"no-combat,omitempty"`
+	AttackerWinRate float64             `json:"attacker-win-rate"`
+	DefenderWinRate float64             `json:"defender-win-rate"`
+	TieRate         float64             `json:"tie-rate"`
+	AverageRounds   float64             `json:"average-rounds"`
+	MinRounds       int                 `json:"min-rounds"`
+	MaxRounds       int                 `json:"max-rounds"`
+	FortDamage      float64             `json:"average-fort-damage,omitempty"`
+	Stacks          []*CombatStackStats `json:"stacks"`
+}
+
+// CombatStackStats is the average losses of a stack per battle, by item,
+// and what became of each of its nobles.
+type CombatStackStats struct {
+	Name   string              `json:"name"`
+	Side   string              `json:"side"`
+	Losses map[string]float64  `json:"average-losses,omitempty"`
+	Nobles []*CombatNobleStats `json:"nobles"`
+}
+
+// CombatNobleStats counts the battles that left the noble unhurt, wounded,
+// taken prisoner, or killed.
+type CombatNobleStats struct {
+	Name     string `json:"name"`
+	Unhurt   int    `json:"unhurt"`
+	Wounded  int    `json:"wounded"`
+	Captured int    `json:"captured"`
+	Killed   int    `json:"killed"`
+}
+
+var (
+	sim_forts = map[string]int{
+		"castle": sub_castle,
+		"guild":  sub_guild,
+		"inn":    sub_inn,
+		"mine":   sub_mine,
+		"temple": sub_temple,
+		"tower":  sub_tower,
+	}
+	sim_terrain = map[string]int{
+		"desert":   sub_desert,
+		"forest":   sub_forest,
+		"mountain": sub_mountain,
+		"plain":    sub_plain,
+		"swamp":    sub_swamp,
+	}
+	sim_weather = map[string]int{
+		"rain": sub_rain,
+		"wind": sub_wind,
+	}
+)
+
+// ParseCombatScenario decodes and checks a scenario.
+// Unknown fields are an error so that typos aren't silently dropped.
+// Skill and item names are checked when the scenario is run.
+func ParseCombatScenario(data []byte) (*CombatScenario, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	sc := &CombatScenario{}
+	if err := dec.Decode(sc); err != nil {
+		return nil, fmt.Errorf("scenario: %w", err)
+	}
+
+	if sc.Terrain == "" {
+		sc.Terrain = "plain"
+	}
+	if _, ok := sim_terrain[sc.Terrain]; !ok {
+		return nil, fmt.Errorf("scenario: unknown terrain %q", sc.Terrain)
+	}
+	for _, w := range sc.Weather {
+		if _, ok := sim_weather[w]; !ok {
+			return nil, fmt.Errorf("scenario: unknown weather %q", w)
+		}
+	}
+	if f := sc.Fortification; f != nil {
+		if _, ok := sim_forts[f.Kind]; !ok {
+			return nil, fmt.Errorf("scenario: unknown fortification %q", f.Kind)
+		} else if f.HitPoints < 0 || f.Damage < 0 {
+			return nil, fmt.Errorf("scenario: fortification: hit-points and damage must not be negative")
+		} else if f.Damage >= or_int(f.HitPoints != 0, f.HitPoints, 100) {
+			return nil, fmt.Errorf("scenario: fortification: damage must be less than hit-points")
+		}
+	}
+
+	attackers, defenders := 0, 0
+	for i, st := range sc.Stacks {
+		if st.Name == "" {
+			st.Name = fmt.Sprintf("stack %d", i+1)
+		}
+		switch st.Side {
+		case "attacker":
+			attackers++
+		case "defender":
+			defenders++
+		default:
+			return nil, fmt.Errorf("scenario: %s: side must be attacker or defender", st.Name)
+		}
+		if len(st.Nobles) == 0 {
+			return nil, fmt.Errorf("scenario: %s: no nobles", st.Name)
+		}
+		for j, nb := range st.Nobles {
+			if nb.Name == "" {
+				nb.Name = fmt.Sprintf("%s noble %d", st.Name, j+1)
+			}
+			if nb.Behind < 0 || nb.Behind > 9 {
+				return nil, fmt.Errorf("scenario: %s: behind must be 0 to 9", nb.Name)
+			} else if nb.Health < 0 || nb.Health > 100 {
+				return nil, fmt.Errorf("scenario: %s: health must be 1 to 100", nb.Name)
+			}
+			for item, qty := range nb.Items {
+				if qty < 0 {
+					return nil, fmt.Errorf("scenario: %s: %s: quantity must not be negative", nb.Name, item)
+				}
+			}
+		}
+	}
+	if attackers == 0 {
+		return nil, fmt.Errorf("scenario: no attacking stack")
+	} else if defenders == 0 {
+		return nil, fmt.Errorf("scenario: no defending stack")
+	}
+
+	return sc, nil
+}
+
+// SimulateCombat loads the database for its items and skills, then fights
+// the scenario's battle the given number of times (the scenario's count
+// if battles is zero) in a throwaway province. The reports the battles
+// generate are written to a temporary directory and discarded; nothing
+// is saved to the library.
+func SimulateCombat(sc *CombatScenario, battles int, options ...Option) (cs *CombatStats, err error) {
+	defer recover_turn("SimulateCombat", &err)
+	defer unlock_tag()
+
+	if battles == 0 {
+		battles = sc.Battles
+	}
+	if battles < 1 {
+		return nil, fmt.Errorf("SimulateCombat: battles must be at least 1")
+	}
+
+	if err := start_turn(options...); err != nil {
+		return nil, fmt.Errorf("SimulateCombat: %w", err)
+	}
+	if sc.Seed != 0 {
+		rng = prng.New(sc.Seed, sc.Seed, sc.Seed, sc.Seed)
+	}
+	if err := sim_resolve(sc); err != nil {
+		return nil, fmt.Errorf("SimulateCombat: %w", err)
+	}
+
+	tmpdir, err := os.MkdirTemp("", "goly-combat-")
+	if err != nil {
+		return nil, fmt.Errorf("SimulateCombat: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpdir)
+	}()
+	savedLibdir := libdir
+	libdir = tmpdir
+	defer func() {
+		libdir = savedLibdir
+	}()
+	immediate = FALSE
+	open_logfile_nondestruct()
+	defer close_logfile()
+
+	s := new_sim_battle(sc)
+	for i := 0; i < battles; i++ {
+		s.fight()
+	}
+
+	return s.stats(), nil
+}
+
+// sim_battle holds the state of the simulator between battles.
+type sim_battle struct {
+	sc      *CombatScenario
+	players [2]int  // the attacking and defending factions
+	region  int     // holds the battlefield
+	nobles  [][]int // the nobles of each stack in the current battle
+	losses  []map[int]int
+	fates   [][]*CombatNobleStats
+	cs      *CombatStats
+	rounds  int
+	damage  int
+}
+
+// sim_resolve checks the skill and item names in the scenario.
+func sim_resolve(sc *CombatScenario) error {
+	for _, st := range sc.Stacks {
+		for _, nb := range st.Nobles {
+			for _, s := range sim_keys(nb.Skills) {
+				if sim_lookup(T_skill, s) == 0 {
+					return fmt.Errorf("%s: unknown skill %q", nb.Name, s)
+				}
+			}
+			for _, s := range sim_keys(nb.Items) {
+				if sim_lookup(T_item, s) == 0 {
+					return fmt.Errorf("%s: unknown item %q", nb.Name, s)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// sim_lookup returns the entity of the given kind named by s, which may be
+// an entity number or a name. Dashes and underscores in s match spaces.
+func sim_lookup(k int, s string) int {
+	if n, err := strconv.Atoi(s); err == nil {
+		if valid_box(n) && kind(n) == k {
+			return n
+		}
+		return 0
+	}
+	s = sim_name(s)
+	for _, n := range loop_kind(k) {
+		if sim_name(name(n)) == s {
+			return n
+		} else if k == T_item && rp_item(n) != nil && sim_name(rp_item(n).plural_name) == s {
+			return n
+		}
+	}
+	return 0
+}
+
+func sim_keys(m map[string]int) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sim_name(s string) string {
+	return strings.ToLower(strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), " "))
+}
+
+func new_sim_battle(sc *CombatScenario) *sim_battle {
+	s := &sim_battle{sc: sc, cs: &CombatStats{Title: sc.Title}}
+	s.region = new_ent(T_loc, sub_region)
+	set_name(s.region, "Simulation")
+	for i, side := range []string{"attackers", "defenders"} {
+		s.players[i] = new_ent(T_player, sub_pl_regular)
+		set_name(s.players[i], fmt.Sprintf("Simulated %s", side))
+	}
+	for _, st := range sc.Stacks {
+		ss := &CombatStackStats{Name: st.Name, Side: st.Side}
+		var fates []*CombatNobleStats
+		for _, nb := range st.Nobles {
+			fates = append(fates, &CombatNobleStats{Name: nb.Name})
+		}
+		ss.Nobles = fates
+		s.cs.Stacks = append(s.cs.Stacks, ss)
+		s.fates = append(s.fates, fates)
+		s.losses = append(s.losses, make(map[int]int))
+	}
+	return s
+}
+
+// fight sets up the province, fights one battle, and records the outcome.
+func (s *sim_battle) fight() {
+	sc := s.sc
+
+	where := new_ent(T_loc, sim_terrain[sc.Terrain])
+	set_name(where, "Battlefield")
+	set_where(where, s.region)
+	created := []int{where}
+	for _, w := range sc.Weather {
+		created = append(created, new_storm(0, sim_weather[w], 1, where))
+	}
+
+	fort := 0
+	if f := sc.Fortification; f != nil {
+		fort = new_ent(T_loc, sim_forts[f.Kind])
+		set_name(fort, "Fortification")
+		set_where(fort, where)
+		p := p_subloc(fort)
+		p.hp = or_int(f.HitPoints != 0, f.HitPoints, 100)
+		p.damage = f.Damage
+		p.defense = fort_default_defense(sim_forts[f.Kind])
+		if f.Defense != nil {
+			p.defense = *f.Defense
+		}
+		created = append(created, fort)
+	}
+
+	// build the stacks
+	s.nobles = make([][]int, len(sc.Stacks))
+	attacker, defender := 0, 0
+	for i, st := range sc.Stacks {
+		pl, place := s.players[0], where
+		if st.Side == "defender" {
+			pl = s.players[1]
+			if defender == 0 && fort != 0 {
+				place = fort
+			}
+		}
+		leader := 0
+		for _, nb := range st.Nobles {
+			n := sim_noble(nb, pl, place)
+			if leader != 0 {
+				stack(n, leader)
+			} else if st.Side == "attacker" && attacker != 0 {
+				stack(n, attacker)
+			}
+			if leader == 0 {
+				leader = n
+			}
+			s.nobles[i] = append(s.nobles[i], n)
+		}
+		if st.Side == "attacker" && attacker == 0 {
+			attacker = leader
+		} else if st.Side == "defender" && defender == 0 {
+			defender = leader
+		}
+	}
+
+	target := defender
+	if fort != 0 {
+		target = fort
+	}
+
+	combat_result, combat_side_a, combat_side_b = 0, nil, nil
+	regular_combat(attacker, target, FALSE, 0)
+	second_wait_list = nil
+	battles_this_turn = nil /* the simulator keeps its own tally */
+
+	s.record()
+
+	// take down the battlefield and free the entity numbers,
+	// or a long run would use them all up
+	for i := len(s.nobles) - 1; i >= 0; i-- {
+		for j := len(s.nobles[i]) - 1; j >= 0; j-- {
+			n := s.nobles[i][j]
+			if kind(n) == T_char {
+				set_lord(n, 0, LOY_UNCHANGED, 0)
+				set_where(n, 0)
+			}
+			free_box(n)
+		}
+	}
+	for i := len(created) - 1; i >= 0; i-- {
+		n := created[i]
+		if kind(n) != T_deleted {
+			set_where(n, 0)
+		}
+		free_box(n)
+	}
+}
+
+// sim_noble creates a noble for the faction at the location,
+// with its skills and items.
+func sim_noble(nb *CombatNoble, pl, where int) int {
+	n := new_ent(T_char, 0)
+	set_name(n, nb.Name)
+	set_where(n, where)
+	set_lord(n, pl, LOY_oath, 1)
+
+	p := p_char(n)
+	p.attack, p.defense, p.missile = 60, 60, nb.Missile
+	if nb.Attack != nil {
+		p.attack = *nb.Attack
+	}
+	if nb.Defense != nil {
+		p.defense = *nb.Defense
+	}
+	p.health = or_int(nb.Health != 0, nb.Health, 100)
+	p.behind = nb.Behind
+	p.break_point = 50
+	if nb.BreakPoint != nil {
+		p.break_point = *nb.BreakPoint
+	}
+
+	// the maps are walked in order so that a seeded run can be repeated
+	for _, name := range sim_keys(nb.Skills) {
+		sk := p_skill_ent(n, sim_lookup(T_skill, name))
+		sk.know = SKILL_know
+		sk.experience = nb.Skills[name]
+	}
+	for _, name := range sim_keys(nb.Items) {
+		gen_item(n, sim_lookup(T_item, name), nb.Items[name])
+	}
+
+	return n
+}
+
+// record adds the outcome of the battle just fought to the totals.
+func (s *sim_battle) record() {
+	cs := s.cs
+	cs.Battles++
+
+	switch combat_result {
+	case A_WON:
+		cs.AttackerWins++
+	case B_WON:
+		cs.DefenderWins++
+	case TIE:
+		cs.Ties++
+	default:
+		cs.NoCombat++
+		return
+	}
+
+	s.rounds += round
+	if cs.MinRounds == 0 || round < cs.MinRounds {
+		cs.MinRounds = round
+	}
+	if round > cs.MaxRounds {
+		cs.MaxRounds = round
+	}
+
+	stack_of := make(map[int]int)
+	for i := range s.nobles {
+		for _, n := range s.nobles[i] {
+			stack_of[n] = i
+		}
+	}
+	for _, l := range [][]*fight{combat_side_a, combat_side_b} {
+		for _, f := range l {
+			if f.kind == FK_fort {
+				s.damage += f.sav_num - f.num
+			} else if i, ok := stack_of[f.unit]; ok && f.kind > 0 {
+				s.losses[i][f.kind] += f.sav_num - f.num
+			}
+		}
+	}
+
+	for i, st := range s.sc.Stacks {
+		for j, n := range s.nobles[i] {
+			fate := s.fates[i][j]
+			if kind(n) != T_char {
+				fate.Killed++
+			} else if is_prisoner(n) {
+				fate.Captured++
+			} else if char_health(n) < or_int(st.Nobles[j].Health != 0, st.Nobles[j].Health, 100) {
+				fate.Wounded++
+			} else {
+				fate.Unhurt++
+			}
+		}
+	}
+}
+
+// stats returns the totals as rates and averages.
+func (s *sim_battle) stats() *CombatStats {
+	cs := s.cs
+	if cs.Battles == 0 {
+		return cs
+	}
+	battles := float64(cs.Battles)
+	cs.AttackerWinRate = float64(cs.AttackerWins) / battles
+	cs.DefenderWinRate = float64(cs.DefenderWins) / battles
+	cs.TieRate = float64(cs.Ties) / battles
+	if fought := cs.Battles - cs.NoCombat; fought != 0 {
+		cs.AverageRounds = float64(s.rounds) / float64(fought)
+	}
+	if s.sc.Fortification != nil {
+		cs.FortDamage = float64(s.damage) / battles
+	}
+	for i, ss := range cs.Stacks {
+		for item, lost := range s.losses[i] {
+			if ss.Losses == nil {
+				ss.Losses = make(map[string]float64)
+			}
+			ss.Losses[plural_item_name(item, 2)] = float64(lost) / battles
+		}
+	}
+	return cs
+}
@@ -262,6 +262,12 @@ const (
 	sk_prot_blast_8      = 2735
 	sk_locate_char       = 2935
 	sk_deep_identify     = 3104
+	sk_show_art_creat    = 3105 /* show artifact creator */
+	sk_show_art_reg      = 3106 /* show artifact region */
+	sk_cloak_creat       = 3140 /* cloak artifact creator */
+	sk_cloak_reg         = 3141 /* cloak artifact region */
+	sk_rem_art_cloak     = 3142 /* remove artifact cloaks */
+	sk_curse_noncreat    = 3143 /* curse noncreator loyalty */
 	sk_shroud_abil       = 2834 /* ability shroud */
 	sk_detect_abil       = 2835 /* detect ability scry */
 	sk_detect_scry       = 2936 /* detect region scry */
@@ -825,3 +831,118 @@ var convSkill = map[int]int{
 	9625: 3237,
 	9626: 3333,
 }
+
+// EntitySkill is the json version of a skill definition.
+type EntitySkill struct {
+	TimeToLearn   int                  `json:"time-to-learn,omitempty"`  // days of study req'd to learn skill
+	TimeToUse     int                  `json:"time-to-use,omitempty"`    // days to use this skill
+	Flags         int                  `json:"flags,omitempty"`          // IS_POLLED, REQ_HOLY_SYMBOL, etc.
+	RequiredSkill int                  `json:"required-skill,omitempty"` // skill required to learn this skill
+	NpReq         int                  `json:"np-req,omitempty"`         // noble points required to learn
+	Offered       []int                `json:"offered,omitempty"`        // skills learnable after this one
+	Research      []int                `json:"research,omitempty"`       // skills researchable with this one
+	Guild         []int                `json:"guild,omitempty"`          // skills offered if you're a guild member
+	Req           []*ReqEnt            `json:"req,omitempty"`            // items required for use or cast
+	Produced      int                  `json:"produced,omitempty"`       // simple production skill result
+	NoExp         int                  `json:"no-exp,omitempty"`         // this skill not rated for experience
+	PracticeCost  int                  `json:"practice-cost,omitempty"`  // cost to practice this skill
+	PracticeTime  int                  `json:"practice-time,omitempty"`  // time to practice this skill
+	PracticeProg  int                  `json:"practice-prog,omitempty"`  // a day longer to practice each N experience levels
+	Piety         int                  `json:"piety,omitempty"`          // piety (or aura) required to use
+	Religion      *EntityReligionSkill `json:"religion,omitempty"`       // set if this is a religion skill
+}
+
+// ReqEnt is an item required to use a skill.
+type ReqEnt struct {
+	Item    int `json:"item"`              // item required to use
+	Qty     int `json:"qty,omitempty"`     // quantity required
+	Consume int `json:"consume,omitempty"` // REQ_xx
+}
+
+// EntityReligionSkill is the json version of the god behind a religion skill.
+type EntityReligionSkill struct {
+	Name       string `json:"name,omitempty"`        // of the god
+	Strength   int    `json:"strength,omitempty"`    // related strength skill
+	Weakness   int    `json:"weakness,omitempty"`    // related weakness skill
+	Plant      int    `json:"plant,omitempty"`       // the holy plant
+	Animal     int    `json:"animal,omitempty"`      // the holy animal
+	Terrain    int    `json:"terrain,omitempty"`     // holy terrain
+	HighPriest int    `json:"high-priest,omitempty"` // the high priest
+	Bishops    [2]int `json:"bishops"`               // the two bishops
+}
+
+func (e *entity_skill) ToEntitySkill() *EntitySkill {
+	if e == nil {
+		return nil
+	}
+	es := &EntitySkill{
+		TimeToLearn:   e.time_to_learn,
+		TimeToUse:     e.time_to_use,
+		Flags:         e.flags,
+		RequiredSkill: e.required_skill,
+		NpReq:         e.np_req,
+		Offered:       append([]int(nil), e.offered...),
+		Research:      append([]int(nil), e.research...),
+		Guild:         append([]int(nil), e.guild...),
+		Produced:      e.produced,
+		NoExp:         e.no_exp,
+		PracticeCost:  e.practice_cost,
+		PracticeTime:  e.practice_time,
+		PracticeProg:  e.practice_prog,
+		Piety:         e.piety,
+	}
+	for _, r := range e.req {
+		es.Req = append(es.Req, &ReqEnt{Item: r.item, Qty: r.qty, Consume: r.consume})
+	}
+	if r := e.religion_skill; r != nil {
+		es.Religion = &EntityReligionSkill{
+			Name:       r.name,
+			Strength:   r.strength,
+			Weakness:   r.weakness,
+			Plant:      r.plant,
+			Animal:     r.animal,
+			Terrain:    r.terrain,
+			HighPriest: r.high_priest,
+			Bishops:    r.bishops,
+		}
+	}
+	return es
+}
+
+func (e *EntitySkill) toBox() *entity_skill {
+	if e == nil {
+		return nil
+	}
+	es := &entity_skill{
+		time_to_learn:  e.TimeToLearn,
+		time_to_use:    e.TimeToUse,
+		flags:          e.Flags,
+		required_skill: e.RequiredSkill,
+		np_req:         e.NpReq,
+		offered:        append([]int(nil), e.Offered...),
+		research:       append([]int(nil), e.Research...),
+		guild:          append([]int(nil), e.Guild...),
+		produced:       e.Produced,
+		no_exp:         e.NoExp,
+		practice_cost:  e.PracticeCost,
+		practice_time:  e.PracticeTime,
+		practice_prog:  e.PracticeProg,
+		piety:          e.Piety,
+	}
+	for _, r := range e.Req {
+		es.req = append(es.req, &req_ent{item: r.Item, qty: r.Qty, consume: r.Consume})
+	}
+	if r := e.Religion; r != nil {
+		es.religion_skill = &entity_religion_skill{
+			name:        r.Name,
+			strength:    r.Strength,
+			weakness:    r.Weakness,
+			plant:       r.Plant,
+			animal:      r.Animal,
+			terrain:     r.Terrain,
+			high_priest: r.HighPriest,
+			bishops:     r.Bishops,
+		}
+	}
+	return es
+}
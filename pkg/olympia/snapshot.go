@@ -0,0 +1,354 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SNAPSHOT_DIR is the directory in libdir that holds the turn snapshots.
+// Snapshots are only taken when a turn is run with the save flag set;
+// a turn that isn't saved doesn't change anything that needs rolling back.
+const SNAPSHOT_DIR = "snapshots"
+
+// ROLLBACK_DIR is where a rollback moves the output of the turns it undoes.
+const ROLLBACK_DIR = "rolled-back"
+
+// snapshot_paths are the files and directories in libdir that make up
+// the state of the game before a turn: the system data, the PRNG seed,
+// the ledger, the box database (in either format), the orders, the
+// queued join requests, and the editorials waiting for the Times.
+var snapshot_paths = []string{
+	"sysdata.json",
+	SEED_FILE,
+	LEDGER_FILE,
+	"master",
+	"loc", "item", "skill", "gate", "road", "ship", "unform", "misc", "nation",
+	"fact",
+	BOXES_FILE,
+	FACTIONS_DIR,
+	"orders",
+	JOINS_DIR,
+	filepath.Join(TIMES_DIR, "editorials.json"),
+}
+
+// RollbackTurn restores the game to the state it was in before the given turn was run.
+// The snapshot's checksum is verified before anything in the library is changed.
+// The reports, back issues of the Times, logs, and snapshots from that turn
+// and the ones after it are moved to ROLLBACK_DIR so that they can't be
+// mistaken for the output of the re-run.
+func RollbackTurn(turn int, options ...Option) (err error) {
+	defer recover_turn("RollbackTurn", &err)
+	defer unlock_tag()
+
+	for _, option := range options {
+		if err := option(); err != nil {
+			return fmt.Errorf("RollbackTurn: %w", err)
+		}
+	}
+	if err := lock_tag(); err != nil {
+		return fmt.Errorf("RollbackTurn: %w", err)
+	}
+
+	data, err := read_snapshot(turn)
+	if err != nil {
+		return fmt.Errorf("RollbackTurn: %w", err)
+	} else if err := restore_snapshot(data); err != nil {
+		return fmt.Errorf("RollbackTurn: %w", err)
+	}
+	log.Printf("RollbackTurn: restored the state before turn %d\n", turn)
+	if err := move_rolled_back(turn); err != nil {
+		return fmt.Errorf("RollbackTurn: %w", err)
+	}
+
+	return nil
+}
+
+// move_rolled_back moves the output of the given turn and the turns after
+// it into ROLLBACK_DIR, keeping their paths relative to libdir.
+// The snapshot for the turn itself is kept, since it is still good.
+func move_rolled_back(turn int) error {
+	// turn_of returns the turn number at the start of name, or -1
+	turn_of := func(name string) int {
+		if n, err := strconv.Atoi(strings.SplitN(name, ".", 2)[0]); err == nil {
+			return n
+		}
+		return -1
+	}
+
+	var paths []string
+	if isdir(filepath.Join(libdir, "log")) {
+		paths = append(paths, "log")
+	}
+	for _, dir := range []struct {
+		name  string
+		after int // first turn to move
+	}{
+		{"save", turn},
+		{TIMES_DIR, turn},
+		{SNAPSHOT_DIR, turn + 1},
+	} {
+		files, err := os.ReadDir(filepath.Join(libdir, dir.name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("move_rolled_back: %w", err)
+		}
+		for _, f := range files {
+			if t := turn_of(f.Name()); t >= dir.after {
+				paths = append(paths, filepath.Join(dir.name, f.Name()))
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	aside := filepath.Join(ROLLBACK_DIR, fmt.Sprintf("%d-%s", turn, time.Now().UTC().Format("20060102T150405")))
+	for _, path := range paths {
+		to := filepath.Join(libdir, aside, path)
+		if err := os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+			return fmt.Errorf("move_rolled_back: %w", err)
+		} else if err := os.Rename(filepath.Join(libdir, path), to); err != nil {
+			return fmt.Errorf("move_rolled_back: %w", err)
+		}
+	}
+	log.Printf("move_rolled_back: moved %d files from turn %d on to %s\n", len(paths), turn, aside)
+
+	// the engine expects the log directory to exist
+	if err := mkdir(filepath.Join(libdir, "log")); err != nil {
+		return fmt.Errorf("move_rolled_back: %w", err)
+	}
+	return nil
+}
+
+// Snapshots returns the turns that have snapshots, in order.
+func Snapshots(options ...Option) ([]int, error) {
+	for _, option := range options {
+		if err := option(); err != nil {
+			return nil, fmt.Errorf("Snapshots: %w", err)
+		}
+	}
+	files, err := os.ReadDir(filepath.Join(libdir, SNAPSHOT_DIR))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("Snapshots: %w", err)
+	}
+	var turns []int
+	for _, f := range files {
+		if name := f.Name(); strings.HasSuffix(name, ".tar.gz") && isdigit(name[0]) {
+			turns = append(turns, atoi(name))
+		}
+	}
+	sort.Ints(turns)
+	return turns, nil
+}
+
+func snapshot_name(turn int) string {
+	return filepath.Join(libdir, SNAPSHOT_DIR, fmt.Sprintf("%d.tar.gz", turn))
+}
+
+func snapshot_sum_name(turn int) string {
+	return filepath.Join(libdir, SNAPSHOT_DIR, fmt.Sprintf("%d.sha256", turn))
+}
+
+// begin_snapshot archives the current state of the library into a temporary file.
+// The turn that is about to run is the one after the current turn.
+// The snapshot is only kept if commit_snapshot is called after the turn is saved.
+func begin_snapshot() (string, error) {
+	if err := mkdir(filepath.Join(libdir, SNAPSHOT_DIR)); err != nil {
+		return "", fmt.Errorf("begin_snapshot: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(zw)
+	for _, path := range snapshot_paths {
+		if err := add_to_snapshot(tw, path); err != nil {
+			return "", fmt.Errorf("begin_snapshot: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return "", fmt.Errorf("begin_snapshot: %w", err)
+	} else if err := zw.Close(); err != nil {
+		return "", fmt.Errorf("begin_snapshot: %w", err)
+	}
+
+	tmp := snapshot_name(sysclock.turn+1) + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0666); err != nil {
+		return "", fmt.Errorf("begin_snapshot: %w", err)
+	}
+	return tmp, nil
+}
+
+// commit_snapshot moves the temporary snapshot into place and writes its checksum.
+func commit_snapshot(tmp string) error {
+	data, err := os.ReadFile(tmp)
+	if err != nil {
+		return fmt.Errorf("commit_snapshot: %w", err)
+	}
+	name := strings.TrimSuffix(tmp, ".tmp")
+	sum := sha256.Sum256(data)
+	line := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), filepath.Base(name))
+	if err := os.WriteFile(strings.TrimSuffix(name, ".tar.gz")+".sha256", []byte(line), 0666); err != nil {
+		return fmt.Errorf("commit_snapshot: %w", err)
+	} else if err := rename(tmp, name); err != nil {
+		return fmt.Errorf("commit_snapshot: %w", err)
+	}
+	log.Printf("commit_snapshot: %s\n", name)
+	return nil
+}
+
+// abandon_snapshot removes a temporary snapshot after a turn failed.
+func abandon_snapshot(tmp string) {
+	if tmp == "" {
+		return
+	} else if err := os.Remove(tmp); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("abandon_snapshot: %v\n", err)
+	}
+}
+
+// add_to_snapshot adds a file or directory from libdir to the archive.
+// Files that don't exist are skipped, since only one of the database formats is present.
+func add_to_snapshot(tw *tar.Writer, path string) error {
+	root := filepath.Join(libdir, path)
+	if _, err := os.Stat(root); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if d.IsDir() || strings.HasSuffix(name, "~") {
+			return nil
+		}
+		rel, err := filepath.Rel(libdir, name)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		hdr := &tar.Header{Name: filepath.ToSlash(rel), Mode: 0666, Size: int64(len(data))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		} else if _, err := tw.Write(data); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+// read_snapshot returns the archive for the turn after checking it against the recorded checksum.
+func read_snapshot(turn int) ([]byte, error) {
+	data, err := os.ReadFile(snapshot_name(turn))
+	if err != nil {
+		return nil, fmt.Errorf("read_snapshot: %w", err)
+	}
+	line, err := os.ReadFile(snapshot_sum_name(turn))
+	if err != nil {
+		return nil, fmt.Errorf("read_snapshot: %w", err)
+	}
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("read_snapshot: %s: missing checksum", snapshot_sum_name(turn))
+	}
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); got != fields[0] {
+		return nil, fmt.Errorf("read_snapshot: turn %d: checksum mismatch: want %s, got %s", turn, fields[0], got)
+	}
+	return data, nil
+}
+
+// restore_snapshot removes the current state from libdir and extracts the archive in its place.
+// Every entry is checked before anything is removed, so a bad archive leaves libdir alone.
+func restore_snapshot(data []byte) error {
+	files := make(map[string][]byte)
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("restore_snapshot: %w", err)
+	}
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("restore_snapshot: %w", err)
+		}
+		name := filepath.FromSlash(hdr.Name)
+		if !snapshot_member(name) {
+			return fmt.Errorf("restore_snapshot: %q: not part of a snapshot", hdr.Name)
+		}
+		if files[name], err = io.ReadAll(tr); err != nil {
+			return fmt.Errorf("restore_snapshot: %s: %w", hdr.Name, err)
+		}
+	}
+	if err := zr.Close(); err != nil {
+		return fmt.Errorf("restore_snapshot: %w", err)
+	}
+
+	for _, path := range snapshot_paths {
+		if err := os.RemoveAll(filepath.Join(libdir, path)); err != nil {
+			return fmt.Errorf("restore_snapshot: %w", err)
+		}
+	}
+	for name, data := range files {
+		path := filepath.Join(libdir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("restore_snapshot: %w", err)
+		} else if err := os.WriteFile(path, data, 0666); err != nil {
+			return fmt.Errorf("restore_snapshot: %w", err)
+		}
+	}
+	// the engine expects the orders directory to exist even if nobody sent orders
+	if err := mkdir(filepath.Join(libdir, "orders")); err != nil {
+		return fmt.Errorf("restore_snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// snapshot_member returns true if the name is inside one of the snapshot paths.
+func snapshot_member(name string) bool {
+	if filepath.IsAbs(name) || name != filepath.Clean(name) || strings.HasPrefix(name, "..") {
+		return false
+	}
+	for _, path := range snapshot_paths {
+		if name == path || strings.HasPrefix(name, path+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
@@ -241,12 +241,24 @@ func match_lines(who int, s string) {
 	out(who, "%s", string(buf))
 }
 
+// log_dir overrides the directory the output is written to,
+// for commands that mustn't touch libdir/log.
+var log_dir string
+
+// logdir returns the directory the output is written to.
+func logdir() string {
+	if log_dir != "" {
+		return log_dir
+	}
+	return filepath.Join(libdir, "log")
+}
+
 /*
  *  Fan-out decollating	fp allocater
  */
 func open_fp(fp *fp_ent, player int) *fp_ent {
 	var err error
-	fp.name = filepath.Join(libdir, "log", fmt.Sprintf("%d", player))
+	fp.name = filepath.Join(logdir(), fmt.Sprintf("%d", player))
 	fp.fp, err = os.OpenFile(fp.name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	if err != nil {
 		log.Printf("open_fp: can't open %q: %v\n", fp.name, err)
@@ -260,16 +272,16 @@ func open_fp(fp *fp_ent, player int) *fp_ent {
 func open_logfile() {
 	if immediate != FALSE {
 		return
-	} else if err := rmdir(filepath.Join(libdir, "log")); err != nil {
+	} else if err := rmdir(logdir()); err != nil {
 		panic(err)
-	} else if err = os.Mkdir(filepath.Join(libdir, "log"), 0755); err != nil {
+	} else if err = os.Mkdir(logdir(), 0755); err != nil {
 		panic(err)
 	}
 }
 
 func open_logfile_nondestruct() {
-	if err := mkdir(filepath.Join(libdir, "log")); err != nil {
-		panic(fmt.Sprintf("%q: %v\n", filepath.Join(libdir, "log"), err))
+	if err := mkdir(logdir()); err != nil {
+		panic(fmt.Sprintf("%q: %v\n", logdir(), err))
 	}
 }
 
@@ -643,7 +643,7 @@ func v_stack(c *command) int {
 	 *  Clerics can't stack w/ magicians/undead and vice versa
 	 *
 	 */
-	if options.mp_antipathy &&
+	if game_rules.MPAntipathy &&
 		((stack_contains_priest(c.who) != FALSE &&
 			stack_contains_mu_undead(target) != FALSE) ||
 			(stack_contains_priest(target) != FALSE &&
@@ -45,10 +45,12 @@ func fprintf(fp *os.File, format string, args ...interface{}) {
 	_, _ = fp.WriteString(fmt.Sprintf(format, args...))
 }
 
+// fputb and fputs behave like the C library function and
+// do not append a newline.
 func fputb(s []byte, fp *os.File) {
-	fprintf(fp, "%s\n", string(s))
+	_, _ = fp.Write(s)
 }
 
 func fputs(s string, fp *os.File) {
-	fprintf(fp, "%s\n", s)
+	_, _ = fp.WriteString(s)
 }
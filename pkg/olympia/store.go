@@ -0,0 +1,339 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The box database can be stored in the legacy line-oriented text
+// format (loc, item, skill, ..., fact/<pl>) or as JSON.
+const (
+	DB_TEXT = "text"
+	DB_JSON = "json"
+)
+
+// The JSON store keeps the boxes that belong to a faction (the player
+// and its units) in factions/<pl>.json and everything else in boxes.json.
+// SWAP_FILE exists while a save is moving a new store into place.
+const (
+	BOXES_FILE   = "boxes.json"
+	FACTIONS_DIR = "factions"
+	SWAP_FILE    = "boxes.swap"
+)
+
+var (
+	db_load_format = "" // format to load; empty means use the one recorded in sysdata
+	db_save_format = "" // format to save; empty means use the one we loaded
+)
+
+// BoxStore is the json version of a file in the box database.
+type BoxStore struct {
+	Boxes []*Box `json:"boxes"`
+}
+
+// WithDBFormat sets the format used to load and save the box database.
+func WithDBFormat(format string) func() error {
+	return func() error {
+		if err := check_db_format(format); err != nil {
+			return fmt.Errorf("db format: %w", err)
+		}
+		db_load_format, db_save_format = format, format
+		return nil
+	}
+}
+
+// WithSaveFormat sets the format used to save the box database.
+// It lets us load a database in one format and save it in the other.
+func WithSaveFormat(format string) func() error {
+	return func() error {
+		if err := check_db_format(format); err != nil {
+			return fmt.Errorf("save format: %w", err)
+		}
+		db_save_format = format
+		return nil
+	}
+}
+
+func check_db_format(format string) error {
+	switch format {
+	case DB_TEXT, DB_JSON:
+		return nil
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// ConvertDB loads the box database and saves it in the requested format.
+// The old files are left in place; sysdata records which format is current.
+func ConvertDB(to string, options ...Option) (err error) {
+	defer recover_turn("ConvertDB", &err)
+	defer unlock_tag()
+
+	options = append(options, WithSaveFormat(to))
+	if err := start_turn(options...); err != nil {
+		return fmt.Errorf("ConvertDB: %w", err)
+	}
+	log.Printf("ConvertDB: converting %s to %s\n", db_load_format, db_save_format)
+	if err := check_db(); err != nil {
+		return fmt.Errorf("ConvertDB: %w", err)
+	} else if err := save_db(); err != nil {
+		return fmt.Errorf("ConvertDB: %w", err)
+	}
+
+	return nil
+}
+
+// LoadDB loads and checks the box database without saving it.
+// It returns the number of boxes loaded.
+func LoadDB(options ...Option) (count int, err error) {
+	defer recover_turn("LoadDB", &err)
+	defer unlock_tag()
+
+	if err := start_turn(options...); err != nil {
+		return 0, fmt.Errorf("LoadDB: %w", err)
+	}
+
+	return len(sorted_box_ids()), nil
+}
+
+// read_json_boxes loads the JSON box store.
+// All the boxes are allocated before any are filled in so that
+// references between them can be checked.
+// If a save was interrupted while swapping the new store in, the swap
+// is finished first when we hold the lib-dir lock; without the lock the
+// store may be half swapped, so we refuse to load it.
+func read_json_boxes() error {
+	stage("read_json_boxes")
+
+	if _, err := os.Stat(filepath.Join(libdir, SWAP_FILE)); err == nil {
+		if !lock_held {
+			return fmt.Errorf("read_json_boxes: %s exists: a save is in progress or was interrupted", SWAP_FILE)
+		}
+		log.Printf("read_json_boxes: finishing an interrupted save\n")
+		if err := swap_json_boxes(); err != nil {
+			return fmt.Errorf("read_json_boxes: %w", err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read_json_boxes: %w", err)
+	}
+
+	var list []*Box
+	store, err := read_box_store(filepath.Join(libdir, BOXES_FILE))
+	if err != nil {
+		return fmt.Errorf("read_json_boxes: %w", err)
+	}
+	list = append(list, store.Boxes...)
+
+	dir := filepath.Join(libdir, FACTIONS_DIR)
+	files, err := os.ReadDir(dir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read_json_boxes: %w", err)
+	}
+	for _, f := range files {
+		if f.IsDir() || !isdigit(f.Name()[0]) || !strings.HasSuffix(f.Name(), ".json") {
+			continue
+		}
+		store, err := read_box_store(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return fmt.Errorf("read_json_boxes: %w", err)
+		}
+		list = append(list, store.Boxes...)
+	}
+
+	// pass 1: call alloc_box for each entity
+	for _, b := range list {
+		if !(b.Id > 0 && b.Id < MAX_BOXES) {
+			return fmt.Errorf("read_json_boxes: invalid box id %d", b.Id)
+		} else if bx[b.Id] != nil {
+			return fmt.Errorf("read_json_boxes: duplicate box %d", b.Id)
+		}
+		alloc_box(b.Id, b.Kind, b.SubKind)
+	}
+
+	// pass 2: read the entity attributes
+	for _, b := range list {
+		if err := b.toBox(bx[b.Id]); err != nil {
+			return fmt.Errorf("read_json_boxes: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func read_box_store(fnam string) (*BoxStore, error) {
+	data, err := os.ReadFile(fnam)
+	if err != nil {
+		return nil, err
+	}
+	store := &BoxStore{}
+	if err := json.Unmarshal(data, store); err != nil {
+		return nil, fmt.Errorf("%s: %w", fnam, err)
+	}
+	return store, nil
+}
+
+// write_json_boxes saves every box to the JSON store.
+// The new store is written beside the old one. A failed or interrupted
+// write leaves the old store as it was. Once everything is written,
+// SWAP_FILE is created and the new store is moved into place; if that
+// is interrupted, the next load finishes it, so the store is never
+// loaded half old and half new.
+func write_json_boxes() error {
+	dir := filepath.Join(libdir, FACTIONS_DIR)
+	tmp := dir + ".tmp"
+	if err := rmdir(tmp); err != nil {
+		return fmt.Errorf("write_json_boxes: %w", err)
+	} else if err := mkdir(tmp); err != nil {
+		return fmt.Errorf("write_json_boxes: %w", err)
+	}
+
+	// boxes belonging to a faction go in the faction's file
+	saved := make(map[int]bool)
+	for _, pl := range loop_player() {
+		if err := write_json_player(tmp, pl); err != nil {
+			return fmt.Errorf("write_json_boxes: %w", err)
+		}
+		saved[pl] = true
+		for _, who := range loop_units(pl) {
+			saved[who] = true
+		}
+	}
+
+	store := &BoxStore{}
+	for _, n := range sorted_box_ids() {
+		if !saved[n] {
+			store.Boxes = append(store.Boxes, bx[n].ToBox(n))
+		}
+	}
+	boxes := filepath.Join(libdir, BOXES_FILE)
+	if err := write_box_store(boxes+".tmp", store); err != nil {
+		return fmt.Errorf("write_json_boxes: %w", err)
+	}
+
+	// everything is written; swap the new store in
+	if err := os.WriteFile(filepath.Join(libdir, SWAP_FILE), nil, 0666); err != nil {
+		return fmt.Errorf("write_json_boxes: %w", err)
+	} else if err := swap_json_boxes(); err != nil {
+		return fmt.Errorf("write_json_boxes: %w", err)
+	}
+
+	return nil
+}
+
+// swap_json_boxes moves the store written by write_json_boxes into place
+// and removes SWAP_FILE. Each step checks what is left to do, so it can
+// be run again to finish a swap that was interrupted.
+func swap_json_boxes() error {
+	dir := filepath.Join(libdir, FACTIONS_DIR)
+	tmp, old := dir+".tmp", dir+".old"
+	boxes := filepath.Join(libdir, BOXES_FILE)
+
+	if isdir(tmp) {
+		if isdir(dir) {
+			if err := rmdir(old); err != nil {
+				return fmt.Errorf("swap_json_boxes: %w", err)
+			} else if err := os.Rename(dir, old); err != nil {
+				return fmt.Errorf("swap_json_boxes: %w", err)
+			}
+		}
+		if err := os.Rename(tmp, dir); err != nil {
+			return fmt.Errorf("swap_json_boxes: %w", err)
+		}
+	}
+	if err := os.Rename(boxes+".tmp", boxes); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("swap_json_boxes: %w", err)
+	} else if err := rmdir(old); err != nil {
+		return fmt.Errorf("swap_json_boxes: %w", err)
+	} else if err := os.Remove(filepath.Join(libdir, SWAP_FILE)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("swap_json_boxes: %w", err)
+	}
+
+	return nil
+}
+
+// write_json_player saves the player and its units to the faction's file in dir.
+func write_json_player(dir string, pl int) error {
+	store := &BoxStore{}
+	if kind(pl) != T_deleted {
+		store.Boxes = append(store.Boxes, bx[pl].ToBox(pl))
+	}
+	for _, who := range loop_units(pl) {
+		if kind(who) != T_deleted {
+			store.Boxes = append(store.Boxes, bx[who].ToBox(who))
+		}
+	}
+	fnam := filepath.Join(dir, strconv.Itoa(pl)+".json")
+	if err := write_box_store(fnam, store); err != nil {
+		return fmt.Errorf("write_json_player: %w", err)
+	}
+	return nil
+}
+
+// write_box_store writes to a temporary file first so that a failed
+// save doesn't leave a truncated store behind.
+func write_box_store(fnam string, store *BoxStore) error {
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("%s: %w", fnam, err)
+	}
+	tmp := fnam + ".tmp"
+	if err := os.WriteFile(tmp, data, 0666); err != nil {
+		return err
+	} else if err := os.Rename(tmp, fnam); err != nil {
+		return err
+	}
+	return nil
+}
+
+// sorted_box_ids returns the ids of all the boxes that should be saved.
+func sorted_box_ids() []int {
+	var ids []int
+	for n, p := range bx {
+		if p != nil && kind(n) != T_deleted {
+			ids = append(ids, n)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// load_format returns the format of the box database in libdir.
+func load_format() string {
+	if db_load_format == "" {
+		db_load_format = DB_TEXT
+	}
+	return db_load_format
+}
+
+// save_format returns the format to save the box database in.
+func save_format() string {
+	if db_save_format == "" {
+		db_save_format = load_format()
+	}
+	return db_save_format
+}
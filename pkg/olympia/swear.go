@@ -58,6 +58,24 @@ func set_loyal(who int, k int, lev int) {
 	p.loy_rate = lev
 }
 
+/*
+ *  delta_loyalty changes the loyalty rate of a unit, never letting it
+ *  drop below zero.  Units sworn by oath keep their rate.
+ */
+func delta_loyalty(who int, amount int, silent bool) {
+	p := p_char(who)
+	if p.loy_kind == LOY_oath || amount == 0 {
+		return
+	}
+
+	old := p.loy_rate
+	p.loy_rate = max(0, p.loy_rate+amount)
+
+	if !silent && p.loy_rate != old {
+		wout(who, "Loyalty changed to %s.", loyal_s(who))
+	}
+}
+
 /*
  *  is b sworn somewhere beneath a?
  */
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -54,12 +55,11 @@ type SysData struct {
 	SkillPlayer          int       `json:"skill-player,omitempty"`
 	Seed                 [3]int    `json:"seed,omitempty"`
 	AccountingDir        string    `json:"accounting-dir,omitempty"`
-	AccountingProg       string    `json:"accounting-prog,omitempty"`
 	CPP                  string    `json:"cpp,omitempty"`
 	HTMLPath             string    `json:"html-path,omitempty"`
 	HTMLPasswords        string    `json:"html-passwords,omitempty"`
 	Free                 bool      `json:"free,omitempty"`
-	FullMarkets          bool      `json:"full-markets,omitempty"`
+	FullMarkets          *bool     `json:"full-markets,omitempty"` // unset means true
 	GuildTeaching        bool      `json:"guild-teaching,omitempty"`
 	MonsterSublocInit    bool      `json:"monster-subloc-init,omitempty"`
 	MPAntipathy          bool      `json:"mp-antipathy,omitempty"`
@@ -91,6 +91,13 @@ type SysData struct {
 	MiddlePiety          int       `json:"middle-piety,omitempty"`
 	BottomPiety          int       `json:"bottom-piety,omitempty"`
 	HeadPriestPietyLimit int       `json:"head-priest-piety-limit,omitempty"`
+	SMTPHost             string    `json:"smtp-host,omitempty"`
+	SMTPPort             int       `json:"smtp-port,omitempty"`
+	SMTPUser             string    `json:"smtp-user,omitempty"`
+	SMTPPassword         string    `json:"smtp-password,omitempty"` // read from old files only; see SMTP_PASSWORD_FILE
+	SMTPStartTLS         bool      `json:"smtp-starttls,omitempty"`
+	MailDir              string    `json:"mail-dir,omitempty"`
+	DBFormat             string    `json:"db-format,omitempty"` // format of the box database, text or json
 
 	NL int `json:"nl,omitempty"`
 	NR int `json:"nr,omitempty"`
@@ -127,6 +134,9 @@ func SysDataLoad(name string) (*SysData, error) {
 		js.MiddlePiety, js.BottomPiety = js.BottomPiety, js.MiddlePiety
 	}
 
+	if db_load_format == "" {
+		db_load_format = js.DBFormat
+	}
 	cloud_region = js.CloudRegion
 	combat_pl = js.CombatPlayer
 	cookie_init = js.CookieInit
@@ -150,7 +160,6 @@ func SysDataLoad(name string) (*SysData, error) {
 	options.created_at = js.CreatedAt
 	options.updated_at = js.UpdatedAt
 	options.accounting_dir = js.AccountingDir
-	options.accounting_prog = js.AccountingProg
 	options.auto_drop = js.AutoDrop
 	options.bottom_piety = js.BottomPiety
 	options.check_balance = js.CheckBalance
@@ -159,11 +168,12 @@ func SysDataLoad(name string) (*SysData, error) {
 	options.death_nps = js.DeathNPs
 	options.free = js.Free
 	options.free_np_limit = js.FreeNPLimit
-	options.full_markets = js.FullMarkets
+	options.full_markets = js.FullMarkets == nil || *js.FullMarkets
 	options.guild_teaching = js.GuildTeaching
 	options.head_priest_piety_limit = js.HeadPriestPietyLimit
 	options.html_passwords = js.HTMLPasswords
 	options.html_path = js.HTMLPath
+	options.mail_dir = js.MailDir
 	options.market_age = js.MarketAge
 	options.middle_piety = js.MiddlePiety
 	options.min_piety = js.MinPiety
@@ -172,6 +182,11 @@ func SysDataLoad(name string) (*SysData, error) {
 	options.open_ended = js.OpenEnded
 	options.output_tags = js.OutputTags
 	options.piety_limit = js.PietyLimit
+	options.smtp_host = js.SMTPHost
+	options.smtp_password = js.SMTPPassword
+	options.smtp_port = js.SMTPPort
+	options.smtp_starttls = js.SMTPStartTLS
+	options.smtp_user = js.SMTPUser
 	options.survive_np = js.SurviveNP
 	options.times_pay = js.TimesPay
 	options.top_piety = js.TopPiety
@@ -207,6 +222,7 @@ func SysDataSave(name string) error {
 		js.CreatedAt = time.Now().UTC()
 	}
 	js.UpdatedAt = time.Now().UTC()
+	js.DBFormat = save_format()
 	js.CloudRegion = cloud_region
 	js.CombatPlayer = combat_pl
 	js.CookieInit = cookie_init
@@ -228,7 +244,6 @@ func SysDataSave(name string) error {
 	js.SysClock.Turn = sysclock.turn
 	js.SysClock.DaysSinceEpoch = sysclock.days_since_epoch
 	js.AccountingDir = options.accounting_dir
-	js.AccountingProg = options.accounting_prog
 	js.AutoDrop = options.auto_drop
 	js.BottomPiety = options.bottom_piety
 	js.CheckBalance = options.check_balance
@@ -237,11 +252,12 @@ func SysDataSave(name string) error {
 	js.DeathNPs = options.death_nps
 	js.Free = options.free
 	js.FreeNPLimit = options.free_np_limit
-	js.FullMarkets = options.full_markets
+	js.FullMarkets = &options.full_markets
 	js.GuildTeaching = options.guild_teaching
 	js.HeadPriestPietyLimit = options.head_priest_piety_limit
 	js.HTMLPasswords = options.html_passwords
 	js.HTMLPath = options.html_path
+	js.MailDir = options.mail_dir
 	js.MarketAge = options.market_age
 	js.MiddlePiety = options.middle_piety
 	js.MinPiety = options.min_piety
@@ -250,6 +266,10 @@ func SysDataSave(name string) error {
 	js.OpenEnded = options.open_ended
 	js.OutputTags = options.output_tags
 	js.PietyLimit = options.piety_limit
+	js.SMTPHost = options.smtp_host
+	js.SMTPPort = options.smtp_port
+	js.SMTPStartTLS = options.smtp_starttls
+	js.SMTPUser = options.smtp_user
 	js.SurviveNP = options.survive_np
 	js.TimesPay = options.times_pay
 	js.TopPiety = options.top_piety
@@ -266,6 +286,14 @@ func SysDataSave(name string) error {
 	js.XSize = xsize
 	js.YSize = ysize
 
+	// the mail server's password from an old sysdata.json moves to its
+	// own file, which the snapshots leave out
+	if options.smtp_password != "" {
+		if err := save_smtp_password(filepath.Join(filepath.Dir(name), SMTP_PASSWORD_FILE), options.smtp_password); err != nil {
+			return fmt.Errorf("SysDataSave: %w", err)
+		}
+	}
+
 	data, err := json.MarshalIndent(js, "", "  ")
 	if err != nil {
 		return fmt.Errorf("SysDataSave: %w", err)
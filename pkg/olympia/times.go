@@ -21,8 +21,10 @@ package olympia
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
+	"github.com/mdhender/golympia/pkg/times"
 	"log"
 	"os"
 	"path/filepath"
@@ -30,12 +32,12 @@ import (
 	"time"
 )
 
-var (
-	DASH_LINE = "===-===-===-===-===-===-===-===-===-===-===-===-===-===-===-===-===-===-=\n"
-	months    = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
-	press_fp  *os.File
-	rumor_fp  *os.File
-)
+// TIMES_DIR holds the archive of back issues, one JSON file per turn,
+// and the editorials waiting for the next issue.
+const TIMES_DIR = "times"
+
+// times_articles collects the press and rumors posted this turn.
+var times_articles []*times.Article
 
 /*
  *  Fri Mar  6 09:53:39 1998 -- Scott Turner
@@ -44,116 +46,186 @@ var (
  *
  */
 
-func close_times() {
-	press_fp = fclose(press_fp)
-	rumor_fp = fclose(rumor_fp)
+// times_archive returns the archive of back issues.
+func times_archive() times.Archive {
+	return times.Archive{Dir: filepath.Join(libdir, TIMES_DIR)}
 }
 
-func copy_file(src, dst string) {
-	r, err := os.Open(src)
-	if err != nil {
-		return // ignore errors?
+// editorials_path returns the name of the file holding the pending editorials.
+func editorials_path() string {
+	return filepath.Join(libdir, TIMES_DIR, "editorials.json")
+}
+
+// load_editorials returns the editorials waiting for the next issue.
+// A missing file means there are none.
+func load_editorials() ([]*times.Article, error) {
+	data, err := os.ReadFile(editorials_path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var list []*times.Article
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%s: %w", editorials_path(), err)
+	}
+	return list, nil
+}
+
+// AddEditorial queues an editorial section for the next issue of the Times.
+func AddEditorial(title, author, body string, options ...Option) (err error) {
+	for _, opt := range options {
+		if err := opt(); err != nil {
+			return fmt.Errorf("AddEditorial: %w", err)
+		}
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("AddEditorial: empty editorial")
+	}
+	if err := lock_tag(); err != nil {
+		return fmt.Errorf("AddEditorial: %w", err)
 	}
+	defer unlock_tag()
 
-	w, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
+	list, err := load_editorials()
+	if err != nil {
+		return fmt.Errorf("AddEditorial: %w", err)
+	}
+	list = append(list, &times.Article{
+		Kind:   times.Editorial,
+		Author: author,
+		Title:  title,
+		Body:   strings.TrimRight(body, "\n") + "\n",
+	})
+	data, err := json.MarshalIndent(list, "", "  ")
 	if err != nil {
-		return // ignore errors?
+		return fmt.Errorf("AddEditorial: %w", err)
+	} else if err := os.MkdirAll(filepath.Dir(editorials_path()), 0755); err != nil {
+		return fmt.Errorf("AddEditorial: %w", err)
+	} else if err := os.WriteFile(editorials_path(), data, 0666); err != nil {
+		return fmt.Errorf("AddEditorial: %w", err)
 	}
+	return nil
+}
 
-	_, err = io.Copy(w, r)
+// TimesIssue returns the issue of the Times for the turn from the archive.
+// Turn 0 returns the latest issue.
+func TimesIssue(turn int, options ...Option) (*times.Issue, error) {
+	for _, opt := range options {
+		if err := opt(); err != nil {
+			return nil, fmt.Errorf("TimesIssue: %w", err)
+		}
+	}
+	var is *times.Issue
+	var err error
+	if turn == 0 {
+		is, err = times_archive().Latest()
+	} else {
+		is, err = times_archive().Load(turn)
+	}
 	if err != nil {
-		return // ignore errors?
+		return nil, fmt.Errorf("TimesIssue: %w", err)
+	} else if is == nil {
+		return nil, fmt.Errorf("TimesIssue: no issues in the archive")
 	}
+	return is, nil
 }
 
-func copy_file_slow(src io.Reader, dst io.Writer) {
-	if src == nil || dst == nil {
-		return
+// SearchTimes returns the articles in the back issues that contain every word of the query.
+func SearchTimes(query string, options ...Option) ([]times.Match, error) {
+	for _, opt := range options {
+		if err := opt(); err != nil {
+			return nil, fmt.Errorf("SearchTimes: %w", err)
+		}
 	}
-	_, err := io.Copy(dst, src)
+	matches, err := times_archive().Search(query)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("SearchTimes: %w", err)
 	}
+	return matches, nil
 }
 
+// do_times prints this turn's issue of the Times.
+// The issue is written to libdir as the plain text Times that goes out
+// with the reports, plus Markdown and HTML editions. When the database is
+// being saved, the issue is also added to the archive and the editorials
+// that ran in it are cleared.
 func do_times() {
-	times_goal_info()
-	times_masthead()
-	close_times()
-	times_index()
-
-	w, err := os.OpenFile(filepath.Join(libdir, "Times"), os.O_CREATE|os.O_WRONLY, 0666)
-	if err != nil {
-		panic(err)
+	is := &times.Issue{
+		Game:   game_number,
+		Turn:   sysclock.turn,
+		Date:   time.Now().UTC().Format("2006-01-02"),
+		Season: fmt.Sprintf("Season \"%s\", month %d, in the year %d", month_names[oly_month(&sysclock)], oly_month(&sysclock)+1, oly_year(&sysclock)+1),
+		Orders: reply_host,
 	}
+	is.Players = len(loop_pl_regular())
 
-	// for filename in libdir
-	//   if filename like *~ then skip
-	//   if filename not like times* then skip
-	//   append filename to libdir/Times
-	files, err := os.ReadDir(libdir)
+	editorials, err := load_editorials()
 	if err != nil {
-		panic(err)
+		log.Printf("do_times: %+v\n", err)
 	}
-	for _, f := range files {
-		if strings.HasPrefix(f.Name(), "times") && !strings.HasSuffix(f.Name(), "~") {
-			if r, err := os.Open(f.Name()); err != nil {
-				log.Printf("do_times: %+v\n", err)
-			} else if _, err = io.Copy(w, r); err != nil {
+	for _, a := range editorials {
+		a.Turn = sysclock.turn
+		is.Articles = append(is.Articles, a)
+	}
+	is.Articles = append(is.Articles, &times.Article{
+		Turn:  sysclock.turn,
+		Kind:  times.GameInfo,
+		Title: "Game Information",
+		Body:  times_goal_info(),
+	})
+	is.Articles = append(is.Articles, times_articles...)
+
+	// a dry run leaves the archive and the editorials for the real run
+	if save_flag {
+		if err := times_archive().Save(is); err != nil {
+			log.Printf("do_times: %+v\n", err)
+		} else if len(editorials) != 0 {
+			// the editorials ran in this issue
+			if err := os.Remove(editorials_path()); err != nil {
 				log.Printf("do_times: %+v\n", err)
-			} else {
-				_ = r.Close()
 			}
 		}
 	}
-}
 
-func open_times() {
-	var err error
-
-	if press_fp == nil {
-		press_fp, err = os.Create(filepath.Join(libdir, "times_press"))
-		if err != nil {
-			panic(err)
-		}
-	}
-	fprintf(press_fp, "\n%s\n", DASH_LINE)
-	fprintf(press_fp, "                        Player-contributed press\n\n")
-	fprintf(press_fp, "%s\n", DASH_LINE)
-
-	if rumor_fp == nil {
-		rumor_fp, err = os.Create(filepath.Join(libdir, "times_rumor"))
-		if err != nil {
-			panic(err)
+	for _, edition := range []struct {
+		name   string
+		render func(*times.Issue, *bytes.Buffer) error
+	}{
+		{"Times", func(is *times.Issue, b *bytes.Buffer) error { return is.Text(b) }},
+		{"Times.md", func(is *times.Issue, b *bytes.Buffer) error { return is.Markdown(b) }},
+		{"Times.html", func(is *times.Issue, b *bytes.Buffer) error { return is.HTML(b) }},
+	} {
+		var b bytes.Buffer
+		if err := edition.render(is, &b); err != nil {
+			log.Printf("do_times: %s: %+v\n", edition.name, err)
+		} else if err := os.WriteFile(filepath.Join(libdir, edition.name), b.Bytes(), 0666); err != nil {
+			log.Printf("do_times: %+v\n", err)
 		}
 	}
-
-	fprintf(rumor_fp, "                                 Rumors\n\n")
-	fprintf(rumor_fp, "%s\n", DASH_LINE)
 }
 
-// this generates the section of the Times that covers how the various game goals are doing.
-func times_goal_info() {
-	fp, err := fopen(filepath.Join(libdir, "times_info"), "w")
-	if err != nil {
-		panic(err)
-	}
+// open_times starts collecting the press and rumors for a new issue.
+func open_times() {
+	times_articles = nil
+}
 
-	fprintf(fp, "\nGame Information\n")
-	fprintf(fp, "================\n\n")
+// times_goal_info generates the section of the Times that covers how the various game goals are doing.
+func times_goal_info() string {
+	var fp strings.Builder
 
-	fprintf(fp, "  Nations Summary\n")
-	fprintf(fp, "  ---------------\n\n")
+	fmt.Fprintf(&fp, "  Nations Summary\n")
+	fmt.Fprintf(&fp, "  ---------------\n\n")
 
 	calculate_nation_nps()
 	for _, i := range loop_kind(T_nation) {
-		fprintf(fp, "    %s:\n", rp_nation(i).name)
-		fprintf(fp, "        * Next player joining starts with %d NPs.\n",
+		fmt.Fprintf(&fp, "    %s:\n", rp_nation(i).name)
+		fmt.Fprintf(&fp, "        * Next player joining starts with %d NPs.\n",
 			starting_noble_points(i))
-		fprintf(fp, "        * Next player joining starts with %d gold.\n", starting_gold(i))
-		fprintf(fp, "        * Players: %d.\n", rp_nation(i).players)
-		fprintf(fp, "        * Nobles (and controlled units): %d.\n", rp_nation(i).nobles)
-		fprintf(fp, "        * Noble points: %d.\n", rp_nation(i).nps)
+		fmt.Fprintf(&fp, "        * Next player joining starts with %d gold.\n", starting_gold(i))
+		fmt.Fprintf(&fp, "        * Players: %d.\n", rp_nation(i).players)
+		fmt.Fprintf(&fp, "        * Nobles (and controlled units): %d.\n", rp_nation(i).nobles)
+		fmt.Fprintf(&fp, "        * Noble points: %d.\n", rp_nation(i).nps)
 
 		total1 := 0
 		for _, j := range loop_province() {
@@ -161,59 +233,59 @@ func times_goal_info() {
 				total1++
 			}
 		}
-		fprintf(fp, "        * Provinces controlled: %d.\n", total1)
+		fmt.Fprintf(&fp, "        * Provinces controlled: %d.\n", total1)
 		total1 = 0
 		for _, j := range loop_city() {
 			if nation(player_controls_loc(j)) == i {
 				total1++
 			}
 		}
-		fprintf(fp, "        * Cities controlled: %d.\n", total1)
+		fmt.Fprintf(&fp, "        * Cities controlled: %d.\n", total1)
 		total1 = 0
 		for _, j := range loop_castle() {
 			if nation(player_controls_loc(j)) == i {
 				total1++
 			}
 		}
-		fprintf(fp, "        * Castles controlled: %d.\n", total1)
+		fmt.Fprintf(&fp, "        * Castles controlled: %d.\n", total1)
 	}
 
 	if !options.open_ended {
 		for _, i := range loop_nation() {
 			if rp_nation(i).win == 1 {
-				fprintf(fp, "\n  *********************************************************\n")
-				fprintf(fp, "      The %s will win at the end of next turn if\n", rp_nation(i).name)
-				fprintf(fp, "      they maintain the win conditions.\n\n")
-				fprintf(fp, "  *********************************************************\n")
+				fmt.Fprintf(&fp, "\n  *********************************************************\n")
+				fmt.Fprintf(&fp, "      The %s will win at the end of next turn if\n", rp_nation(i).name)
+				fmt.Fprintf(&fp, "      they maintain the win conditions.\n\n")
+				fmt.Fprintf(&fp, "  *********************************************************\n")
 			} else if rp_nation(i).win == 2 {
-				fprintf(fp, "  ***********************************************\n")
-				fprintf(fp, "  ***********************************************\n")
-				fprintf(fp, "    Congratulations to the %s!  They have\n", rp_nation(i).name)
-				fprintf(fp, "    conquered all enemies and have become the most\n")
-				fprintf(fp, "    powerful nation in the history of Olympia!\n")
-				fprintf(fp, "    Final NP Statistics:\n")
-				fprintf(fp, "       %s: %d NPs.\n", rp_nation(i).name, rp_nation(i).nps)
+				fmt.Fprintf(&fp, "  ***********************************************\n")
+				fmt.Fprintf(&fp, "  ***********************************************\n")
+				fmt.Fprintf(&fp, "    Congratulations to the %s!  They have\n", rp_nation(i).name)
+				fmt.Fprintf(&fp, "    conquered all enemies and have become the most\n")
+				fmt.Fprintf(&fp, "    powerful nation in the history of Olympia!\n")
+				fmt.Fprintf(&fp, "    Final NP Statistics:\n")
+				fmt.Fprintf(&fp, "       %s: %d NPs.\n", rp_nation(i).name, rp_nation(i).nps)
 				for _, j := range loop_nation() {
 					if j != i {
-						fprintf(fp, "       %s: %d NPs.\n", rp_nation(j).name,
+						fmt.Fprintf(&fp, "       %s: %d NPs.\n", rp_nation(j).name,
 							rp_nation(j).nps)
 					}
 				}
-				fprintf(fp, "\n    Winning Players:\n")
+				fmt.Fprintf(&fp, "\n    Winning Players:\n")
 				for _, j := range loop_pl_regular() {
 					if nation(j) == i {
-						fprintf(fp, "      %s\n", rp_player(j).FullName)
+						fmt.Fprintf(&fp, "      %s\n", rp_player(j).FullName)
 					}
 				}
-				fprintf(fp, "  ***********************************************\n")
-				fprintf(fp, "  ***********************************************\n")
+				fmt.Fprintf(&fp, "  ***********************************************\n")
+				fmt.Fprintf(&fp, "  ***********************************************\n")
 			}
 		}
 	}
 
-	if options.mp_antipathy {
-		fprintf(fp, "\n  Staff of the Sun Summary\n")
-		fprintf(fp, "  ------------------------\n\n")
+	if game_rules.MPAntipathy {
+		fmt.Fprintf(&fp, "\n  Staff of the Sun Summary\n")
+		fmt.Fprintf(&fp, "  ------------------------\n\n")
 
 		/*
 		 *  How many pieces are out there?
@@ -235,51 +307,48 @@ func times_goal_info() {
 		}
 
 		if priests == 0 && mus == 0 && others == 0 {
-			fprintf(fp, "    * No pieces of the Staff of the Sun have been found.\n")
+			fmt.Fprintf(&fp, "    * No pieces of the Staff of the Sun have been found.\n")
 		} else {
 			if others > 0 {
-				fprintf(fp, "  * %s pieces of the Staff of the Sun are at large.\n", cap_(nice_num(others)))
+				fmt.Fprintf(&fp, "  * %s pieces of the Staff of the Sun are at large.\n", cap_(nice_num(others)))
 				for _, i := range loop_subkind(sub_special_staff) {
 					if kind(item_unique(i)) == T_char && is_magician(item_unique(i)) && is_priest(item_unique(i)) == FALSE {
-						fprintf(fp, "    * One piece is held somewhere on %s.\n", just_name(region(item_unique(i))))
+						fmt.Fprintf(&fp, "    * One piece is held somewhere on %s.\n", just_name(region(item_unique(i))))
 					}
 				}
 			}
 			if priests > 0 {
 				if priests == 1 {
-					fprintf(fp, "  * %s pieces of the Staff of the Sun is held by priests.\n", nice_num(priests))
+					fmt.Fprintf(&fp, "  * %s pieces of the Staff of the Sun is held by priests.\n", nice_num(priests))
 				} else {
-					fprintf(fp, "  * %s pieces of the Staff of the Sun are held by priests.\n", nice_num(priests))
+					fmt.Fprintf(&fp, "  * %s pieces of the Staff of the Sun are held by priests.\n", nice_num(priests))
 				}
 				for _, i := range loop_subkind(sub_special_staff) {
 					if kind(item_unique(i)) == T_char && is_priest(item_unique(i)) != FALSE {
-						fprintf(fp, "    * One piece is held on %s.\n", just_name(region(item_unique(i))))
+						fmt.Fprintf(&fp, "    * One piece is held on %s.\n", just_name(region(item_unique(i))))
 					}
 				}
 			}
 			if mus > 0 {
 				if mus == 1 {
-					fprintf(fp, "  * %s piece of the Staff of the Sun is held by magicians.\n", nice_num(mus))
+					fmt.Fprintf(&fp, "  * %s piece of the Staff of the Sun is held by magicians.\n", nice_num(mus))
 				} else {
-					fprintf(fp, "  * %s pieces of the Staff of the Sun are held by magicians.\n", nice_num(mus))
+					fmt.Fprintf(&fp, "  * %s pieces of the Staff of the Sun are held by magicians.\n", nice_num(mus))
 				}
 				for _, i := range loop_subkind(sub_special_staff) {
 					if kind(item_unique(i)) == T_char && is_magician(item_unique(i)) {
-						fprintf(fp, "    * One piece is held on %s.\n", just_name(region(item_unique(i))))
+						fmt.Fprintf(&fp, "    * One piece is held on %s.\n", just_name(region(item_unique(i))))
 					}
 				}
 			}
 		}
 	}
 
-	fprintf(fp, "\n                                *  *  *\n\n")
-	fprintf(fp, "                                *  *  *\n\n")
-
-	fp = fclose(fp)
+	return fp.String()
 }
 
 func times_credit(c *command) bool {
-	if options.times_pay == FALSE {
+	if game_rules.TimesPay == FALSE {
 		return true
 	}
 
@@ -290,71 +359,30 @@ func times_credit(c *command) bool {
 	}
 
 	p_player(pl).timesPaid = TRUE
-	wout(pl, "The Times adds %s to your CLAIM.", gold_s(options.times_pay))
-	gen_item(pl, item_gold, options.times_pay)
-	gold_times += options.times_pay
+	wout(pl, "The Times adds %s to your CLAIM.", gold_s(game_rules.TimesPay))
+	gen_item(pl, item_gold, game_rules.TimesPay)
+	gold_times += game_rules.TimesPay
 
 	return true
 }
 
-func times_index() {
-	/*
-	 *  Open the output file.
-	 *
-	 */
-	fp, err := fopen(filepath.Join(libdir, "index_times_middle"), "w")
-	if err != nil {
-		panic(err)
-	}
-	/*
-	 *  Put in the list of back issues.
-	 *
-	 */
-	for i := sysclock.turn - 1; i > 0; i-- {
-		fprintf(fp, "<A HREF=\"%02d.html\">%02d</A>\n", i, i)
-	}
-	fclose(fp)
-
-}
-
-func times_masthead() {
-	fp, err := os.Create(filepath.Join(libdir, "times_0"))
-	if err != nil {
-		panic(err)
-	}
-
-	now := time.Now().UTC()
-	date := now.Format("2006 01 02")
-
-	for _, i := range loop_kind(T_player) {
-		if subkind(i) == sub_pl_regular {
-			nplayers++
+// times_post adds a player's press or rumor to this turn's issue.
+// Lines that look like the separator between articles are quoted.
+func times_post(kind times.Kind, author string, l [][]byte) {
+	var b strings.Builder
+	for i := 0; i < len(l); i++ {
+		if bytes.HasPrefix(l[i], []byte("===-")) {
+			fmt.Fprintf(&b, "> %s\n", l[i])
+		} else {
+			fmt.Fprintf(&b, "%s\n", l[i])
 		}
 	}
-
-	turn_s := fmt.Sprintf("Turn %d  %d Players", sysclock.turn, nplayers)
-
-	month := oly_month(&sysclock)
-	year := oly_year(&sysclock)
-
-	issue_s := fmt.Sprintf("Game %d, Season \"%s\", month %d, in the year %d.", game_number, month_names[month], month+1, year+1)
-
-	fprintf(fp, "From: %s\n", from_host)
-	fprintf(fp, "Subject: The Gods Speak (Game %d, Issue %d)\n", game_number, sysclock.turn)
-	/* 	fprintf(fp, "To: UPDATE email address here\n\n",game_number); */
-	fprintf(fp, "\n   +----------------------------------------------------------------------+\n")
-	fprintf(fp, "   | The Gods Speak %53s |\n", date)
-	fprintf(fp, "   | %-68s |\n", issue_s)
-	fprintf(fp, "   |                                                                      |\n")
-	fprintf(fp, "   | %-40s http://olytag.com           |\n", turn_s)
-	fprintf(fp, "   |                    Send orders to: tagtest (at) olytag (dot) com     |\n", game_number) /* UPDATE */
-	fprintf(fp, "   +----------------------------------------------------------------------+\n\n")
-	fprintf(fp, "           Questions, comments, to play:  moderator (at) olytag (dot) com\n\n") /*UPDATE*/
-	fprintf(fp, "                             Olympia PBEM\n\n")
-	fprintf(fp, "                                *  *  *\n\n")
-	fprintf(fp, "                                *  *  *\n\n")
-
-	fp = fclose(fp)
+	times_articles = append(times_articles, &times.Article{
+		Turn:   sysclock.turn,
+		Kind:   kind,
+		Author: author,
+		Body:   b.String(),
+	})
 }
 
 func v_press(c *command) int {
@@ -369,27 +397,15 @@ func v_press(c *command) int {
 		return FALSE
 	}
 
-	for i := 0; i < len(l); i++ {
-		if bytes.HasPrefix(l[i], []byte("===-")) {
-			fprintf(press_fp, "> %s\n", l[i])
-		} else {
-			fprintf(press_fp, "%s\n", l[i])
-		}
-	}
-
 	/*
 	 *  Turn off tags in the Times?
 	 *
 	 */
 	tags_off()
-	attrib := fmt.Sprintf("-- %s", box_name(player(c.who)))
+	attrib := box_name(player(c.who))
 	tags_on()
 	attrib = strings.ReplaceAll(attrib, "~", " ")
-	fprintf(press_fp, "\n%55s\n\n", attrib)
-
-	fprintf(press_fp, DASH_LINE)
-	fprintf(press_fp, "\n")
-	fflush(press_fp)
+	times_post(times.Press, attrib, l)
 
 	wout(c.who, "Press posted.")
 	times_credit(c)
@@ -408,18 +424,7 @@ func v_rumor(c *command) int {
 		return FALSE
 	}
 
-	for i := 0; i < len(l); i++ {
-		if bytes.HasPrefix(l[i], []byte("===-")) {
-			fprintf(rumor_fp, "> %s\n", l[i])
-		} else {
-			fprintf(rumor_fp, "%s\n", l[i])
-		}
-	}
-
-	fprintf(rumor_fp, "\n")
-	fprintf(rumor_fp, DASH_LINE)
-	fprintf(rumor_fp, "\n")
-	fflush(rumor_fp)
+	times_post(times.Rumor, "", l)
 
 	wout(c.who, "Rumor posted.")
 	times_credit(c)
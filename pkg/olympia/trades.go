@@ -21,18 +21,18 @@ package olympia
 
 type TradeList []*Trade
 type Trade struct {
-	Id        int  `json:"id,omitempty"`
-	Kind      int  `json:"kind,omitempty"` // BUY or SELL
-	Item      int  `json:"item,omitempty"`
-	Qty       int  `json:"qty,omitempty"`
-	Cloak     bool `json:"cloak,omitempty"` // don't reveal identity of trader
-	Cost      int  `json:"cost,omitempty"`
-	Counter   int  `json:"counter,omitempty"`    // Counter to age and lose untraded goods
-	HaveLeft  int  `json:"have-left,omitempty"`  // amount remaining
-	MonthProd int  `json:"month-prod,omitempty"` // month city produces item
-	OldQty    int  `json:"old-qty,omitempty"`    // qty at beginning of month, for trade goods
-	sort      int  // temp key for sorting -- not saved
-	who       int  // redundant -- not saved
+	Id        int `json:"id,omitempty"`
+	Kind      int `json:"kind,omitempty"` // BUY or SELL
+	Item      int `json:"item,omitempty"`
+	Qty       int `json:"qty,omitempty"`
+	Cloak     int `json:"cloak,omitempty"` // don't reveal identity of trader
+	Cost      int `json:"cost,omitempty"`
+	Counter   int `json:"counter,omitempty"`    // Counter to age and lose untraded goods
+	HaveLeft  int `json:"have-left,omitempty"`  // amount remaining
+	MonthProd int `json:"month-prod,omitempty"` // month city produces item
+	OldQty    int `json:"old-qty,omitempty"`    // qty at beginning of month, for trade goods
+	sort      int // temp key for sorting -- not saved
+	who       int // redundant -- not saved
 }
 
 type trade_l []*trade
@@ -47,6 +47,25 @@ func (l trade_l) rem_value(e *trade) trade_l {
 	return cp
 }
 
+// toBox converts the list back to pending trades for the given box.
+func (l TradeList) toBox(who int) (tl trade_l) {
+	for _, e := range l {
+		tl = append(tl, &trade{
+			kind:       e.Kind,
+			item:       e.Item,
+			qty:        e.Qty,
+			cost:       e.Cost,
+			cloak:      e.Cloak,
+			have_left:  e.HaveLeft,
+			month_prod: e.MonthProd,
+			who:        who,
+			old_qty:    e.OldQty,
+			counter:    e.Counter,
+		})
+	}
+	return tl
+}
+
 func (l trade_l) ToTradeList() (tl TradeList) {
 	for _, e := range l {
 		if !valid_box(e.item) {
@@ -64,7 +83,7 @@ func (l trade_l) ToTradeList() (tl TradeList) {
 			Item:      e.item,
 			Qty:       e.qty,
 			Cost:      e.cost,
-			Cloak:     e.cloak != FALSE,
+			Cloak:     e.cloak,
 			HaveLeft:  e.have_left,
 			MonthProd: e.month_prod,
 			OldQty:    e.old_qty,
@@ -162,7 +162,7 @@ func make_tower_guild(where, school int) {
 
 	change_box_subkind(where, sub_guild)
 	p.guild = school
-	if options.guild_teaching {
+	if game_rules.GuildTeaching {
 		p.teaches = append(p.teaches, school)
 	}
 
@@ -365,6 +365,24 @@ func update_small_city_trades(where int) {
 	update_city_trade(where, CONSUME, item_stone, amount, rp_item(item_stone).base_price, 0)
 }
 
+/*
+ *  When the GM turns full markets off, cities stop buying the common
+ *  goods.  Drop any demand left from a turn when they did; what the
+ *  city itself produces is kept.
+ *
+ */
+func delete_common_trades(where int) {
+	for _, item := range []int{item_lumber, item_stone, item_wild_horse,
+		item_lana_bark, item_pretus_bones, item_mallorn_wood, item_yew,
+		item_farrenstone, item_spiny_root, item_avinia_leaf} {
+		for _, t := range loop_trade(where) {
+			if t.item == item && (t.kind == CONSUME || t.kind == BUY) {
+				bx[where].trades = bx[where].trades.rem_value(t)
+			}
+		}
+	}
+}
+
 /*
  *  Override causes cities which only produce a good once per year
  *  to produce it now anyway.  This is useful for epoch city trade
@@ -442,7 +460,7 @@ func update_market(where int) {
 		 * Calculate what the change in price, if any, will be.
 		 *
 		 */
-		change = (t.cost * (9 + rnd(1, 11))) / 100
+		change = (t.cost * rnd(game_rules.Markets.MinChange, game_rules.Markets.MaxChange)) / 100
 		if change < 1 {
 			change = 1
 		}
@@ -511,7 +529,7 @@ func update_market(where int) {
 			                     *  Hmm.
 		*/
 		if (rp_item(t.item).trade_good != FALSE || item_unique(t.item) != FALSE) &&
-			t.counter > options.market_age {
+			t.counter > game_rules.Markets.Age {
 			wout(gm_player, "Deleting good %s from %s.",
 				box_name(t.item),
 				box_name(where))
@@ -536,13 +554,13 @@ func update_market(where int) {
 		 */
 		bp = rp_item(t.item).base_price
 
-		if bp != FALSE && t.cost > bp*2 {
-			t.cost = bp * 2
+		if max_cost := bp * game_rules.Markets.MaxPrice / 100; bp != FALSE && t.cost > max_cost {
+			t.cost = max_cost
 			newTrade.cost = t.cost
 		}
 
-		if bp != FALSE && t.cost < bp/2 {
-			t.cost = bp / 2
+		if min_cost := bp * game_rules.Markets.MinPrice / 100; bp != FALSE && t.cost < min_cost {
+			t.cost = min_cost
 			newTrade.cost = t.cost
 		}
 
@@ -551,9 +569,9 @@ func update_market(where int) {
 			newTrade.cost = 1
 		}
 
-		if bp == FALSE && t.cost > 50 {
-			t.cost = 50
-			newTrade.cost = 50
+		if bp == FALSE && t.cost > game_rules.Markets.PriceCap {
+			t.cost = game_rules.Markets.PriceCap
+			newTrade.cost = t.cost
 		}
 
 		/*
@@ -733,23 +751,27 @@ func update_markets() {
 	 *
 	 */
 	for _, where = range loop_city() {
-		/*
-		 *  City-size specific trades.
-		 *
-		 */
-		if has_item(where, item_peasant) > 9500 {
-			update_big_city_trades(where)
+		if game_rules.FullMarkets {
+			/*
+			 *  City-size specific trades.
+			 *
+			 */
+			if has_item(where, item_peasant) > 9500 {
+				update_big_city_trades(where)
+			} else {
+				update_small_city_trades(where)
+			}
+
+			/*
+			 *  Everyone buys wild horses.
+			 *
+			 */
+			update_city_trade(where, CONSUME, item_wild_horse, rnd(1, 10),
+				rp_item(item_wild_horse).base_price, 0)
 		} else {
-			update_small_city_trades(where)
+			delete_common_trades(where)
 		}
 
-		/*
-		 *  Everyone buys wild horses.
-		 *
-		 */
-		update_city_trade(where, CONSUME, item_wild_horse, rnd(1, 10),
-			rp_item(item_wild_horse).base_price, 0)
-
 		/*
 		 *  Update the markets.
 		 *
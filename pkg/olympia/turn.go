@@ -0,0 +1,277 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// EatOrders loads the database and processes the orders waiting in the spool.
+// Unless WithEatOnce is set, it polls the spool until a stop file shows up.
+func EatOrders(options ...Option) (err error) {
+	defer recover_turn("EatOrders", &err)
+	defer unlock_tag()
+
+	if err := start_turn(options...); err != nil {
+		return fmt.Errorf("EatOrders: %w", err)
+	}
+	immediate = FALSE
+
+	if err := eat_loop(mail_now, eat_once); err != nil {
+		return fmt.Errorf("EatOrders: %w", err)
+	}
+
+	return nil
+}
+
+// RunTurn runs the whole turn pipeline: load_db, eat (optional),
+// process_orders, reports, and save_db (if WithSaveDB is set).
+// When the database is saved, the pre-turn state is kept as a snapshot
+// that RollbackTurn can restore.
+func RunTurn(options ...Option) (err error) {
+	defer recover_turn("RunTurn", &err)
+	defer unlock_tag()
+
+	if err := start_turn(options...); err != nil {
+		return fmt.Errorf("RunTurn: %w", err)
+	}
+	immediate = FALSE
+
+	if unspool_first_flag {
+		if err := mkdir(filepath.Join(libdir, "orders")); err != nil {
+			return fmt.Errorf("RunTurn: %w", err)
+		} else if err := mkdir(filepath.Join(libdir, "spool")); err != nil {
+			return fmt.Errorf("RunTurn: %w", err)
+		}
+		read_spool(mail_now)
+	}
+
+	// archive the pre-turn state so that the turn can be rolled back and re-run.
+	var snapshot string
+	if save_flag {
+		if snapshot, err = begin_snapshot(); err != nil {
+			return fmt.Errorf("RunTurn: %w", err)
+		}
+		defer func() {
+			abandon_snapshot(snapshot)
+		}()
+	}
+
+	run_turn()
+
+	if err := finish_turn(true); err != nil {
+		return fmt.Errorf("RunTurn: %w", err)
+	}
+
+	if snapshot != "" {
+		if err := commit_snapshot(snapshot); err != nil {
+			return fmt.Errorf("RunTurn: %w", err)
+		}
+		snapshot = ""
+		if err := record_turn_digest(sysclock.turn); err != nil {
+			return fmt.Errorf("RunTurn: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// AddPlayers loads the database and adds any new players who have joined.
+func AddPlayers(options ...Option) (err error) {
+	defer recover_turn("AddPlayers", &err)
+	defer unlock_tag()
+
+	if err := start_turn(options...); err != nil {
+		return fmt.Errorf("AddPlayers: %w", err)
+	}
+	immediate = FALSE
+
+	if mail_now {
+		new_player_top(TRUE)
+	} else {
+		new_player_top(FALSE)
+	}
+	// the initial reports have been mailed, don't send the turn reports.
+	mail_now = false
+
+	if err := finish_turn(false); err != nil {
+		return fmt.Errorf("AddPlayers: %w", err)
+	}
+
+	return nil
+}
+
+// MailReports loads the database and mails the reports for the current turn.
+func MailReports(options ...Option) (err error) {
+	defer recover_turn("MailReports", &err)
+	defer unlock_tag()
+
+	if err := start_turn(options...); err != nil {
+		return fmt.Errorf("MailReports: %w", err)
+	}
+
+	mail_reports()
+
+	return nil
+}
+
+// SaveDB loads the database, checks it, and writes it back out.
+func SaveDB(options ...Option) (err error) {
+	defer recover_turn("SaveDB", &err)
+	defer unlock_tag()
+
+	if err := start_turn(options...); err != nil {
+		return fmt.Errorf("SaveDB: %w", err)
+	} else if err := check_db(); err != nil {
+		return fmt.Errorf("SaveDB: %w", err)
+	} else if err := save_db(); err != nil {
+		return fmt.Errorf("SaveDB: %w", err)
+	}
+
+	return nil
+}
+
+// start_turn applies the options, initializes the engine and loads the database.
+func start_turn(options ...Option) error {
+	for _, option := range options {
+		if err := option(); err != nil {
+			return err
+		}
+	}
+
+	if err := call_init_routines(); err != nil {
+		return err
+	}
+
+	// lock up; prevents multiple TAGs running simultaneously.
+	if err := lock_tag(); err != nil {
+		return err
+	}
+
+	if err := load_db(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// run_turn processes the orders for the month and generates the reports.
+func run_turn() {
+	open_logfile()
+	open_times()
+	incidents = nil
+	battles_this_turn = nil
+
+	show_day = true
+	pre_month()
+	process_orders()
+	post_month()
+	show_day = false
+	record_visits()
+	if !options.free {
+		charge_account()
+	}
+
+	determine_output_order()
+	turn_end_loc_reports()
+	list_order_templates()
+	player_ent_info()
+	character_report()
+
+	player_banner()
+	if !options.free {
+		report_account()
+	}
+	summary_report()
+	player_report()
+
+	scan_char_skill_lore()
+	show_lore_sheets()
+	if !options.open_ended {
+		check_win_conditions()
+	}
+	gm_report(gm_player)
+	gm_show_all_skills(skill_player, true)
+	if !inhibit_add_flag {
+		add_new_players()
+	}
+	gen_include_section() /* must be last */
+	close_logfile()
+	log_incidents()
+
+	write_player_list()
+	write_nations_lists()
+	write_email()
+	write_totimes()
+	write_forwards()
+	write_factions()
+	write_atlases()
+	write_battles()
+	write_turn_reports()
+}
+
+// finish_turn runs the immediate commands (if requested), checks and saves
+// the database, and publishes the Times and reports.
+// turnRan should be true only when run_turn has been called.
+func finish_turn(turnRan bool) error {
+	if immed_after != FALSE {
+		immediate = TRUE
+
+		open_logfile()
+		immed_commands()
+		close_logfile()
+	}
+
+	if err := check_db(); err != nil {
+		return err
+	}
+
+	if save_flag {
+		if err := save_db(); err != nil {
+			return err
+		}
+		if turnRan {
+			if err := save_logdir(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if turnRan {
+		do_times()
+	}
+
+	if mail_now {
+		mail_reports()
+	}
+
+	stage("")
+
+	return nil
+}
+
+// recover_turn converts a panic raised by the engine into an error
+// so that callers get a chance to report it and exit cleanly.
+func recover_turn(name string, err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%s: panic: %v", name, r)
+	}
+}
@@ -0,0 +1,312 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/report"
+	"github.com/mdhender/golympia/pkg/turnreport"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/*
+ *  The JSON turn report is built from the database after the text
+ *  report is done, so it shows what the text report shows and no more:
+ *  the visibility checks mirror show_loc() and friends.  Messages are
+ *  taken from the faction's log; only lines written while orders ran
+ *  carry a day, which keeps the end-of-turn status reports out.
+ */
+
+var loyalty_kind_s = map[int]string{
+	0:            "unsworn",
+	LOY_contract: "contract",
+	LOY_oath:     "oath",
+	LOY_fear:     "fear",
+	LOY_npc:      "npc",
+	LOY_summon:   "summon",
+}
+
+// write_turn_reports writes each faction's JSON report into the log
+// directory, next to the text report, so that it is saved with the turn.
+func write_turn_reports() {
+	stage("write_turn_reports()")
+
+	for _, pl := range loop_player() {
+		if subkind(pl) != sub_pl_regular {
+			continue
+		}
+		r := turn_report(pl)
+		if err := turn_report_messages(pl, r); err != nil {
+			log.Printf("write_turn_reports: %s: %v\n", box_code_less(pl), err)
+		}
+		if err := turnreport.Write(filepath.Join(libdir, "log", fmt.Sprintf("%d.json", pl)), r); err != nil {
+			log.Printf("write_turn_reports: %s: %v\n", box_code_less(pl), err)
+		}
+	}
+}
+
+// turn_report returns what the faction knows at the end of the turn.
+func turn_report(pl int) *turnreport.Report {
+	r := &turnreport.Report{
+		Schema: turnreport.SchemaVersion,
+		Game:   game_number,
+		Turn:   sysclock.turn,
+		Faction: &turnreport.Faction{
+			Id:          box_code_less(pl),
+			Name:        just_name(pl),
+			NoblePoints: player_np(pl),
+			Items:       turn_report_items(pl),
+		},
+		Units:     []*turnreport.Unit{},
+		Locations: []*turnreport.Location{},
+		Messages:  []*turnreport.Message{},
+	}
+
+	for _, who := range loop_units(pl) {
+		if kind(who) == T_char {
+			r.Units = append(r.Units, turn_report_unit(pl, who))
+		}
+	}
+
+	for _, where := range known_sparse_loop(p_player(pl).locs) {
+		if valid_box(where) && loc_depth(where) >= LOC_province {
+			r.Locations = append(r.Locations, turn_report_loc(pl, where))
+		}
+	}
+
+	return r
+}
+
+func turn_report_unit(pl, who int) *turnreport.Unit {
+	u := &turnreport.Unit{
+		Id:       box_code_less(who),
+		Name:     just_name(who),
+		Location: box_code_less(subloc(who)),
+		Behind:   char_behind(who),
+		Health:   char_health(who),
+		Sick:     char_sick(who) != FALSE,
+		Prisoner: is_prisoner(who),
+		Loyalty:  &turnreport.Loyalty{Kind: loyalty_kind_s[loyal_kind(who)], Rate: loyal_rate(who)},
+		Items:    turn_report_items(who),
+		Skills:   []*turnreport.Skill{},
+		Orders:   []string{},
+	}
+	if where := province(who); where != 0 {
+		u.Province = box_code_less(where)
+	}
+	if parent := stack_parent(who); parent != 0 {
+		u.StackedUnder = box_code_less(parent)
+	}
+
+	if p := rp_char(who); p != nil {
+		for _, e := range p.skills {
+			sk := &turnreport.Skill{
+				Id:    box_code_less(e.skill),
+				Name:  just_name(e.skill),
+				Known: e.know == SKILL_know,
+			}
+			if sk.Known {
+				sk.Level = e.experience
+			} else {
+				sk.DaysStudied = e.days_studied / TOUGH_NUM
+				sk.DaysNeeded = learn_time(e.skill)
+			}
+			u.Skills = append(u.Skills, sk)
+		}
+	}
+
+	if c := rp_command(who); c != nil {
+		switch c.state {
+		case RUN:
+			u.Executing = &turnreport.Executing{Order: c.line, DaysLeft: c.wait}
+			if c.wait < 0 {
+				u.Executing.DaysLeft = -1
+			}
+		case LOAD: /* loaded, but not started yet */
+			u.Orders = append(u.Orders, c.line)
+		}
+	}
+	if l := rp_order_head(pl, who); l != nil {
+		for _, s := range l.l {
+			u.Orders = append(u.Orders, string(eat_leading_trailing_whitespace(s)))
+		}
+	}
+
+	return u
+}
+
+func turn_report_items(who int) []*turnreport.Item {
+	l := []*turnreport.Item{}
+	for _, e := range loop_inventory(who) {
+		l = append(l, &turnreport.Item{
+			Id:     box_code_less(e.item),
+			Name:   just_name(e.item),
+			Qty:    e.qty,
+			Weight: item_weight(e.item) * e.qty,
+		})
+	}
+	return l
+}
+
+func turn_report_loc(pl, where int) *turnreport.Location {
+	l := &turnreport.Location{
+		Id:          box_code_less(where),
+		Name:        just_name(where),
+		Kind:        subkind_s[subkind(where)],
+		Region:      name(region(where)),
+		Hidden:      loc_hidden(where),
+		Exits:       []*turnreport.Exit{},
+		Occupants:   []*turnreport.Occupant{},
+		LastVisited: p_player(pl).Visited[where],
+	}
+	if loc_depth(where) > LOC_province || is_ship_either(where) {
+		l.Inside = box_code_less(loc(where))
+		if prov := province(where); prov != 0 && prov != where {
+			l.Province = box_code_less(prov)
+		}
+	}
+
+	/* as list_exits(): sublocations are shown as places, not routes */
+	for _, v := range exits_from_loc(pl, where) {
+		if v.hidden != FALSE && see_all(pl) == FALSE {
+			continue
+		} else if v.road == 0 && v.direction == DIR_IN && see_all(pl) != 2 {
+			continue
+		}
+		e := &turnreport.Exit{
+			To:         box_code_less(v.destination),
+			ToName:     just_name(v.destination),
+			ToKind:     subkind_s[subkind(v.destination)],
+			Days:       v.distance,
+			Impassable: v.impassable != FALSE || (v.road != FALSE && v.in_transit != FALSE),
+			Hidden:     v.dest_hidden != FALSE,
+			Water:      v.water != FALSE,
+		}
+		if v.road != FALSE {
+			e.Road, e.RoadName = box_code_less(v.road), just_name(v.road)
+		} else if v.direction > 0 {
+			e.Direction = full_dir_s[v.direction]
+		}
+		l.Exits = append(l.Exits, e)
+	}
+
+	if subkind(where) == sub_city || is_guild(where) == sk_trading {
+		l.Market = turn_report_market(where)
+	}
+
+	/* as show_chars_here() */
+	if loc_depth(where) == LOC_province &&
+		(weather_here(where, sub_fog) != FALSE || weather_here(where, sub_mist) != FALSE) &&
+		is_priest(pl) != sk_domingo {
+		l.Concealed = true
+		return l
+	}
+	for _, i := range loop_here(where) {
+		if kind(i) != T_char || char_really_hidden(i) {
+			continue
+		}
+		l.Occupants = append(l.Occupants, &turnreport.Occupant{
+			Id:   box_code_less(i),
+			Name: just_name(i),
+			Own:  player(i) == pl,
+		})
+		for _, j := range loop_char_here(i) {
+			l.Occupants = append(l.Occupants, &turnreport.Occupant{
+				Id:           box_code_less(j),
+				Name:         just_name(j),
+				StackedUnder: box_code_less(i),
+				Own:          player(j) == pl,
+			})
+		}
+	}
+
+	return l
+}
+
+// turn_report_market lists the trades that market_report() shows.
+func turn_report_market(where int) *turnreport.Market {
+	m := &turnreport.Market{Trades: []*turnreport.Trade{}}
+	if rp_subloc(where) != nil {
+		m.Tax = rp_subloc(where).tax_market
+	}
+	for _, l := range [][]*trade{buyer_list(where, 0), seller_list(where, 0)} {
+		for _, t := range l {
+			if t.cloak >= 2 {
+				continue
+			}
+			qty := reduce_qty(t, t.cost)
+			if qty <= 0 {
+				continue
+			}
+			tr := &turnreport.Trade{
+				Kind:     or_string(t.kind == BUY, "buy", "sell"),
+				Item:     box_code_less(t.item),
+				ItemName: just_name(t.item),
+				Qty:      qty,
+				Price:    t.cost,
+				Weight:   item_weight(t.item),
+			}
+			if t.cloak == FALSE {
+				tr.Who = box_code_less(t.who)
+			}
+			m.Trades = append(m.Trades, tr)
+		}
+	}
+	return m
+}
+
+// turn_report_messages adds the faction's and its units' messages from
+// the faction's log.
+func turn_report_messages(pl int, r *turnreport.Report) error {
+	data, err := os.ReadFile(filepath.Join(libdir, "log", fmt.Sprintf("%d", pl)))
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	rl, err := report.Parse(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	add := func(who int) {
+		for _, line := range rl.Sections[who] {
+			if line.Day < 0 {
+				continue
+			}
+			if text := strings.TrimSpace(line.Plain()); text != "" {
+				r.Messages = append(r.Messages, &turnreport.Message{
+					Unit: box_code_less(who),
+					Day:  line.Day,
+					Text: text,
+				})
+			}
+		}
+	}
+	add(pl)
+	for _, who := range loop_units(pl) {
+		add(who)
+	}
+	return nil
+}
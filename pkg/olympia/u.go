@@ -110,7 +110,7 @@ func survive_fatal(who int) bool {
 		p_char(who).health = 100
 		p_char(who).sick = FALSE
 
-		if options.survive_np && skill_np_req(sk_survive_fatal) != FALSE {
+		if game_rules.SurviveNP && skill_np_req(sk_survive_fatal) != FALSE {
 			wout(who, "Received back %d noble points.", skill_np_req(sk_survive_fatal))
 			add_np(player(who), skill_np_req(sk_survive_fatal))
 		}
@@ -389,6 +389,7 @@ func take_unit_items(from, inherit, how_many int) {
 		}
 
 		move_item(from, to, e.item, qty)
+		battle_booty(from, to, e.item, qty)
 
 		if e.item == item_gold && player(from) != player(to) && player(to) > 1000 {
 			if player(from) < 1000 {
@@ -1814,8 +1815,3 @@ func ship_cap(ship int) int {
 
 	return sc
 }
-
-// prevent multiple TAGS running in same path
-func lock_tag() {
-	log.Printf("lock_tag: not implemented\n")
-}
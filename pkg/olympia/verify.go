@@ -0,0 +1,348 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package olympia
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// VerifyDB loads the library, writes the boxes to a temporary directory
+// in the given format, reads them back, and compares every box field by field,
+// including the fields that only exist in memory (see snapshot_boxes).
+// It returns one line for each attribute that was lost, added, or altered.
+// The library itself is not changed.
+func VerifyDB(format string, options ...Option) (diffs []string, err error) {
+	defer recover_turn("VerifyDB", &err)
+	defer unlock_tag()
+
+	if err := check_db_format(format); err != nil {
+		return nil, fmt.Errorf("VerifyDB: %w", err)
+	} else if err := start_turn(options...); err != nil {
+		return nil, fmt.Errorf("VerifyDB: %w", err)
+	}
+
+	before := snapshot_boxes()
+
+	tmpdir, err := os.MkdirTemp("", "goly-verify-")
+	if err != nil {
+		return nil, fmt.Errorf("VerifyDB: %w", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpdir)
+	}()
+	savedLibdir := libdir
+	libdir = tmpdir
+	defer func() {
+		libdir = savedLibdir
+	}()
+
+	if err := round_trip_boxes(format); err != nil {
+		return nil, fmt.Errorf("VerifyDB: %w", err)
+	}
+
+	return diff_snapshots(before, snapshot_boxes()), nil
+}
+
+// round_trip_boxes writes all the boxes to libdir, clears them,
+// and reads them back in.
+func round_trip_boxes(format string) error {
+	switch format {
+	case DB_JSON:
+		if err := write_json_boxes(); err != nil {
+			return err
+		}
+		clear_boxes()
+		if err := read_json_boxes(); err != nil {
+			return err
+		}
+	case DB_TEXT:
+		if err := mkdir(filepath.Join(libdir, "fact")); err != nil {
+			return err
+		} else if err := write_all_boxes(); err != nil {
+			return err
+		} else if err := write_master(); err != nil {
+			return err
+		}
+		clear_boxes()
+		if err := fast_scan(); err != nil {
+			return err
+		} else if err := read_all_boxes(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clear_boxes removes every box and resets the kind and subkind chains.
+func clear_boxes() {
+	bx = make(map[int]*box)
+	for i := range box_head {
+		box_head[i] = 0
+	}
+	for i := range sub_head {
+		sub_head[i] = 0
+	}
+}
+
+// snapshot_boxes returns every box in memory, walked field by field
+// (including the unexported fields and the entity sub-structures)
+// into generic maps so that it can be compared attribute by attribute.
+// Zero values are left out, so a nil sub-structure and an empty one
+// compare equal.  The fields documented in oly.go as "not saved" are
+// listed in transient_fields and are skipped, and the sets listed in
+// unordered_fields are sorted first.
+func snapshot_boxes() map[int]interface{} {
+	snap := make(map[int]interface{})
+	for _, n := range sorted_box_ids() {
+		snap[n] = snapshot_value(reflect.ValueOf(bx[n]), make(map[uintptr]bool))
+	}
+	return snap
+}
+
+// transient_fields are the struct fields, as "type.field", that the
+// engine rebuilds or resets each turn and never writes to the database.
+var transient_fields = map[string]bool{
+	"Admit.Flag":                 true,
+	"EntityPlayer.cmdCount":      true,
+	"EntityPlayer.deliverLore":   true,
+	"EntityPlayer.locs":          true,
+	"EntityPlayer.npGained":      true,
+	"EntityPlayer.npSpent":       true,
+	"EntityPlayer.output":        true,
+	"EntityPlayer.swearThisTurn": true,
+	"EntityPlayer.timesPaid":     true,
+	"EntityPlayer.weatherSeen":   true,
+	"ItemMagic.one_turn_use":     true,
+	"box.output_order":           true,
+	"box.temp":                   true,
+	"char_magic.ferry_flag":      true,
+	"char_magic.mage_worked":     true,
+	"char_magic.pledged_to_us":   true,
+	"command.debug":              true,
+	"command.fuzzy":              true,
+	"command.wait_parse":         true,
+	"command.who":                true,
+	"entity_char.accept":         true,
+	"entity_char.fresh_hire":     true,
+	"entity_char.melt_me":        true,
+	"entity_char.new_lord":       true,
+	"entity_char.studied":        true,
+	"entity_misc.border_open":    true,
+	"entity_misc.garr_forward":   true,
+	"entity_misc.garr_host":      true,
+	"entity_misc.garr_tax":       true,
+	"entity_misc.garr_watch":     true,
+	"entity_misc.opium_double":   true,
+	"entity_misc.post_txt":       true,
+	"entity_misc.storm_move":     true,
+	"entity_skill.last_use_who":  true,
+	"entity_skill.use_count":     true,
+	"skill_ent.exp_this_month":   true,
+	"trade.sort":                 true,
+	"trade.who":                  true,
+}
+
+// unordered_fields are the sparse sets, as "type.field".  The engine
+// appends to them in any order and sorts them when it loops over them,
+// so they are compared sorted.
+var unordered_fields = map[string]bool{
+	"EntityPlayer.Known":     true,
+	"char_magic.visions":     true,
+	"entity_misc.npc_memory": true,
+}
+
+// snapshot_value converts v into maps, slices, and scalars that
+// diff_values can walk.  It returns nil for zero values.  Functions
+// are skipped, and a pointer already being walked is shown as "<cycle>".
+func snapshot_value(v reflect.Value, seen map[uintptr]bool) interface{} {
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return true
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() != 0 {
+			return v.Int()
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() != 0 {
+			return v.Uint()
+		}
+	case reflect.Float32, reflect.Float64:
+		if v.Float() != 0 {
+			return v.Float()
+		}
+	case reflect.String:
+		if v.String() != "" {
+			return v.String()
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			return snapshot_value(v.Elem(), seen)
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		} else if seen[v.Pointer()] {
+			return "<cycle>"
+		}
+		seen[v.Pointer()] = true
+		defer delete(seen, v.Pointer())
+		return snapshot_value(v.Elem(), seen)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			if v.Len() != 0 {
+				return string(v.Bytes())
+			}
+			return nil
+		}
+		var list []interface{}
+		for i := 0; i < v.Len(); i++ {
+			list = append(list, snapshot_value(v.Index(i), seen))
+		}
+		if len(list) != 0 {
+			return list
+		}
+	case reflect.Map:
+		m := make(map[string]interface{})
+		iter := v.MapRange()
+		for iter.Next() {
+			if val := snapshot_value(iter.Value(), seen); val != nil {
+				m[fmt.Sprintf("%v", snapshot_value(iter.Key(), seen))] = val
+			}
+		}
+		if len(m) != 0 {
+			return m
+		}
+	case reflect.Struct:
+		t := v.Type()
+		m := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if transient_fields[t.Name()+"."+f.Name] {
+				continue
+			}
+			val := snapshot_value(v.Field(i), seen)
+			if list, ok := val.([]interface{}); ok && unordered_fields[t.Name()+"."+f.Name] {
+				sort.Slice(list, func(i, j int) bool {
+					return list[i].(int64) < list[j].(int64)
+				})
+			}
+			if val != nil {
+				m[f.Name] = val
+			}
+		}
+		if len(m) != 0 {
+			return m
+		}
+	}
+	return nil
+}
+
+func diff_snapshots(before, after map[int]interface{}) []string {
+	var ids []int
+	for n := range before {
+		ids = append(ids, n)
+	}
+	for n := range after {
+		if _, ok := before[n]; !ok {
+			ids = append(ids, n)
+		}
+	}
+	sort.Ints(ids)
+
+	var diffs []string
+	for _, n := range ids {
+		a, aok := before[n]
+		b, bok := after[n]
+		if !bok {
+			diffs = append(diffs, fmt.Sprintf("%d: box lost", n))
+		} else if !aok {
+			diffs = append(diffs, fmt.Sprintf("%d: box added", n))
+		} else {
+			diffs = diff_values(strconv.Itoa(n), a, b, diffs)
+		}
+	}
+	return diffs
+}
+
+// diff_values compares two values decoded from json and appends
+// a line for each attribute that differs.
+func diff_values(path string, a, b interface{}, diffs []string) []string {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			var keys []string
+			for k := range av {
+				keys = append(keys, k)
+			}
+			for k := range bv {
+				if _, ok := av[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				ak, aok := av[k]
+				bk, bok := bv[k]
+				if !bok {
+					diffs = append(diffs, fmt.Sprintf("%s.%s: lost %s", path, k, show_value(ak)))
+				} else if !aok {
+					diffs = append(diffs, fmt.Sprintf("%s.%s: added %s", path, k, show_value(bk)))
+				} else {
+					diffs = diff_values(path+"."+k, ak, bk, diffs)
+				}
+			}
+			return diffs
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			for i := 0; i < len(av) || i < len(bv); i++ {
+				p := fmt.Sprintf("%s[%d]", path, i)
+				if i >= len(bv) {
+					diffs = append(diffs, fmt.Sprintf("%s: lost %s", p, show_value(av[i])))
+				} else if i >= len(av) {
+					diffs = append(diffs, fmt.Sprintf("%s: added %s", p, show_value(bv[i])))
+				} else {
+					diffs = diff_values(p, av[i], bv[i], diffs)
+				}
+			}
+			return diffs
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		diffs = append(diffs, fmt.Sprintf("%s: %s -> %s", path, show_value(a), show_value(b)))
+	}
+	return diffs
+}
+
+func show_value(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -20,6 +20,7 @@
 package olympia
 
 import (
+	"bufio"
 	"bytes"
 	"io"
 	"log"
@@ -35,6 +36,7 @@ const LEN = 512 /* generic string max length */
 
 var (
 	line_fd     *os.File
+	line_rd     *bufio.Reader
 	lower_array [256]byte
 )
 
@@ -156,23 +158,26 @@ func fuzzy_transpose(s1, s2 []byte, l1, l2 int) bool {
 // ignores carriage-return and strips newline off end of line.
 // returns nil on end of input.
 func getlin(r io.Reader) []byte {
-	var buf []byte
-	if r != nil {
-		p := []byte{0}
-		for {
-			if n, err := r.Read(p); err != nil {
-				break
-			} else if n == 0 {
-				panic("assert(n && !err)")
-			} else if p[0] == '\n' {
-				break
-			} else if p[0] == '\r' {
-				continue
+	if r == nil {
+		return nil
+	}
+	buf, p := []byte{}, []byte{0}
+	for {
+		n, err := r.Read(p)
+		if n != 0 {
+			if p[0] == '\n' {
+				return buf
+			} else if p[0] != '\r' {
+				buf = append(buf, p[0])
+			}
+		}
+		if err != nil {
+			if len(buf) == 0 { // end of input
+				return nil
 			}
-			buf = append(buf, p[0])
+			return buf
 		}
 	}
-	return buf
 }
 
 // get line, remove leading and trailing whitespace,
@@ -279,26 +284,33 @@ func readfile(path string) bool {
 	var err error
 	if line_fd != nil {
 		_ = line_fd.Close()
+		line_fd, line_rd = nil, nil
 	}
 	line_fd, err = os.Open(path)
 	if err != nil {
-		log.Printf("readfile: can't open %q: %v", path, err)
-		panic("!")
+		log.Printf("readfile: can't open %q: %v\n", path, err)
 		return false
 	}
+	line_rd = bufio.NewReader(line_fd)
 
 	return true
 }
 
-// readlin reads the next line from the global file pointer `line_fd`.
+// readlin reads the next line from the global reader `line_rd`.
 // ignores carriage-return and strips newline off end of line.
 // returns nil on end of input.
 func readlin() []byte {
-	return getlin(line_fd)
+	if line_rd == nil {
+		return nil
+	}
+	return getlin(line_rd)
 }
 
 func readlin_ew() []byte {
-	return getlin_ew(line_fd)
+	if line_rd == nil {
+		return nil
+	}
+	return getlin_ew(line_rd)
 }
 
 func str_save(src []byte) []byte {
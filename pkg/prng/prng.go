@@ -21,62 +21,74 @@ package prng
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 )
 
+// Source is a source of pseudo-random numbers.
+// The engine draws all of its randomness from a single Source
+// so that a turn can be replayed exactly.
+type Source interface {
+	Uint32() uint32
+}
+
+// State is the saved state of a Source.
+type State struct {
+	A, B, C, D uint32
+}
+
+// Stateful is a Source whose state can be saved between turns.
+type Stateful interface {
+	Source
+	State() State
+}
+
 // Range returns a pseudo-random value in the range of low...high
-func Range(low, high int) int {
-	if state == nil {
-		state = &privateState
-		state.seed(defaultSeed[0], defaultSeed[1], defaultSeed[2], defaultSeed[3])
-	}
+func Range(src Source, low, high int) int {
 	if high < low {
 		high, low = low, high
 	}
-	n := (int)(state.next())
+	n := (int)(src.Uint32())
 	if n < 0 {
 		n = -n
 	}
 	return low + (n % (high - low + 1))
 }
 
-func LoadSeed(name string) {
-	var data struct {
-		State struct {
-			A, B, C, D uint32
-		} `json:"state"`
+// Shuffle pseudo-randomizes the order of n elements.
+// swap swaps the elements with indexes i and j.
+func Shuffle(src Source, n int, swap func(i, j int)) {
+	for i := n - 1; i > 0; i-- {
+		swap(i, Range(src, 0, i))
 	}
+}
 
-	state = &privateState
-	if buf, err := os.ReadFile(name); err == nil {
-		if err = json.Unmarshal(buf, &data); err == nil {
-			state = state.seed(data.State.A, data.State.B, data.State.C, data.State.C)
-		} else {
-			state = state.seed(defaultSeed[0], defaultSeed[1], defaultSeed[2], defaultSeed[3])
-		}
-	} else {
-		state = state.seed(defaultSeed[0], defaultSeed[1], defaultSeed[2], defaultSeed[3])
+// Load returns a source restored from the state saved in the named file.
+// If the file doesn't exist, it returns a source using the default seed.
+func Load(name string) (*SFC32, error) {
+	var data struct {
+		State State `json:"state"`
 	}
+	buf, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return Default(), nil
+	} else if err != nil {
+		return nil, err
+	} else if err = json.Unmarshal(buf, &data); err != nil {
+		return nil, err
+	}
+	return FromState(data.State), nil
 }
 
-func SaveSeed(name string) {
-	if state == nil {
-		state = &privateState
-		state = state.seed(defaultSeed[0], defaultSeed[1], defaultSeed[2], defaultSeed[3])
-	}
+// Save writes the state of the source to the named file.
+func Save(name string, src Stateful) error {
 	var data struct {
-		State struct {
-			A, B, C, D uint32
-		} `json:"state"`
+		State State `json:"state"`
 	}
-	data.State.A = state.a
-	data.State.B = state.b
-	data.State.C = state.c
-	data.State.D = state.d
-
-	if buf, err := json.MarshalIndent(data, "", "  "); err != nil {
-		panic(err)
-	} else if err = os.WriteFile(name, buf, 0666); err != nil {
-		panic(err)
+	data.State = src.State()
+	buf, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
 	}
+	return os.WriteFile(name, buf, 0666)
 }
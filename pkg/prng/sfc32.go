@@ -19,6 +19,40 @@
 
 package prng
 
+var defaultSeed = [4]uint32{0, 12345, 0, 1}
+
+// SFC32 is a Source using the sfc32 algorithm.
+type SFC32 struct {
+	state sfc32_state
+}
+
+// New returns a source seeded with the given values.
+func New(a, b, c, d uint32) *SFC32 {
+	src := &SFC32{}
+	src.state.seed(a, b, c, d)
+	return src
+}
+
+// Default returns a source seeded with the default seed.
+func Default() *SFC32 {
+	return New(defaultSeed[0], defaultSeed[1], defaultSeed[2], defaultSeed[3])
+}
+
+// FromState returns a source that continues from a saved state.
+func FromState(st State) *SFC32 {
+	return &SFC32{state: sfc32_state{a: st.A, b: st.B, c: st.C, d: st.D}}
+}
+
+// Uint32 implements Source.
+func (src *SFC32) Uint32() uint32 {
+	return src.state.next()
+}
+
+// State implements Stateful.
+func (src *SFC32) State() State {
+	return State{A: src.state.a, B: src.state.b, C: src.state.c, D: src.state.d}
+}
+
 // sfc32_state holds the state for our PRNG.
 type sfc32_state struct {
 	a uint32
@@ -27,12 +61,6 @@ type sfc32_state struct {
 	d uint32
 }
 
-var (
-	state        *sfc32_state
-	privateState sfc32_state
-	defaultSeed  = [4]uint32{0, 12345, 0, 1}
-)
-
 // sfc32_init returns an initialized PRNG.
 // it is equivalent to calling sfc32_seed(0, a, b, c, d).
 func sfc32_init(a, b, c, d uint32) *sfc32_state {
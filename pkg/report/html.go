@@ -0,0 +1,131 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package report
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/battle"
+	"html"
+	"strings"
+)
+
+// renderHTML writes the report as a preformatted HTML page.
+// Unit reports get anchors and references to boxes link to them.
+// The player's battles, if any, follow the report.
+func renderHTML(w *bufio.Writer, lines []Line, opts Options) error {
+	title := opts.Title
+	if title == "" {
+		title = "Olympia turn report"
+	}
+	if _, err := fmt.Fprintf(w, "<html>\n<head>\n<title>%s</title>\n</head>\n<body>\n<pre>\n", html.EscapeString(title)); err != nil {
+		return err
+	}
+	// a box name can be wrapped onto the next line, so links stay open across lines
+	links := &htmlLinks{}
+	for _, line := range lines {
+		if isTagOnly(line.Text) {
+			// the only tag-only lines we care about are the anchors for the unit reports
+			for _, t := range tokenize(line.Text) {
+				if t.tag && strings.HasPrefix(t.text, "<tag type=unit_report ") {
+					if id, ok := tagAttr(t.text, "id"); ok {
+						if _, err := fmt.Fprintf(w, "<a name=\"%s\"></a>", html.EscapeString(id)); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			continue
+		}
+		for _, s := range wrap(line, opts.Width, true) {
+			if _, err := fmt.Fprintf(w, "%s\n", links.line(s)); err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := fmt.Fprintf(w, "%s</pre>\n", links.close()); err != nil {
+		return err
+	}
+	if err := battle.HTML(w, opts.Battles); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "</body>\n</html>\n")
+	return err
+}
+
+// htmlLinks turns box tags into links.
+// Box tags without an id, and box tags inside a link (links can't be
+// nested), don't open a link, so their closing tags are dropped.
+type htmlLinks struct {
+	boxes []bool // the open box tags; true if the tag opened a link
+	open  bool   // a link is open
+}
+
+// line escapes the text and turns box tags into links.
+func (l *htmlLinks) line(s string) string {
+	var sb strings.Builder
+	for _, t := range tokenize(s) {
+		switch {
+		case !t.tag:
+			sb.WriteString(html.EscapeString(t.text))
+		case strings.HasPrefix(t.text, "<tag type=box "):
+			id, ok := tagAttr(t.text, "id")
+			ok = ok && !l.open
+			if ok {
+				sb.WriteString(fmt.Sprintf("<a href=\"#%s\">", html.EscapeString(id)))
+				l.open = true
+			}
+			l.boxes = append(l.boxes, ok)
+		case strings.HasPrefix(t.text, "</tag type=box"):
+			if len(l.boxes) == 0 {
+				// a closing tag without an opening tag
+				continue
+			}
+			linked := l.boxes[len(l.boxes)-1]
+			l.boxes = l.boxes[:len(l.boxes)-1]
+			if linked {
+				sb.WriteString("</a>")
+				l.open = false
+			}
+		}
+	}
+	return sb.String()
+}
+
+// close returns the end tag for a link that was never closed.
+func (l *htmlLinks) close() string {
+	l.boxes = nil
+	if l.open {
+		l.open = false
+		return "</a>"
+	}
+	return ""
+}
+
+// tagAttr returns the value of an attribute from a tag.
+func tagAttr(tag, name string) (string, bool) {
+	s := strings.TrimSuffix(strings.TrimPrefix(tag, "<"), ">")
+	for _, field := range strings.Fields(s) {
+		if k, v, ok := strings.Cut(field, "="); ok && k == name {
+			return strings.Trim(v, "\""), true
+		}
+	}
+	return "", false
+}
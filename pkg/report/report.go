@@ -0,0 +1,300 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package report renders the per-player logs written by the engine
+// into the TEXT, TAGS, RAW, and HTML turn reports.
+// It replaces the external "rep" program.
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/mdhender/golympia/pkg/battle"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Format is a report format.
+// The values match the format bits stored in the player entity.
+type Format int
+
+const (
+	HTML Format = 1 << 0
+	TEXT Format = 1 << 1
+	TAGS Format = 1 << 2
+	RAW  Format = 1 << 3
+)
+
+func (f Format) String() string {
+	switch f {
+	case HTML:
+		return "HTML"
+	case TEXT:
+		return "TEXT"
+	case TAGS:
+		return "TAGS"
+	case RAW:
+		return "RAW"
+	}
+	return "UNKNOWN"
+}
+
+// IncludeSection is the section that holds the "#include" lines that
+// stitch the other sections together (OUT_INCLUDE in the engine).
+const IncludeSection = 1
+
+// DefaultWidth is the column that text reports are wrapped at.
+const DefaultWidth = 78
+
+// Options control rendering.
+type Options struct {
+	Format Format
+	NoTab  bool // don't convert leading spaces to tabs
+	Width  int  // defaults to DefaultWidth
+	Title  string
+
+	// Battles are the battles the player fought in this turn.
+	// The HTML report shows them after the turn report.
+	Battles []*battle.Battle
+}
+
+// Line is one line of output from the engine.
+// The log file format is "who:unit:indent[/indent2]:day:text".
+type Line struct {
+	Who     int    // section the line belongs to
+	Unit    string // unit code, only set for garrison logs
+	Indent  int    // indentation of the first line
+	Indent2 int    // additional indentation for wrapped lines
+	Day     int    // day of the month, or -1 if not shown
+	Text    string
+}
+
+// Plain returns the text of the line as a reader sees it: without tags,
+// and with each non-breaking "~" shown as a space.
+func (l Line) Plain() string {
+	return strings.ReplaceAll(stripTags(l.Text), "~", " ")
+}
+
+// Log is the parsed contents of a player's log file.
+type Log struct {
+	Sections map[int][]Line
+	Order    []int // sections in the order they first appear
+}
+
+// Parse reads a player's log file.
+func Parse(r io.Reader) (*Log, error) {
+	l := &Log{Sections: make(map[int][]Line)}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for n := 1; sc.Scan(); n++ {
+		line, err := parseLine(sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("report: line %d: %w", n, err)
+		}
+		if _, ok := l.Sections[line.Who]; !ok {
+			l.Order = append(l.Order, line.Who)
+		}
+		l.Sections[line.Who] = append(l.Sections[line.Who], line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("report: %w", err)
+	}
+	return l, nil
+}
+
+// parseLine splits a log line into its fields.
+// The text is everything after the fourth colon, so it may contain colons.
+func parseLine(s string) (Line, error) {
+	fields := strings.SplitN(s, ":", 5)
+	if len(fields) != 5 {
+		return Line{}, fmt.Errorf("expected 5 fields, got %d", len(fields))
+	}
+	line := Line{Unit: fields[1], Day: -1, Text: fields[4]}
+
+	var err error
+	if line.Who, err = strconv.Atoi(fields[0]); err != nil {
+		return Line{}, fmt.Errorf("who: %w", err)
+	}
+	indent, indent2, found := strings.Cut(fields[2], "/")
+	if line.Indent, err = strconv.Atoi(indent); err != nil {
+		return Line{}, fmt.Errorf("indent: %w", err)
+	}
+	if found {
+		if line.Indent2, err = strconv.Atoi(indent2); err != nil {
+			return Line{}, fmt.Errorf("indent: %w", err)
+		}
+	}
+	if fields[3] != "" {
+		if line.Day, err = strconv.Atoi(fields[3]); err != nil {
+			return Line{}, fmt.Errorf("day: %w", err)
+		}
+	}
+	return line, nil
+}
+
+// Expand returns the lines of the report in output order, with every
+// "#include n" line replaced by the lines of section n.
+// If the log has no include section, sections are output in the order they first appear.
+func (l *Log) Expand() []Line {
+	var lines []Line
+	seen := make(map[int]bool)
+	var expand func(who int)
+	expand = func(who int) {
+		if seen[who] { // guard against an include loop
+			return
+		}
+		seen[who] = true
+		for _, line := range l.Sections[who] {
+			if n, ok := include(line.Text); ok {
+				expand(n)
+				continue
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	if _, ok := l.Sections[IncludeSection]; ok {
+		expand(IncludeSection)
+	} else {
+		for _, who := range l.Order {
+			expand(who)
+		}
+	}
+	return lines
+}
+
+// include returns the section number from an "#include n" line.
+func include(text string) (int, bool) {
+	s := strings.TrimSpace(stripTags(text))
+	if !strings.HasPrefix(s, "#include ") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(s[len("#include "):]))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// RenderFile renders the log file into a report.
+func RenderFile(name string, opts Options) ([]byte, error) {
+	fp, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("report: %w", err)
+	}
+	defer fp.Close()
+	l, err := Parse(fp)
+	if err != nil {
+		return nil, fmt.Errorf("report: %s: %w", name, err)
+	}
+	b := &bytes.Buffer{}
+	if err := Render(b, l, opts); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Render writes the report in the requested format.
+func Render(w io.Writer, l *Log, opts Options) error {
+	if opts.Width <= 0 {
+		opts.Width = DefaultWidth
+	}
+	bw := bufio.NewWriter(w)
+	var err error
+	switch opts.Format {
+	case RAW:
+		err = renderRaw(bw, l.Expand())
+	case HTML:
+		err = renderHTML(bw, l.Expand(), opts)
+	case TAGS:
+		err = renderText(bw, l.Expand(), opts, true)
+	case TEXT, 0:
+		err = renderText(bw, l.Expand(), opts, false)
+	default:
+		return fmt.Errorf("report: unknown format %d", opts.Format)
+	}
+	if err != nil {
+		return fmt.Errorf("report: %s: %w", opts.Format, err)
+	}
+	return bw.Flush()
+}
+
+// renderRaw writes the expanded lines with tags and without wrapping.
+func renderRaw(w *bufio.Writer, lines []Line) error {
+	for _, line := range lines {
+		prefix := dayPrefix(line) + unitPrefix(line) + strings.Repeat(" ", line.Indent)
+		if _, err := fmt.Fprintf(w, "%s%s\n", prefix, line.Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderText writes the wrapped text report.
+// When keepTags is set, the tags are passed through for client programs.
+func renderText(w *bufio.Writer, lines []Line, opts Options, keepTags bool) error {
+	for _, line := range lines {
+		if isTagOnly(line.Text) {
+			if keepTags {
+				if _, err := fmt.Fprintf(w, "%s\n", strings.TrimSpace(line.Text)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		for _, s := range wrap(line, opts.Width, keepTags) {
+			if !opts.NoTab {
+				s = entab(s)
+			}
+			if _, err := fmt.Fprintf(w, "%s\n", s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func dayPrefix(line Line) string {
+	if line.Day < 0 {
+		return ""
+	}
+	return fmt.Sprintf("%2d: ", line.Day)
+}
+
+func unitPrefix(line Line) string {
+	if line.Unit == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s: ", line.Unit)
+}
+
+// entab replaces leading runs of eight spaces with tabs.
+func entab(s string) string {
+	n := 0
+	for n < len(s) && s[n] == ' ' {
+		n++
+	}
+	if n < 8 {
+		return s
+	}
+	return strings.Repeat("\t", n/8) + s[n-n%8:]
+}
@@ -0,0 +1,101 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package report
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// TestRenderGolden renders testdata/report.log in each format and
+// compares the result to testdata/report.<name>.golden.
+// Run "go test -update" to rewrite the golden files after a deliberate change.
+func TestRenderGolden(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts Options
+	}{
+		{"text", Options{Format: TEXT}},
+		{"text-notab", Options{Format: TEXT, NoTab: true}},
+		{"tags", Options{Format: TAGS}},
+		{"raw", Options{Format: RAW}},
+		{"html", Options{Format: HTML, Title: "Smith & Sons <turn 2>"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RenderFile(filepath.Join("testdata", "report.log"), tc.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", "report."+tc.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s doesn't match the golden file:\n--- got\n%s\n--- want\n%s", tc.name, got, want)
+			}
+		})
+	}
+}
+
+func TestHTMLLinks(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"link", []string{`a <tag type=box id=5>b</tag type=box id=5> c`}, `a <a href="#5">b</a> c`},
+		{"stray close", []string{`a </tag type=box id=5> b`}, `a  b`},
+		{"no id", []string{`<tag type=box>a</tag type=box>`}, `a`},
+		{"nested", []string{`<tag type=box id=1>a <tag type=box id=2>b</tag type=box id=2> c</tag type=box id=1>`}, `<a href="#1">a b c</a>`},
+		{"wrapped", []string{`<tag type=box id=1>a`, `b</tag type=box id=1>`}, `<a href="#1">a` + "\n" + `b</a>`},
+		{"unclosed", []string{`<tag type=box id=1>a`}, `<a href="#1">a` + "\n" + `</a>`},
+		{"escaped", []string{`<tag type=box id=1>a & b</tag type=box id=1> < c`}, `<a href="#1">a &amp; b</a> &lt; c`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			l := &htmlLinks{}
+			var got []string
+			for _, s := range tc.lines {
+				got = append(got, l.line(s))
+			}
+			out := got[0]
+			for _, s := range got[1:] {
+				out += "\n" + s
+			}
+			if end := l.close(); end != "" {
+				out += "\n" + end
+			}
+			if out != tc.want {
+				t.Errorf("got %q, want %q", out, tc.want)
+			}
+		})
+	}
+}
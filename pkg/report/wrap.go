@@ -0,0 +1,172 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package report
+
+import (
+	"strconv"
+	"strings"
+)
+
+// token is a word, a run of spaces, or a tag.
+// Tags have no width on the page.
+type token struct {
+	text  string
+	space bool
+	tag   bool
+}
+
+// tokenize splits text into words, spaces, and tags.
+func tokenize(text string) []token {
+	var tokens []token
+	for len(text) != 0 {
+		switch {
+		case strings.HasPrefix(text, "<tag ") || strings.HasPrefix(text, "</tag"):
+			end := strings.IndexByte(text, '>')
+			if end == -1 {
+				end = len(text) - 1
+			}
+			tokens = append(tokens, token{text: text[:end+1], tag: true})
+			text = text[end+1:]
+		case text[0] == ' ':
+			n := 0
+			for n < len(text) && text[n] == ' ' {
+				n++
+			}
+			tokens = append(tokens, token{text: text[:n], space: true})
+			text = text[n:]
+		default:
+			n := 0
+			for n < len(text) && text[n] != ' ' && !strings.HasPrefix(text[n:], "<tag ") && !strings.HasPrefix(text[n:], "</tag") {
+				n++
+			}
+			tokens = append(tokens, token{text: text[:n]})
+			text = text[n:]
+		}
+	}
+	return tokens
+}
+
+// isTagOnly returns true if the line has tags and nothing else.
+func isTagOnly(text string) bool {
+	hasTag := false
+	for _, t := range tokenize(text) {
+		if t.tag {
+			hasTag = true
+		} else if !t.space {
+			return false
+		}
+	}
+	return hasTag
+}
+
+// stripTags removes all the tags from the text.
+func stripTags(text string) string {
+	var sb strings.Builder
+	for _, t := range tokenize(text) {
+		if !t.tag {
+			sb.WriteString(t.text)
+		}
+	}
+	return sb.String()
+}
+
+// tabColumn returns the column from a "<tag type=tab col=n>" tag.
+func tabColumn(tag string) (int, bool) {
+	if !strings.HasPrefix(tag, "<tag type=tab ") {
+		return 0, false
+	}
+	i := strings.Index(tag, "col=")
+	if i == -1 {
+		return 0, false
+	}
+	s := strings.TrimRight(tag[i+len("col="):], ">")
+	if sp := strings.IndexByte(s, ' '); sp != -1 {
+		s = s[:sp]
+	}
+	col, err := strconv.Atoi(s)
+	return col, err == nil
+}
+
+// wrap breaks a line into lines no wider than width.
+// Wrapped lines get the extra indentation from Indent2.
+// A "~" in the text is a space that doesn't break.
+func wrap(line Line, width int, keepTags bool) []string {
+	prefix := dayPrefix(line) + unitPrefix(line)
+	first := prefix + strings.Repeat(" ", line.Indent)
+	rest := strings.Repeat(" ", len(prefix)+line.Indent+line.Indent2)
+
+	var lines []string
+	var sb strings.Builder
+	sb.WriteString(first)
+	col := len(first)
+	empty := true // nothing but indentation on the current line
+
+	flush := func() {
+		lines = append(lines, strings.TrimRight(sb.String(), " "))
+		sb.Reset()
+		sb.WriteString(rest)
+		col, empty = len(rest), true
+	}
+
+	tokens := tokenize(line.Text)
+	for i, t := range tokens {
+		switch {
+		case t.tag:
+			if n, ok := tabColumn(t.text); ok {
+				if col < n {
+					sb.WriteString(strings.Repeat(" ", n-col))
+					col = n
+				} else {
+					sb.WriteByte(' ')
+					col++
+				}
+			}
+			if keepTags {
+				sb.WriteString(t.text)
+			}
+		case t.space:
+			if empty && col != len(first) {
+				// don't start a wrapped line with spaces
+				continue
+			}
+			// look ahead to see if the next word fits
+			next := 0
+			for _, u := range tokens[i+1:] {
+				if u.space {
+					break
+				} else if !u.tag {
+					next += len(u.text)
+				}
+			}
+			if col+len(t.text)+next > width && !empty {
+				flush()
+				continue
+			}
+			sb.WriteString(t.text)
+			col += len(t.text)
+		default:
+			sb.WriteString(strings.ReplaceAll(t.text, "~", " "))
+			col += len(t.text)
+			empty = false
+		}
+	}
+	lines = append(lines, strings.TrimRight(sb.String(), " "))
+	return lines
+}
@@ -0,0 +1,162 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package times
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// DashLine separates the articles in the text edition.
+const DashLine = "===-===-===-===-===-===-===-===-===-===-===-===-===-===-===-===-===-===-="
+
+// Text writes the plain text edition, which is what gets mailed.
+func (is *Issue) Text(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "\n   +----------------------------------------------------------------------+\n")
+	fmt.Fprintf(bw, "   | The Gods Speak %53s |\n", strings.ReplaceAll(is.Date, "-", " "))
+	fmt.Fprintf(bw, "   | %-68s |\n", fmt.Sprintf("Game %d, %s", is.Game, is.Season))
+	fmt.Fprintf(bw, "   |                                                                      |\n")
+	fmt.Fprintf(bw, "   | %-68s |\n", fmt.Sprintf("Turn %d  %d Players", is.Turn, is.Players))
+	if is.Orders != "" {
+		fmt.Fprintf(bw, "   | %68s |\n", "Send orders to: "+is.Orders)
+	}
+	fmt.Fprintf(bw, "   +----------------------------------------------------------------------+\n\n")
+	fmt.Fprintf(bw, "                             Olympia PBEM\n\n")
+
+	for _, s := range sections {
+		articles := is.section(s.kind)
+		if len(articles) == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "                                *  *  *\n\n")
+		fmt.Fprintf(bw, "%s\n%*s\n%s\n\n", DashLine, 36+len(s.title)/2, s.title, DashLine)
+		for _, a := range articles {
+			if a.Title != "" && a.Title != s.title {
+				fmt.Fprintf(bw, "%s\n%s\n\n", a.Title, strings.Repeat("-", len(a.Title)))
+			}
+			for _, line := range strings.Split(strings.TrimRight(a.Body, "\n"), "\n") {
+				if strings.HasPrefix(line, "===-") {
+					// don't let the body look like the end of the article
+					line = "> " + line
+				}
+				fmt.Fprintf(bw, "%s\n", line)
+			}
+			if a.Author != "" {
+				fmt.Fprintf(bw, "\n%55s\n", "-- "+a.Author)
+			}
+			fmt.Fprintf(bw, "\n%s\n\n", DashLine)
+		}
+	}
+	fmt.Fprintf(bw, "                                *  *  *\n")
+
+	return bw.Flush()
+}
+
+// Markdown writes the Markdown edition.
+// Game information is preformatted; other bodies keep their line breaks.
+func (is *Issue) Markdown(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "# The Gods Speak\n\n")
+	fmt.Fprintf(bw, "*Game %d, %s*  \n", is.Game, is.Season)
+	fmt.Fprintf(bw, "Turn %d, %d players, printed %s", is.Turn, is.Players, is.Date)
+	if is.Orders != "" {
+		fmt.Fprintf(bw, "  \nSend orders to: %s", is.Orders)
+	}
+	fmt.Fprintf(bw, "\n")
+
+	for _, s := range sections {
+		articles := is.section(s.kind)
+		if len(articles) == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "\n## %s\n", s.title)
+		for _, a := range articles {
+			if a.Title != "" && a.Title != s.title {
+				fmt.Fprintf(bw, "\n### %s\n", a.Title)
+			}
+			body := strings.TrimRight(a.Body, "\n")
+			if a.Kind == GameInfo {
+				fmt.Fprintf(bw, "\n```\n%s\n```\n", body)
+			} else {
+				for _, p := range strings.Split(body, "\n\n") {
+					fmt.Fprintf(bw, "\n%s\n", strings.Join(strings.Split(p, "\n"), "  \n"))
+				}
+			}
+			if a.Author != "" {
+				fmt.Fprintf(bw, "\n-- *%s*\n", a.Author)
+			}
+			if a.Kind == Press || a.Kind == Rumor {
+				fmt.Fprintf(bw, "\n---\n")
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// HTML writes the HTML edition as a complete page.
+func (is *Issue) HTML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	esc := html.EscapeString
+
+	fmt.Fprintf(bw, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n", esc(is.Title()))
+	fmt.Fprintf(bw, "<h1>The Gods Speak</h1>\n")
+	fmt.Fprintf(bw, "<p><em>Game %d, %s</em><br>\n", is.Game, esc(is.Season))
+	fmt.Fprintf(bw, "Turn %d, %d players, printed %s", is.Turn, is.Players, esc(is.Date))
+	if is.Orders != "" {
+		fmt.Fprintf(bw, "<br>\nSend orders to: %s", esc(is.Orders))
+	}
+	fmt.Fprintf(bw, "</p>\n")
+
+	for _, s := range sections {
+		articles := is.section(s.kind)
+		if len(articles) == 0 {
+			continue
+		}
+		fmt.Fprintf(bw, "<h2>%s</h2>\n", esc(s.title))
+		for _, a := range articles {
+			fmt.Fprintf(bw, "<article class=%q>\n", string(a.Kind))
+			if a.Title != "" && a.Title != s.title {
+				fmt.Fprintf(bw, "<h3>%s</h3>\n", esc(a.Title))
+			}
+			body := strings.TrimRight(a.Body, "\n")
+			if a.Kind == GameInfo {
+				fmt.Fprintf(bw, "<pre>%s</pre>\n", esc(body))
+			} else {
+				for _, p := range strings.Split(body, "\n\n") {
+					fmt.Fprintf(bw, "<p>%s</p>\n", strings.ReplaceAll(esc(p), "\n", "<br>\n"))
+				}
+			}
+			if a.Author != "" {
+				fmt.Fprintf(bw, "<p class=\"author\">-- %s</p>\n", esc(a.Author))
+			}
+			fmt.Fprintf(bw, "</article>\n")
+		}
+	}
+	fmt.Fprintf(bw, "</body>\n</html>\n")
+
+	return bw.Flush()
+}
@@ -0,0 +1,196 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package times builds "The Gods Speak," the newspaper that goes out with
+// each turn. An issue is a list of articles; the engine writes one issue per
+// turn into an archive, and this package renders the issues as text,
+// Markdown, or HTML and searches the back issues.
+package times
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kind is the section of the paper an article runs in.
+type Kind string
+
+const (
+	Editorial Kind = "editorial" // written by the GM
+	GameInfo  Kind = "game-info" // nation summaries and win conditions
+	Press     Kind = "press"     // player-contributed press
+	Rumor     Kind = "rumor"     // anonymous rumors
+)
+
+// sections is the order the kinds appear in an issue.
+var sections = []struct {
+	kind  Kind
+	title string
+}{
+	{Editorial, "From the Editors"},
+	{GameInfo, "Game Information"},
+	{Press, "Player-contributed press"},
+	{Rumor, "Rumors"},
+}
+
+// Article is one item in the paper.
+// Rumors are anonymous, so they have no author.
+type Article struct {
+	Turn   int    `json:"turn"`
+	Kind   Kind   `json:"kind"`
+	Author string `json:"author,omitempty"`
+	Title  string `json:"title,omitempty"`
+	Body   string `json:"body"`
+}
+
+// Issue is the paper for one turn.
+type Issue struct {
+	Game     int        `json:"game"`
+	Turn     int        `json:"turn"`
+	Date     string     `json:"date"`             // when the issue was printed, as YYYY-MM-DD
+	Season   string     `json:"season,omitempty"` // the date in the game
+	Players  int        `json:"players"`
+	Orders   string     `json:"orders,omitempty"` // where to send orders
+	Articles []*Article `json:"articles,omitempty"`
+}
+
+// Title is the name of the issue.
+func (is *Issue) Title() string {
+	return fmt.Sprintf("The Gods Speak (Game %d, Issue %d)", is.Game, is.Turn)
+}
+
+// section returns the articles of the given kind, in the order they were added.
+func (is *Issue) section(kind Kind) []*Article {
+	var list []*Article
+	for _, a := range is.Articles {
+		if a.Kind == kind {
+			list = append(list, a)
+		}
+	}
+	return list
+}
+
+// Archive is a directory of issues, one JSON file per turn.
+type Archive struct {
+	Dir string
+}
+
+func (ar Archive) name(turn int) string {
+	return filepath.Join(ar.Dir, fmt.Sprintf("%d.json", turn))
+}
+
+// Save writes the issue, replacing any earlier issue for the same turn.
+func (ar Archive) Save(is *Issue) error {
+	if err := os.MkdirAll(ar.Dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(is, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(ar.name(is.Turn), data, 0666)
+}
+
+// Load reads the issue for the turn.
+func (ar Archive) Load(turn int) (*Issue, error) {
+	data, err := os.ReadFile(ar.name(turn))
+	if err != nil {
+		return nil, err
+	}
+	is := &Issue{}
+	if err := json.Unmarshal(data, is); err != nil {
+		return nil, fmt.Errorf("%s: %w", ar.name(turn), err)
+	}
+	return is, nil
+}
+
+// Turns returns the turns in the archive, oldest first.
+// A missing archive is empty.
+func (ar Archive) Turns() ([]int, error) {
+	files, err := os.ReadDir(ar.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var turns []int
+	for _, f := range files {
+		if n, err := strconv.Atoi(strings.TrimSuffix(f.Name(), ".json")); err == nil && !f.IsDir() && strings.HasSuffix(f.Name(), ".json") {
+			turns = append(turns, n)
+		}
+	}
+	sort.Ints(turns)
+	return turns, nil
+}
+
+// Latest returns the most recent issue, or nil if the archive is empty.
+func (ar Archive) Latest() (*Issue, error) {
+	turns, err := ar.Turns()
+	if err != nil || len(turns) == 0 {
+		return nil, err
+	}
+	return ar.Load(turns[len(turns)-1])
+}
+
+// Match is an article found by Search.
+type Match struct {
+	Turn    int      `json:"turn"`
+	Article *Article `json:"article"`
+}
+
+// Search returns the articles that contain every word of the query,
+// ignoring case, newest issue first.
+func (ar Archive) Search(query string) ([]Match, error) {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil, nil
+	}
+	turns, err := ar.Turns()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Match
+	for i := len(turns) - 1; i >= 0; i-- {
+		is, err := ar.Load(turns[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range is.Articles {
+			text := strings.ToLower(a.Title + "\n" + a.Author + "\n" + a.Body)
+			found := true
+			for _, w := range words {
+				if !strings.Contains(text, w) {
+					found = false
+					break
+				}
+			}
+			if found {
+				matches = append(matches, Match{Turn: is.Turn, Article: a})
+			}
+		}
+	}
+	return matches, nil
+}
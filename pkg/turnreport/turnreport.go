@@ -0,0 +1,198 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package turnreport is the machine-readable turn report: what a faction
+// knows at the end of a turn. The engine writes it next to the text report
+// so that clients don't have to scrape the report text. schema.json is the
+// JSON Schema for the report; clients should check SchemaVersion.
+package turnreport
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// SchemaVersion is bumped whenever a change to the report could break a client.
+// Adding a field is not such a change.
+const SchemaVersion = 1
+
+// Schema is the JSON Schema for a Report.
+//
+//go:embed schema.json
+var Schema []byte
+
+// Report is one faction's report for a turn.
+// Entities are identified by their codes, e.g. "ab12".
+type Report struct {
+	Schema    int         `json:"schema"` // SchemaVersion
+	Game      int         `json:"game"`
+	Turn      int         `json:"turn"`
+	Faction   *Faction    `json:"faction"`
+	Units     []*Unit     `json:"units"`
+	Locations []*Location `json:"locations"`
+	Messages  []*Message  `json:"messages"`
+}
+
+// Faction is the player's own entity.
+type Faction struct {
+	Id          string  `json:"id"`
+	Name        string  `json:"name"`
+	NoblePoints int     `json:"noble-points"`
+	Items       []*Item `json:"items"` // unclaimed items, e.g. gold in the CLAIM
+}
+
+// Unit is a character in the faction.
+type Unit struct {
+	Id           string     `json:"id"`
+	Name         string     `json:"name"`
+	Location     string     `json:"location"`           // the innermost place the unit is in
+	Province     string     `json:"province,omitempty"` // the province that place is in
+	StackedUnder string     `json:"stacked-under,omitempty"`
+	Behind       int        `json:"behind,omitempty"`
+	Health       int        `json:"health"` // percent, or -1 if the unit has no health
+	Sick         bool       `json:"sick,omitempty"`
+	Prisoner     bool       `json:"prisoner,omitempty"`
+	Loyalty      *Loyalty   `json:"loyalty"`
+	Items        []*Item    `json:"items"`
+	Skills       []*Skill   `json:"skills"`
+	Executing    *Executing `json:"executing,omitempty"`
+	Orders       []string   `json:"orders"` // queued orders, in the order they will run
+}
+
+// Loyalty is a unit's loyalty, e.g. contract 150.
+type Loyalty struct {
+	Kind string `json:"kind"` // unsworn, contract, oath, fear, npc, or summon
+	Rate int    `json:"rate"`
+}
+
+// Item is a stack of items in an inventory.
+type Item struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Qty    int    `json:"qty"`
+	Weight int    `json:"weight"` // of the whole stack
+}
+
+// Skill is a skill a unit knows or is learning.
+type Skill struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Known       bool   `json:"known"`
+	Level       int    `json:"level,omitempty"`        // experience with a known skill
+	DaysStudied int    `json:"days-studied,omitempty"` // for a skill being learned
+	DaysNeeded  int    `json:"days-needed,omitempty"`  // to learn the skill
+}
+
+// Executing is the order a unit is carrying out.
+type Executing struct {
+	Order    string `json:"order"`
+	DaysLeft int    `json:"days-left"` // -1 if the order runs until it is done
+}
+
+// Location is a place the faction saw this turn.
+type Location struct {
+	Id          string      `json:"id"`
+	Name        string      `json:"name"`
+	Kind        string      `json:"kind"`             // forest, city, ...
+	Inside      string      `json:"inside,omitempty"` // the enclosing place, for sublocations and ships
+	Province    string      `json:"province,omitempty"`
+	Region      string      `json:"region,omitempty"` // region name
+	Hidden      bool        `json:"hidden,omitempty"`
+	Exits       []*Exit     `json:"exits"`
+	Market      *Market     `json:"market,omitempty"`
+	Occupants   []*Occupant `json:"occupants"`
+	Concealed   bool        `json:"concealed,omitempty"` // fog or mist hid the occupants
+	LastVisited int         `json:"last-visited,omitempty"`
+}
+
+// Exit is a route leaving a location.
+type Exit struct {
+	Direction  string `json:"direction,omitempty"` // north, in, out, ... or empty for a road
+	Road       string `json:"road,omitempty"`      // the road, if the route is one
+	RoadName   string `json:"road-name,omitempty"`
+	To         string `json:"to"`
+	ToName     string `json:"to-name"`
+	ToKind     string `json:"to-kind"`
+	Days       int    `json:"days"`
+	Impassable bool   `json:"impassable,omitempty"`
+	Hidden     bool   `json:"hidden,omitempty"` // the destination is hidden
+	Water      bool   `json:"water,omitempty"`  // a sea route
+}
+
+// Market is what a city or trading guild buys and sells.
+type Market struct {
+	Tax    int      `json:"tax,omitempty"` // percent
+	Trades []*Trade `json:"trades"`
+}
+
+// Trade is an offer to buy or sell.
+type Trade struct {
+	Kind     string `json:"kind"`          // buy or sell
+	Who      string `json:"who,omitempty"` // trader, unless cloaked
+	Item     string `json:"item"`
+	ItemName string `json:"item-name"`
+	Qty      int    `json:"qty"`
+	Price    int    `json:"price"`
+	Weight   int    `json:"weight"` // of one item
+}
+
+// Occupant is a character seen at a location.
+type Occupant struct {
+	Id           string `json:"id"`
+	Name         string `json:"name"`
+	StackedUnder string `json:"stacked-under,omitempty"`
+	Own          bool   `json:"own,omitempty"` // belongs to the faction
+}
+
+// Message is a line of a unit's turn log.
+// Messages are grouped by unit, the faction first, and each unit's
+// messages are in the order they happened.
+type Message struct {
+	Unit string `json:"unit"` // the faction itself for faction-wide messages
+	Day  int    `json:"day"`
+	Text string `json:"text"`
+}
+
+// Read loads a report from a file.
+// It returns nil if the file doesn't exist.
+func Read(name string) (*Report, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return &r, nil
+}
+
+// Write saves a report to a file.
+func Write(name string, r *Report) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0666)
+}
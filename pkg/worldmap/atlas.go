@@ -0,0 +1,58 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package worldmap
+
+// Atlas is what one faction knows about the world at the end of a turn.
+// It is written as JSON alongside the turn report so that clients can
+// merge the atlases from each turn into a cumulative map.
+type Atlas struct {
+	Faction      string      `json:"faction"`
+	Name         string      `json:"name,omitempty"`
+	Game         int         `json:"game,omitempty"`
+	Turn         int         `json:"turn"`
+	Provinces    []*Location `json:"provinces,omitempty"`
+	Sublocations []*Location `json:"sublocations,omitempty"`
+	Routes       []*Route    `json:"routes,omitempty"`
+}
+
+// Location is a province or sublocation known to the faction.
+// Row and Col are only set for provinces on the surface of the world.
+type Location struct {
+	Id          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	Kind        string `json:"kind"`             // terrain or sublocation kind
+	Region      string `json:"region,omitempty"` // provinces only
+	Surface     bool   `json:"surface,omitempty"`
+	Row         int    `json:"row,omitempty"`
+	Col         int    `json:"col,omitempty"`
+	Inside      string `json:"inside,omitempty"` // sublocations only
+	Hidden      bool   `json:"hidden,omitempty"`
+	LastVisited int    `json:"last-visited,omitempty"` // zero if never visited
+}
+
+// Route is a road or gate between two known locations.
+type Route struct {
+	Id     string `json:"id"`
+	Kind   string `json:"kind"` // "road" or "gate"
+	Name   string `json:"name,omitempty"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Hidden bool   `json:"hidden,omitempty"`
+}
@@ -0,0 +1,112 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package worldmap
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+)
+
+// PNG writes the map as a PNG image.
+func (m *Map) PNG(w io.Writer, opts Options) error {
+	return png.Encode(w, m.Image(opts))
+}
+
+// Image draws the map.
+func (m *Map) Image(opts Options) *image.RGBA {
+	cell := opts.cell()
+	img := image.NewRGBA(image.Rect(0, 0, m.Cols*cell, m.Rows*cell))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: color_unknown}, image.Point{}, draw.Src)
+
+	for _, p := range m.Provinces {
+		r := image.Rect(p.Col*cell, p.Row*cell, (p.Col+1)*cell, (p.Row+1)*cell)
+		draw.Draw(img, r, &image.Uniform{C: fill_color(p)}, image.Point{}, draw.Src)
+	}
+
+	for _, e := range m.region_borders() {
+		// borders are two pixels wide, centered on the edge
+		for d := -1; d <= 0; d++ {
+			if e.x1 == e.x2 {
+				draw_line(img, e.x1*cell+d, e.y1*cell, e.x2*cell+d, e.y2*cell, color_border)
+			} else {
+				draw_line(img, e.x1*cell, e.y1*cell+d, e.x2*cell, e.y2*cell+d, color_border)
+			}
+		}
+	}
+
+	for _, s := range m.segments(m.Roads) {
+		draw_line(img, s.c1*cell+cell/2, s.r1*cell+cell/2, s.c2*cell+cell/2, s.r2*cell+cell/2, color_road)
+	}
+	for _, s := range m.segments(m.Gates) {
+		draw_line(img, s.c1*cell+cell/2, s.r1*cell+cell/2, s.c2*cell+cell/2, s.r2*cell+cell/2, color_gate)
+	}
+
+	radius := max_int(cell/5, 2)
+	for _, p := range m.Provinces {
+		if len(p.Cities) != 0 {
+			cx, cy := p.Col*cell+cell/2, p.Row*cell+cell/2
+			draw.Draw(img, image.Rect(cx-radius-1, cy-radius-1, cx+radius+1, cy+radius+1), &image.Uniform{C: color_border}, image.Point{}, draw.Src)
+			draw.Draw(img, image.Rect(cx-radius, cy-radius, cx+radius, cy+radius), &image.Uniform{C: color_city}, image.Point{}, draw.Src)
+		}
+	}
+
+	return img
+}
+
+// draw_line draws a line using Bresenham's algorithm.
+// Points outside the image are skipped.
+func draw_line(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx, sx := abs_int(x1-x0), 1
+	if x0 > x1 {
+		sx = -1
+	}
+	dy, sy := -abs_int(y1-y0), 1
+	if y0 > y1 {
+		sy = -1
+	}
+	bounds := img.Bounds()
+	for err := dx + dy; ; {
+		if (image.Point{X: x0, Y: y0}).In(bounds) {
+			img.SetRGBA(x0, y0, c)
+		}
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs_int(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,97 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+package worldmap
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// SVG writes the map as an SVG image.
+// Each province and city has a title, so hovering over it shows its name.
+func (m *Map) SVG(w io.Writer, opts Options) error {
+	cell := opts.cell()
+	bw := bufio.NewWriter(w)
+	width, height := m.Cols*cell, m.Rows*cell
+
+	fmt.Fprintf(bw, "<svg xmlns=%q width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", "http://www.w3.org/2000/svg", width, height, width, height)
+	if m.Title != "" {
+		fmt.Fprintf(bw, "<title>%s</title>\n", html.EscapeString(m.Title))
+	}
+	fmt.Fprintf(bw, "<rect width=\"%d\" height=\"%d\" fill=%q/>\n", width, height, hex_color(color_unknown))
+
+	fmt.Fprintf(bw, "<g id=\"provinces\" stroke=%q stroke-opacity=\"%.2f\" stroke-width=\"0.5\">\n", hex_color(color_grid), float64(color_grid.A)/255)
+	for _, p := range m.Provinces {
+		fmt.Fprintf(bw, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=%q><title>%s</title></rect>\n",
+			p.Col*cell, p.Row*cell, cell, cell, hex_color(fill_color(p)), html.EscapeString(province_title(p)))
+	}
+	fmt.Fprintf(bw, "</g>\n")
+
+	fmt.Fprintf(bw, "<g id=\"regions\" stroke=%q stroke-width=\"2\">\n", hex_color(color_border))
+	for _, e := range m.region_borders() {
+		fmt.Fprintf(bw, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\"/>\n", e.x1*cell, e.y1*cell, e.x2*cell, e.y2*cell)
+	}
+	fmt.Fprintf(bw, "</g>\n")
+
+	svg_segments(bw, "roads", hex_color(color_road), "", m.segments(m.Roads), cell)
+	svg_segments(bw, "gates", hex_color(color_gate), " stroke-dasharray=\"4 3\"", m.segments(m.Gates), cell)
+
+	fmt.Fprintf(bw, "<g id=\"cities\" fill=%q stroke=%q>\n", hex_color(color_city), hex_color(color_border))
+	for _, p := range m.Provinces {
+		if len(p.Cities) != 0 {
+			fmt.Fprintf(bw, "<circle cx=\"%d\" cy=\"%d\" r=\"%d\"><title>%s</title></circle>\n",
+				p.Col*cell+cell/2, p.Row*cell+cell/2, max_int(cell/5, 2), html.EscapeString(strings.Join(p.Cities, ", ")))
+		}
+	}
+	fmt.Fprintf(bw, "</g>\n")
+
+	fmt.Fprintf(bw, "</svg>\n")
+	return bw.Flush()
+}
+
+func svg_segments(bw *bufio.Writer, id, stroke, extra string, segs []segment, cell int) {
+	fmt.Fprintf(bw, "<g id=%q stroke=%q stroke-width=\"%d\"%s>\n", id, stroke, max_int(cell/8, 1), extra)
+	for _, s := range segs {
+		fmt.Fprintf(bw, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\"/>\n",
+			s.c1*cell+cell/2, s.r1*cell+cell/2, s.c2*cell+cell/2, s.r2*cell+cell/2)
+	}
+	fmt.Fprintf(bw, "</g>\n")
+}
+
+func province_title(p *Province) string {
+	s := fmt.Sprintf("%s [%d], %s", p.Name, p.Id, p.Terrain)
+	if p.SeaLane {
+		s += ", sea lane"
+	}
+	if len(p.Cities) != 0 {
+		s += ", " + strings.Join(p.Cities, ", ")
+	}
+	return s
+}
+
+func max_int(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,182 @@
+/*
+ * golympia - a turn based game
+ * Copyright (c) 2022 Michael D Henderson
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Affero General Public License as published
+ * by the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Affero General Public License for more details.
+ *
+ * You should have received a copy of the GNU Affero General Public License
+ * along with this program.  If not, see <https://www.gnu.org/licenses/>.
+ *
+ */
+
+// Package worldmap draws the surface provinces of the world as SVG or PNG images.
+// The engine fills in a Map from the loaded boxes; this package only knows
+// about rows, columns, terrain, and the links between provinces.
+package worldmap
+
+import (
+	"fmt"
+	"image/color"
+	"sort"
+)
+
+// DefaultCell is the size of a province, in pixels.
+const DefaultCell = 16
+
+// Map is the surface of the world as seen by the GM or by one faction.
+type Map struct {
+	Title     string
+	Rows      int
+	Cols      int
+	Provinces []*Province
+	Roads     []Link
+	Gates     []Link
+}
+
+// Province is one cell of the map.
+type Province struct {
+	Id      int
+	Row     int
+	Col     int
+	Name    string
+	Terrain string // "ocean", "plain", "forest", and so on
+	Region  int
+	SeaLane bool
+	Cities  []string // names of the cities in the province
+}
+
+// Link joins two provinces, by id.
+type Link struct {
+	Id       int
+	From, To int
+}
+
+// Options control rendering.
+type Options struct {
+	Cell int // size of a province in pixels, defaults to DefaultCell
+}
+
+func (o Options) cell() int {
+	if o.Cell < 4 {
+		return DefaultCell
+	}
+	return o.Cell
+}
+
+// terrain_colors are the fill colors for each terrain.
+var terrain_colors = map[string]color.RGBA{
+	"ocean":       {0x2b, 0x5f, 0xa8, 0xff},
+	"plain":       {0xc8, 0xd8, 0x8a, 0xff},
+	"forest":      {0x3f, 0x7f, 0x3f, 0xff},
+	"mountain":    {0x8b, 0x7d, 0x6b, 0xff},
+	"desert":      {0xe3, 0xcf, 0x8f, 0xff},
+	"swamp":       {0x5f, 0x7f, 0x6f, 0xff},
+	"underground": {0x55, 0x55, 0x55, 0xff},
+	"mine shaft":  {0x55, 0x55, 0x55, 0xff},
+}
+
+var (
+	color_unknown  = color.RGBA{0x20, 0x20, 0x20, 0xff}
+	color_sea_lane = color.RGBA{0x4f, 0x86, 0xc6, 0xff}
+	color_border   = color.RGBA{0x00, 0x00, 0x00, 0xff}
+	color_road     = color.RGBA{0x8b, 0x45, 0x13, 0xff}
+	color_gate     = color.RGBA{0xc0, 0x20, 0xc0, 0xff}
+	color_city     = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	color_grid     = color.RGBA{0x00, 0x00, 0x00, 0x30}
+)
+
+func fill_color(p *Province) color.RGBA {
+	if p.Terrain == "ocean" && p.SeaLane {
+		return color_sea_lane
+	} else if c, ok := terrain_colors[p.Terrain]; ok {
+		return c
+	}
+	return color.RGBA{0xcc, 0xcc, 0xcc, 0xff}
+}
+
+func hex_color(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// index returns the provinces keyed by id and by row and column.
+func (m *Map) index() (map[int]*Province, map[[2]int]*Province) {
+	byId := make(map[int]*Province)
+	byRC := make(map[[2]int]*Province)
+	for _, p := range m.Provinces {
+		byId[p.Id] = p
+		byRC[[2]int{p.Row, p.Col}] = p
+	}
+	return byId, byRC
+}
+
+// edge is a side of a province, from (x1, y1) to (x2, y2) in cell units.
+type edge struct {
+	x1, y1, x2, y2 int
+}
+
+// region_borders returns the edges between neighboring provinces in different regions.
+// The map wraps east to west, but not north to south.
+func (m *Map) region_borders() []edge {
+	_, byRC := m.index()
+	var edges []edge
+	for _, p := range m.Provinces {
+		if e, ok := byRC[[2]int{p.Row, (p.Col + 1) % m.Cols}]; ok && e.Region != p.Region {
+			edges = append(edges, edge{p.Col + 1, p.Row, p.Col + 1, p.Row + 1})
+		}
+		if s, ok := byRC[[2]int{p.Row + 1, p.Col}]; ok && s.Region != p.Region {
+			edges = append(edges, edge{p.Col, p.Row + 1, p.Col + 1, p.Row + 1})
+		}
+	}
+	return edges
+}
+
+// segment is a line between the centers of two cells, in cell units.
+// Either end may be off the map when a link wraps around the east-west edge.
+type segment struct {
+	r1, c1, r2, c2 int
+}
+
+// segments returns the lines to draw for the links.
+// A link that is shorter going around the edge of the map is drawn
+// as two segments, one leaving each side.
+func (m *Map) segments(links []Link) []segment {
+	byId, _ := m.index()
+	var segs []segment
+	for _, l := range links {
+		a, b := byId[l.From], byId[l.To]
+		if a == nil || b == nil || a == b {
+			continue
+		}
+		dc := b.Col - a.Col
+		switch {
+		case dc > m.Cols/2:
+			segs = append(segs, segment{a.Row, a.Col, b.Row, b.Col - m.Cols}, segment{a.Row, a.Col + m.Cols, b.Row, b.Col})
+		case dc < -m.Cols/2:
+			segs = append(segs, segment{a.Row, a.Col, b.Row, b.Col + m.Cols}, segment{a.Row, a.Col - m.Cols, b.Row, b.Col})
+		default:
+			segs = append(segs, segment{a.Row, a.Col, b.Row, b.Col})
+		}
+	}
+	return segs
+}
+
+// Sort puts the provinces and links in a stable order so that the output
+// doesn't change from run to run.
+func (m *Map) Sort() {
+	sort.Slice(m.Provinces, func(i, j int) bool {
+		return m.Provinces[i].Id < m.Provinces[j].Id
+	})
+	for _, links := range [][]Link{m.Roads, m.Gates} {
+		sort.Slice(links, func(i, j int) bool {
+			return links[i].Id < links[j].Id
+		})
+	}
+}